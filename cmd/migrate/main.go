@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"spsc-loaneasy/internal/adapters/persistence/migrations"
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/config"
+)
+
+// Migrate command - applies versioned migrations (golang-migrate) without starting the server
+//
+//	go run ./cmd/migrate            # AutoMigrate + apply pending migrations
+//	go run ./cmd/migrate -status    # list migrations and whether they are applied
+//	go run ./cmd/migrate -down      # roll back the last applied migration
+//	go run ./cmd/migrate -force 3   # mark version 3 as applied and clean after fixing a failed migration by hand
+func main() {
+	down := flag.Bool("down", false, "roll back the most recently applied migration")
+	status := flag.Bool("status", false, "print migration status and exit")
+	force := flag.Int("force", -1, "set the migration version without running it (clears a dirty state)")
+	skipAuto := flag.Bool("skip-automigrate", false, "do not run AutoMigrate before versioned migrations")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	m, err := migrations.Open(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to open migrations: %v", err)
+	}
+	defer m.Close()
+
+	switch {
+	case *force >= 0:
+		if err := m.Force(*force); err != nil {
+			log.Fatalf("❌ Force failed: %v", err)
+		}
+		log.Printf("✅ Migration version set to %d", *force)
+
+	case *status:
+		statuses, err := m.Status()
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			if s.Applied {
+				log.Printf("   [x] %06d_%s", s.Version, s.Name)
+			} else {
+				log.Printf("   [ ] %06d_%s", s.Version, s.Name)
+			}
+		}
+
+	case *down:
+		version, err := m.Down()
+		if err != nil {
+			log.Fatalf("❌ Rollback failed: %v", err)
+		}
+		if version == 0 {
+			log.Println("✅ Nothing to roll back")
+		} else {
+			log.Printf("✅ Rolled back %d", version)
+		}
+
+	default:
+		if !*skipAuto {
+			db, err := config.ConnectDatabase(cfg)
+			if err != nil {
+				log.Fatalf("❌ Failed to connect to database: %v", err)
+			}
+			defer config.CloseDatabase()
+
+			if err := models.AutoMigrate(db); err != nil {
+				log.Fatalf("❌ Failed to auto migrate: %v", err)
+			}
+		}
+
+		before, err := m.Version()
+		if err != nil {
+			log.Fatalf("❌ Migration failed: %v", err)
+		}
+		after, err := m.Up()
+		if err != nil {
+			log.Fatalf("❌ Migration failed at version %d: %v", after, err)
+		}
+		if after == before {
+			log.Println("✅ Database is up to date")
+		} else {
+			log.Printf("✅ Migrated from version %d to %d", before, after)
+		}
+	}
+}
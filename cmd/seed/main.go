@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"spsc-loaneasy/internal/adapters/persistence/migrations"
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/config"
+)
+
+// Seed command - runs database seeding without starting the server
+// Idempotent: rows are only inserted if their code does not exist yet
+//
+//	go run ./cmd/seed            # master data only
+//	go run ./cmd/seed -admin     # master data + default admin user (dev)
+func main() {
+	withAdmin := flag.Bool("admin", false, "also seed the default admin user (development only)")
+	skipMigrate := flag.Bool("skip-migrate", false, "do not run AutoMigrate and versioned migrations before seeding")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	db, err := config.ConnectDatabase(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer config.CloseDatabase()
+
+	// A fresh database needs the tables (and the migration-owned users columns) before it can be seeded
+	if !*skipMigrate {
+		if err := models.AutoMigrate(db); err != nil {
+			log.Fatalf("❌ Failed to auto migrate: %v", err)
+		}
+
+		m, err := migrations.Open(cfg)
+		if err != nil {
+			log.Fatalf("❌ Failed to open migrations: %v", err)
+		}
+		_, err = m.Up()
+		m.Close()
+		if err != nil {
+			log.Fatalf("❌ Failed to run migrations: %v", err)
+		}
+	}
+
+	log.Println("🌱 Seeding master data...")
+	result, err := config.RunMasterSeed(db)
+	if err != nil {
+		log.Fatalf("❌ Seeding failed after creating %d rows: %v", len(result.Created), err)
+	}
+
+	if len(result.Created) == 0 {
+		log.Printf("✅ Nothing to seed, %d rows already present", result.Skipped)
+	} else {
+		for _, row := range result.Created {
+			log.Printf("   + %s", row)
+		}
+		log.Printf("✅ Created %d rows, %d already present", len(result.Created), result.Skipped)
+	}
+
+	if *withAdmin {
+		if err := config.NewSeeder(db).Run(); err != nil {
+			log.Fatalf("❌ Admin seeding failed: %v", err)
+		}
+	}
+}
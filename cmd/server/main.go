@@ -8,9 +8,12 @@ import (
 
 	"spsc-loaneasy/internal/adapters/http/middleware"
 	"spsc-loaneasy/internal/adapters/http/routes"
+	"spsc-loaneasy/internal/adapters/persistence/migrations"
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/config"
 	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/money"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -44,6 +47,12 @@ func main() {
 		log.Fatalf("❌ Failed to load configuration: %v", err)
 	}
 
+	// Structured logging (JSON in prod, text in dev - LOG_FORMAT / LOG_LEVEL)
+	logger.Init(cfg.Log.Format, cfg.Log.Level)
+
+	// Amount rounding mode (MONEY_ROUNDING)
+	money.SetMode(cfg.MoneyRounding)
+
 	// Connect to database
 	db, err := config.ConnectDatabase(cfg)
 	if err != nil {
@@ -56,11 +65,24 @@ func main() {
 	if err := models.AutoMigrate(db); err != nil {
 		log.Fatalf("❌ Failed to auto migrate: %v", err)
 	}
+
+	// Versioned migrations (schema changes to existing tables)
+	migrator, err := migrations.Open(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to open migrations: %v", err)
+	}
+	_, err = migrator.Up()
+	migrator.Close()
+	if err != nil {
+		log.Fatalf("❌ Failed to run migrations: %v", err)
+	}
 	log.Println("✅ Database migration completed")
 
-	// Seed master data (Phase 4)
-	if err := config.SeedMasterData(db); err != nil {
-		log.Printf("⚠️ Warning: Failed to seed master data: %v", err)
+	// Seed master data (Phase 4) - idempotent, disable with SEED_ON_STARTUP=false and use cmd/seed
+	if cfg.SeedOnStartup {
+		if err := config.SeedMasterData(db); err != nil {
+			log.Printf("⚠️ Warning: Failed to seed master data: %v", err)
+		}
 	}
 
 	// Start Cron Service for LINE reminders (08:30 daily)
@@ -72,6 +94,8 @@ func main() {
 	app := fiber.New(fiber.Config{
 		AppName:      "SPSC loanEasy API v1.0",
 		ErrorHandler: middleware.CustomErrorHandler,
+		// ขนาด request รวมสูงสุด = UPLOAD_MAX_BYTES + 1 MB เผื่อ multipart overhead
+		BodyLimit: int(cfg.Upload.MaxBytes) + 1<<20,
 	})
 
 	// Setup middlewares
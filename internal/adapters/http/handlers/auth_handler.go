@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -85,6 +86,8 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	result, err := h.authService.Register(c.Context(), input)
 	if err != nil {
 		switch {
+		case errors.Is(err, services.ErrInvalidMembNo):
+			return response.BadRequest(c, "Member number must be numeric, up to 5 digits")
 		case errors.Is(err, services.ErrMemberNotFound):
 			return response.NotFound(c, "Member number not found in system")
 		case errors.Is(err, services.ErrMemberAlreadyUsed):
@@ -97,7 +100,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Set cookies
-	h.setAuthCookies(c, result.AccessToken, result.RefreshToken)
+	h.setAuthCookies(c, result.AccessToken, result.RefreshToken, result.User.Role)
 
 	return response.Created(c, "User registered successfully", fiber.Map{
 		"access_token": result.AccessToken,
@@ -149,7 +152,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Set cookies
-	h.setAuthCookies(c, result.AccessToken, result.RefreshToken)
+	h.setAuthCookies(c, result.AccessToken, result.RefreshToken, result.User.Role)
 
 	return response.Success(c, "Login successful", fiber.Map{
 		"access_token": result.AccessToken,
@@ -195,7 +198,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	}
 
 	// Set new cookies
-	h.setAuthCookies(c, result.AccessToken, result.RefreshToken)
+	h.setAuthCookies(c, result.AccessToken, result.RefreshToken, result.User.Role)
 
 	return response.Success(c, "Token refreshed successfully", fiber.Map{
 		"access_token": result.AccessToken,
@@ -281,14 +284,125 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	})
 }
 
-// setAuthCookies sets access and refresh token cookies
-func (h *AuthHandler) setAuthCookies(c *fiber.Ctx, accessToken, refreshToken string) {
+// WhoamiResponse is the lightweight identity returned by /auth/whoami
+type WhoamiResponse struct {
+	ID          uint   `json:"id"`
+	MembNo      string `json:"memb_no"`
+	Username    string `json:"username"`
+	Role        string `json:"role"`
+	IsAdmin     bool   `json:"is_admin"`
+	IsOfficer   bool   `json:"is_officer"` // true for OFFICER and ADMIN
+	LineLinked  bool   `json:"line_linked"`
+	DeviceBound bool   `json:"device_bound"`
+
+	// ImpersonatedBy is the admin user ID when this is a support (impersonation) token
+	ImpersonatedBy *uint `json:"impersonated_by,omitempty"`
+}
+
+// Whoami returns the identity from the access token plus LINE/device binding status
+// @Summary Who am I
+// @Description Lightweight identity for LIFF/mobile: id, memb_no, username, role and LINE/device binding
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/whoami [get]
+func (h *AuthHandler) Whoami(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	membNo, _ := c.Locals("membNo").(string)
+	username, _ := c.Locals("username").(string)
+	role, _ := c.Locals("role").(string)
+
+	// token อาจยังไม่หมดอายุแต่ user ถูกลบไปแล้ว
+	user, err := h.authService.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return response.Unauthorized(c, "User not found")
+	}
+
+	resp := WhoamiResponse{
+		ID:          userID,
+		MembNo:      membNo,
+		Username:    username,
+		Role:        role,
+		IsAdmin:     role == "ADMIN",
+		IsOfficer:   role == "OFFICER" || role == "ADMIN",
+		LineLinked:  user.IsLineLinked(),
+		DeviceBound: user.DeviceID != nil && *user.DeviceID != "",
+	}
+	if adminID, ok := c.Locals("impersonatedBy").(uint); ok {
+		resp.ImpersonatedBy = &adminID
+	}
+
+	return response.Success(c, "Identity retrieved successfully", resp)
+}
+
+// ImpersonateRequest represents impersonate request
+type ImpersonateRequest struct {
+	Reason string `json:"reason,omitempty"` // เหตุผล/เลข ticket สำหรับ audit log
+}
+
+// Impersonate issues a support token for another user
+// @Summary Impersonate user
+// @Description Issue a short-lived access token (IMPERSONATION_TOKEN_MINUTES, default 15) that acts as the user, with an impersonated_by claim. No cookies or refresh token are set; every token is recorded in the audit log. The token is read-only: any request other than GET/HEAD/OPTIONS returns 403. Only member (USER) accounts can be impersonated (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param body body ImpersonateRequest false "Reason"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /admin/users/{id}/impersonate [post]
+func (h *AuthHandler) Impersonate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	var req ImpersonateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	adminID, _ := c.Locals("userID").(uint)
+
+	result, err := h.authService.Impersonate(c.Context(), uint(id), adminID, getClientIP(c), strings.TrimSpace(req.Reason))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return response.NotFound(c, "User not found")
+		case errors.Is(err, services.ErrCannotImpersonate):
+			return response.Forbidden(c, "Only member accounts can be impersonated")
+		case errors.Is(err, services.ErrUserInactive):
+			return response.BadRequest(c, "User account is inactive")
+		default:
+			return response.InternalServerError(c, "Failed to impersonate user")
+		}
+	}
+
+	return response.Success(c, "Impersonation token issued", result)
+}
+
+// setAuthCookies sets access and refresh token cookies (lifetimes follow the user's role)
+func (h *AuthHandler) setAuthCookies(c *fiber.Ctx, accessToken, refreshToken, role string) {
+	accessMins, refreshDays := h.cfg.JWT.TokenExpiry(role)
+
 	// Access token cookie (shorter expiry)
 	c.Cookie(&fiber.Cookie{
 		Name:     "access_token",
 		Value:    accessToken,
 		Path:     "/",
-		MaxAge:   h.cfg.JWT.AccessTokenMins * 60, // Convert minutes to seconds
+		MaxAge:   accessMins * 60, // Convert minutes to seconds
 		Secure:   h.cfg.Cookie.Secure,
 		HTTPOnly: true,
 		SameSite: h.cfg.Cookie.SameSite,
@@ -300,7 +414,7 @@ func (h *AuthHandler) setAuthCookies(c *fiber.Ctx, accessToken, refreshToken str
 		Name:     "refresh_token",
 		Value:    refreshToken,
 		Path:     "/",
-		MaxAge:   h.cfg.JWT.RefreshTokenDays * 24 * 60 * 60, // Convert days to seconds
+		MaxAge:   refreshDays * 24 * 60 * 60, // Convert days to seconds
 		Secure:   h.cfg.Cookie.Secure,
 		HTTPOnly: true,
 		SameSite: h.cfg.Cookie.SameSite,
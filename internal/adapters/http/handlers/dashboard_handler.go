@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"strconv"
+	"time"
+
 	"spsc-loaneasy/internal/core/services"
 	"spsc-loaneasy/internal/pkg/response"
+	"spsc-loaneasy/internal/pkg/timeutil"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -39,6 +43,144 @@ func (h *DashboardHandler) GetAdminDashboard(c *fiber.Ctx) error {
 	return response.Success(c, "Admin dashboard retrieved successfully", data)
 }
 
+// GetOfficerPerformance returns per-officer metrics for a period
+// @Summary Officer Performance
+// @Description Get case counts and average time-to-decision per officer (Admin only). Defaults to the current month
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /dashboard/admin/officers [get]
+func (h *DashboardHandler) GetOfficerPerformance(c *fiber.Ctx) error {
+	now := timeutil.Now()
+	from := timeutil.StartOfMonth(now)
+	to := timeutil.StartOfDay(now)
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation(timeutil.DateLayout, v, timeutil.Location())
+		if err != nil {
+			return response.BadRequest(c, "Invalid from date (use YYYY-MM-DD)")
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation(timeutil.DateLayout, v, timeutil.Location())
+		if err != nil {
+			return response.BadRequest(c, "Invalid to date (use YYYY-MM-DD)")
+		}
+		to = t
+	}
+	if to.Before(from) {
+		return response.BadRequest(c, "from must be before to")
+	}
+
+	// to เป็นวันสุดท้ายแบบ inclusive จึงส่งต่อเป็นเที่ยงคืนของวันถัดไป
+	data, err := h.dashboardService.GetOfficerPerformance(c.Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get officer performance")
+	}
+
+	return response.Success(c, "Officer performance retrieved successfully", data)
+}
+
+// GetPipeline returns the step workflow with case counts
+// @Summary Pipeline
+// @Description Get every active step in order with the number and total amount of cases currently at that step, including empty steps (Admin only)
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /dashboard/admin/pipeline [get]
+func (h *DashboardHandler) GetPipeline(c *fiber.Ctx) error {
+	data, err := h.dashboardService.GetPipeline(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get pipeline")
+	}
+
+	return response.Success(c, "Pipeline retrieved successfully", data)
+}
+
+// SuggestOfficer suggests the least-loaded officer for a new case
+// @Summary Suggest officer
+// @Description Get every active officer's open (non-final) case count, least loaded first, and the suggested officer to assign a new mortgage to (Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/suggest-officer [get]
+func (h *DashboardHandler) SuggestOfficer(c *fiber.Ctx) error {
+	workload, err := h.dashboardService.GetOfficerWorkload(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get officer workload")
+	}
+
+	return response.Success(c, "Officer suggestion retrieved successfully", fiber.Map{
+		"suggested": services.SuggestOfficer(workload),
+		"workload":  workload,
+	})
+}
+
+// GetOutstandingDocs lists members whose open cases still miss required documents
+// @Summary Outstanding documents by member
+// @Description List open (non-final) cases that still miss required documents, grouped by member, for document follow-ups. Officers see their own caseload; admins see all cases or pass officer_id (Officer/Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param officer_id query int false "Officer ID (Admin only)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/outstanding-docs [get]
+func (h *DashboardHandler) GetOutstandingDocs(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(string)
+
+	var officerID *uint
+	if role == "ADMIN" {
+		if q := c.Query("officer_id"); q != "" {
+			id, err := strconv.ParseUint(q, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid officer ID")
+			}
+			uid := uint(id)
+			officerID = &uid
+		}
+	} else {
+		// Officers only see their own caseload
+		officerID = &userID
+	}
+
+	members, err := h.dashboardService.GetOutstandingDocs(c.Context(), officerID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get outstanding documents")
+	}
+
+	cases := 0
+	for _, m := range members {
+		cases += len(m.Cases)
+	}
+
+	return response.Success(c, "Outstanding documents retrieved successfully", fiber.Map{
+		"members":       members,
+		"total_members": len(members),
+		"total_cases":   cases,
+	})
+}
+
 // GetOfficerDashboard returns officer dashboard data
 // @Summary Officer Dashboard
 // @Description Get officer dashboard with assigned cases and tasks (Officer only)
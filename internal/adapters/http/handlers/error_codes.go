@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+
+	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorCode maps a service error to an HTTP status, a stable machine-readable code
+// and the human message shown to users. Clients should switch on Code, not on Message.
+type errorCode struct {
+	Err     error
+	Status  int
+	Code    string
+	Message string
+}
+
+// mortgageErrorCodes - service errors of the mortgage flow
+var mortgageErrorCodes = []errorCode{
+	{services.ErrMortgageNotFound, fiber.StatusNotFound, "MORTGAGE_NOT_FOUND", "Mortgage not found"},
+	{services.ErrLoanTypeNotFound, fiber.StatusNotFound, "LOAN_TYPE_NOT_FOUND", "Loan type not found"},
+	{services.ErrLoanStepNotFound, fiber.StatusNotFound, "STEP_NOT_FOUND", "Step not found"},
+	{services.ErrLoanDocNotFound, fiber.StatusNotFound, "DOC_NOT_FOUND", "Document not found"},
+	{services.ErrLoanApptNotFound, fiber.StatusNotFound, "APPT_TYPE_NOT_FOUND", "Appointment type not found"},
+	{services.ErrInvalidMembNo, fiber.StatusBadRequest, "INVALID_MEMB_NO", "Member number must be numeric, up to 5 digits"},
+	{services.ErrMemberNotFoundMortgage, fiber.StatusNotFound, "MEMBER_NOT_FOUND", "Member not found"},
+	{services.ErrOfficerNotFound, fiber.StatusNotFound, "OFFICER_NOT_FOUND", "Officer not found"},
+	{services.ErrAlreadyClaimed, fiber.StatusConflict, "ALREADY_CLAIMED", "Mortgage is already assigned to an officer"},
+	{services.ErrApptNotFound, fiber.StatusNotFound, "APPT_NOT_FOUND", "Appointment not found"},
+	{services.ErrApptAlreadyCompleted, fiber.StatusConflict, "APPT_ALREADY_COMPLETED", "Appointment already completed"},
+	{services.ErrAttachmentNotFound, fiber.StatusNotFound, "FILE_NOT_FOUND", "File not found"},
+	{services.ErrNotAuthorized, fiber.StatusForbidden, "NOT_AUTHORIZED", "Not authorized"},
+	{services.ErrInvalidStep, fiber.StatusBadRequest, "INVALID_STEP", "Invalid step transition"},
+	{services.ErrAlreadyApproved, fiber.StatusBadRequest, "ALREADY_APPROVED", "Mortgage already approved"},
+	{services.ErrNotFinalStep, fiber.StatusConflict, "CASE_NOT_FINAL", "Only approved, rejected or cancelled cases can be cloned"},
+	{services.ErrInvalidDate, fiber.StatusBadRequest, "INVALID_DATE", "Invalid date format, use YYYY-MM-DD"},
+	{services.ErrInvalidApptTime, fiber.StatusBadRequest, "INVALID_APPT_TIME", "Invalid appointment time, use 24h HH:MM"},
+	{services.ErrInvalidTxType, fiber.StatusBadRequest, "INVALID_TRANSACTION_TYPE", "Unknown transaction type"},
+	{services.ErrInvalidDateRange, fiber.StatusBadRequest, "INVALID_DATE_RANGE", "Start date must not be after end date"},
+	{services.ErrInvalidContractNo, fiber.StatusBadRequest, "INVALID_CONTRACT_NO", "Contract number format is invalid"},
+	{services.ErrContractNoUsed, fiber.StatusConflict, "CONTRACT_NO_USED", "Contract number already used"},
+	{services.ErrGuarantorRequired, fiber.StatusBadRequest, "GUARANTOR_REQUIRED", "This loan type requires a guarantor"},
+	{services.ErrGuarantorNotFound, fiber.StatusBadRequest, "GUARANTOR_NOT_FOUND", "Guarantor member not found"},
+	{services.ErrGuarantorIsBorrower, fiber.StatusBadRequest, "GUARANTOR_IS_BORROWER", "Guarantor must be a different member"},
+	{services.ErrAmountOutOfRange, fiber.StatusBadRequest, "AMOUNT_OUT_OF_RANGE", "Amount is outside the loan type limits"},
+	{services.ErrInvalidApprovedAmount, fiber.StatusBadRequest, "INVALID_APPROVED_AMOUNT", "Approved amount must be positive and not more than the requested amount"},
+	{services.ErrAlreadyRecommended, fiber.StatusConflict, "PENDING_FINAL_APPROVAL", "Mortgage is waiting for a second approver to confirm"},
+	{services.ErrNotPendingFinal, fiber.StatusConflict, "NOT_PENDING_FINAL_APPROVAL", "Mortgage is not waiting for final approval"},
+	{services.ErrSelfConfirm, fiber.StatusForbidden, "SELF_CONFIRM", "The recommending user cannot confirm the approval"},
+	{services.ErrExceedsApprovalLimit, fiber.StatusForbidden, "EXCEEDS_APPROVAL_LIMIT", "Amount exceeds your approval limit, an admin must approve"},
+	{services.ErrDocsOutstanding, fiber.StatusConflict, "DOCS_OUTSTANDING", "Required documents are not submitted"},
+	{services.ErrVersionConflict, fiber.StatusConflict, "VERSION_CONFLICT", "Mortgage was modified by another user, please reload"},
+	{services.ErrLINENotConfigured, fiber.StatusServiceUnavailable, "LINE_NOT_CONFIGURED", "LINE messaging is not configured"},
+	{services.ErrStorageNotConfigured, fiber.StatusServiceUnavailable, "STORAGE_NOT_CONFIGURED", "File storage is not configured"},
+	{services.ErrPDFUnavailable, fiber.StatusServiceUnavailable, "PDF_UNAVAILABLE", "PDF download is not available, the Thai font is not installed"},
+}
+
+// webhookErrorCodes - service errors of webhook subscriptions
+var webhookErrorCodes = []errorCode{
+	{services.ErrWebhookNotFound, fiber.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found"},
+	{services.ErrWebhookInvalidURL, fiber.StatusBadRequest, "WEBHOOK_INVALID_URL", services.ErrWebhookInvalidURL.Error()},
+	{services.ErrWebhookNoEvents, fiber.StatusBadRequest, "WEBHOOK_NO_EVENTS", services.ErrWebhookNoEvents.Error()},
+	{services.ErrWebhookBadEvent, fiber.StatusBadRequest, "WEBHOOK_BAD_EVENT", services.ErrWebhookBadEvent.Error()},
+}
+
+// mapError writes the response for the first matching error code
+// Unknown errors become a 500 with the fallback message
+func mapError(c *fiber.Ctx, err error, codes []errorCode, fallback string) error {
+	if ec, ok := lookupErrorCode(err, codes); ok {
+		return response.ErrorWithCode(c, ec.Status, ec.Code, ec.Message)
+	}
+	return response.InternalServerError(c, fallback)
+}
+
+// lookupErrorCode finds the entry for err (used where errors are reported per item, not as the response)
+func lookupErrorCode(err error, codes []errorCode) (errorCode, bool) {
+	for _, ec := range codes {
+		if errors.Is(err, ec.Err) {
+			return ec, true
+		}
+	}
+	return errorCode{}, false
+}
+
+// mortgageError maps mortgage service errors to responses
+func mortgageError(c *fiber.Ctx, err error, fallback string) error {
+	return mapError(c, err, mortgageErrorCodes, fallback)
+}
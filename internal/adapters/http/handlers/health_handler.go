@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
+
 	"spsc-loaneasy/internal/config"
+	"spsc-loaneasy/internal/pkg/maintenance"
+	"spsc-loaneasy/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -78,3 +83,47 @@ func (h *HealthHandler) APIInfo(c *fiber.Ctx) error {
 		"v2":      "/api/v2 - Mobile Optimized APIs",
 	})
 }
+
+// MaintenanceRequest represents a maintenance mode toggle
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"` // optional message shown to blocked clients
+}
+
+// GetMaintenance handles getting the maintenance mode status
+// @Summary Get maintenance mode
+// @Description Returns whether write operations are currently blocked (Admin only)
+// @Tags System
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /system/maintenance [get]
+func (h *HealthHandler) GetMaintenance(c *fiber.Ctx) error {
+	return response.Success(c, "Maintenance status retrieved", maintenance.Get())
+}
+
+// SetMaintenance handles turning maintenance mode on or off
+// @Summary Toggle maintenance mode
+// @Description While on, POST/PUT/PATCH/DELETE return 503 with Retry-After; reads, health and auth endpoints keep working (Admin only)
+// @Tags System
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body MaintenanceRequest true "Maintenance mode"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /system/maintenance [put]
+func (h *HealthHandler) SetMaintenance(c *fiber.Ctx) error {
+	var req MaintenanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	username, _ := c.Locals("username").(string)
+	userID, _ := c.Locals("userID").(uint)
+	state := maintenance.Set(req.Enabled, strings.TrimSpace(req.Message), fmt.Sprintf("%s (#%d)", username, userID))
+
+	return response.Success(c, "Maintenance mode updated", state)
+}
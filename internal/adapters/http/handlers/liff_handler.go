@@ -1,15 +1,18 @@
 package handlers
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
+	"spsc-loaneasy/internal/config"
 	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/i18n"
 	"spsc-loaneasy/internal/pkg/jwt"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/membno"
 	"spsc-loaneasy/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -27,35 +30,20 @@ import (
 // ============================================================
 
 type LIFFHandler struct {
-	db              *gorm.DB
-	lineService     *services.LINEService
-	otpService      *services.OTPService
-	jwtSecret       string
-	accessTokenExp  int
-	refreshTokenExp int
+	db          *gorm.DB
+	lineService *services.LINEService
+	otpService  *services.OTPService
+	jwtSecret   string
+	jwtCfg      config.JWTConfig
 }
 
-func NewLIFFHandler(db *gorm.DB, lineService *services.LINEService, otpService *services.OTPService) *LIFFHandler {
-	jwtSecret := os.Getenv("PROD_JWT_SECRET")
-	accessTokenExp := 1440
-	if exp := os.Getenv("ACCESS_TOKEN_EXPIRY"); exp != "" {
-		if val, err := strconv.Atoi(exp); err == nil {
-			accessTokenExp = val
-		}
-	}
-	refreshTokenExp := 7
-	if exp := os.Getenv("REFRESH_TOKEN_EXPIRY"); exp != "" {
-		if val, err := strconv.Atoi(exp); err == nil {
-			refreshTokenExp = val
-		}
-	}
+func NewLIFFHandler(db *gorm.DB, lineService *services.LINEService, otpService *services.OTPService, cfg *config.Config) *LIFFHandler {
 	return &LIFFHandler{
-		db:              db,
-		lineService:     lineService,
-		otpService:      otpService,
-		jwtSecret:       jwtSecret,
-		accessTokenExp:  accessTokenExp,
-		refreshTokenExp: refreshTokenExp,
+		db:          db,
+		lineService: lineService,
+		otpService:  otpService,
+		jwtSecret:   cfg.JWT.Secret,
+		jwtCfg:      cfg.JWT,
 	}
 }
 
@@ -119,18 +107,18 @@ type DeviceChangeRequest struct {
 func (h *LIFFHandler) CheckLineUser(c *fiber.Ctx) error {
 	var req CheckLineUserRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	if req.LineAccessToken == "" {
-		return response.BadRequest(c, "กรุณาระบุ LINE Access Token")
+		return response.BadRequest(c, tr(c, "liff.token_required"))
 	}
 
 	// ✅ Verify LINE Access Token แล้วดึง profile จาก LINE โดยตรง
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		log.Printf("LINE token verify failed: %v", err)
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง กรุณา login LINE ใหม่")
+		logger.FromContext(c.Context()).Warn("LINE token verify failed", "error", err)
+		return response.Unauthorized(c, tr(c, "liff.token_invalid_relogin"))
 	}
 
 	// ใช้ LINE User ID จาก profile (ไม่ใช่จาก client)
@@ -145,7 +133,7 @@ func (h *LIFFHandler) CheckLineUser(c *fiber.Ctx) error {
 		h.db.Raw("SELECT device_id FROM users WHERE line_user_id = ? AND deleted_at IS NULL", lineUserID).Scan(&registeredDeviceID)
 	}
 
-	return response.Success(c, "ตรวจสอบสำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.check_ok"), fiber.Map{
 		"exists":        count > 0,
 		"line_user_id":  lineUserID,
 		"display_name":  profile.DisplayName,
@@ -166,23 +154,23 @@ func (h *LIFFHandler) CheckLineUser(c *fiber.Ctx) error {
 func (h *LIFFHandler) RequestOTP(c *fiber.Ctx) error {
 	var req RequestOTPRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	if req.LineAccessToken == "" || req.MembNo == "" || req.Phone == "" {
-		return response.BadRequest(c, "กรุณาระบุข้อมูลให้ครบ")
+		return response.BadRequest(c, tr(c, "common.missing_fields"))
 	}
 
 	// ✅ Verify LINE Token
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง")
+		return response.Unauthorized(c, tr(c, "liff.token_invalid"))
 	}
 
 	// Pad member number
-	membNo := req.MembNo
-	for len(membNo) < 5 {
-		membNo = "0" + membNo
+	membNo, ok := membno.Normalize(req.MembNo)
+	if !ok {
+		return response.BadRequest(c, tr(c, "common.invalid_memb_no"))
 	}
 
 	// ตรวจเลขสมาชิกในระบบ flommast
@@ -190,14 +178,14 @@ func (h *LIFFHandler) RequestOTP(c *fiber.Ctx) error {
 	row := h.db.Raw("SELECT MAST_MEMB_NO, MAST_MOBILE FROM flommast WHERE MAST_MEMB_NO = ?", membNo).Row()
 	err = row.Scan(&mastMembNo, &mastMobile)
 	if err != nil || mastMembNo == "" {
-		return response.BadRequest(c, "ไม่พบเลขสมาชิกนี้ในระบบ")
+		return response.BadRequest(c, tr(c, "common.member_notfound"))
 	}
 
 	// ✅ ตรวจว่าเบอร์โทรตรงกับในระบบ
 	cleanPhone := cleanPhoneNumber(req.Phone)
 	cleanMastMobile := cleanPhoneNumber(mastMobile)
 	if cleanPhone != cleanMastMobile {
-		return response.BadRequest(c, "เบอร์โทรไม่ตรงกับข้อมูลสมาชิก")
+		return response.BadRequest(c, tr(c, "liff.phone_mismatch"))
 	}
 
 	// Generate OTP
@@ -211,14 +199,14 @@ func (h *LIFFHandler) RequestOTP(c *fiber.Ctx) error {
 	// TODO: เชื่อมกับ SMS Provider จริง (เช่น ThaiBulkSMS, Twilio, etc.)
 	// ตอนนี้ส่งผ่าน LINE push message แทน (สำหรับ dev/test)
 	// ============================================================
-	smsMessage := fmt.Sprintf("รหัส OTP ของคุณคือ: %s (หมดอายุใน 5 นาที) - สหกรณ์ SPSC", otpCode)
+	smsMessage := tr(c, "liff.otp_message", otpCode)
 
 	// ส่งผ่าน LINE message (ชั่วคราว - ควรเปลี่ยนเป็น SMS จริง)
 	channelAccessToken := os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
 	if channelAccessToken != "" {
 		go func() {
 			if err := h.lineService.SendPushMessage(profile.UserID, smsMessage, channelAccessToken); err != nil {
-				log.Printf("Failed to send OTP via LINE: %v", err)
+				slog.Error("failed to send OTP via LINE", "memb_no", membNo, "error", err)
 			}
 		}()
 	}
@@ -226,9 +214,9 @@ func (h *LIFFHandler) RequestOTP(c *fiber.Ctx) error {
 	// ⚠️ Production: ให้ใช้ SMS API จริง
 	// sendSMS(cleanPhone, smsMessage)
 
-	log.Printf("📱 OTP Generated for member %s, phone %s: %s", membNo, cleanPhone, otpCode)
+	logger.FromContext(c.Context()).Info("OTP generated", "memb_no", membNo)
 
-	return response.Success(c, "ส่ง OTP สำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.otp_sent"), fiber.Map{
 		"phone_masked": maskPhone(cleanPhone),
 		"otp_code":     otpCode, // ✅ ส่ง OTP กลับให้ frontend แสดงในหน้าเว็บ (ไม่ต้องสลับไปดูใน LINE)
 		"expires_in":   300,     // 5 minutes
@@ -248,17 +236,17 @@ func (h *LIFFHandler) RequestOTP(c *fiber.Ctx) error {
 func (h *LIFFHandler) VerifyOTP(c *fiber.Ctx) error {
 	var req VerifyOTPRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	if req.LineAccessToken == "" || req.OTPCode == "" {
-		return response.BadRequest(c, "กรุณาระบุข้อมูลให้ครบ")
+		return response.BadRequest(c, tr(c, "common.missing_fields"))
 	}
 
 	// Verify LINE Token
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง")
+		return response.Unauthorized(c, tr(c, "liff.token_invalid"))
 	}
 
 	// Verify OTP
@@ -266,7 +254,7 @@ func (h *LIFFHandler) VerifyOTP(c *fiber.Ctx) error {
 		return response.BadRequest(c, err.Error())
 	}
 
-	return response.Success(c, "ยืนยัน OTP สำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.otp_verified"), fiber.Map{
 		"verified": true,
 	})
 }
@@ -284,29 +272,29 @@ func (h *LIFFHandler) VerifyOTP(c *fiber.Ctx) error {
 func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 	var req LIFFRegisterRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	// Validate required fields
 	if req.LineAccessToken == "" || req.MembNo == "" {
-		return response.BadRequest(c, "กรุณาระบุข้อมูลให้ครบ")
+		return response.BadRequest(c, tr(c, "common.missing_fields"))
 	}
 	if req.DeviceID == "" {
-		return response.BadRequest(c, "กรุณาระบุ Device ID")
+		return response.BadRequest(c, tr(c, "liff.device_required"))
 	}
 	if req.OTPCode == "" {
-		return response.BadRequest(c, "กรุณาระบุรหัส OTP")
+		return response.BadRequest(c, tr(c, "liff.otp_required"))
 	}
 
 	// ✅ ตรวจ Network Type - บังคับ Cellular
-	if err := h.validateNetworkType(req.NetworkType); err != nil {
+	if err := h.validateNetworkType(i18n.FromRequest(c), req.NetworkType); err != nil {
 		return response.BadRequest(c, err.Error())
 	}
 
 	// ✅ Verify LINE Token แล้วดึง profile
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง กรุณา login LINE ใหม่")
+		return response.Unauthorized(c, tr(c, "liff.token_invalid_relogin"))
 	}
 
 	lineUserID := profile.UserID
@@ -317,23 +305,23 @@ func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Pad member number
-	membNo := req.MembNo
-	for len(membNo) < 5 {
-		membNo = "0" + membNo
+	membNo, ok := membno.Normalize(req.MembNo)
+	if !ok {
+		return response.BadRequest(c, tr(c, "common.invalid_memb_no"))
 	}
 
 	// ตรวจว่า LINE นี้ลงทะเบียนแล้วหรือยัง
 	var existingCount int64
 	h.db.Raw("SELECT COUNT(*) FROM users WHERE line_user_id = ? AND deleted_at IS NULL", lineUserID).Scan(&existingCount)
 	if existingCount > 0 {
-		return response.BadRequest(c, "LINE นี้ลงทะเบียนแล้ว")
+		return response.BadRequest(c, tr(c, "liff.line_registered"))
 	}
 
 	// ✅ ตรวจว่า Device ID นี้ผูกกับคนอื่นหรือยัง
 	var deviceCount int64
 	h.db.Raw("SELECT COUNT(*) FROM users WHERE device_id = ? AND deleted_at IS NULL", req.DeviceID).Scan(&deviceCount)
 	if deviceCount > 0 {
-		return response.BadRequest(c, "เครื่องนี้ลงทะเบียนกับบัญชีอื่นแล้ว กรุณาติดต่อสหกรณ์")
+		return response.BadRequest(c, tr(c, "liff.device_taken_contact"))
 	}
 
 	// ตรวจเลขสมาชิกใน flommast
@@ -341,7 +329,7 @@ func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 	row := h.db.Raw("SELECT MAST_MEMB_NO, Full_Name, DEPT_NAME, STS_TYPE_DESC, MAST_MOBILE FROM flommast WHERE MAST_MEMB_NO = ?", membNo).Row()
 	err = row.Scan(&mastMembNo, &fullName, &deptName, &stsTypeDesc, &mastMobile)
 	if err != nil || mastMembNo == "" {
-		return response.BadRequest(c, "ไม่พบเลขสมาชิกนี้ในระบบ")
+		return response.BadRequest(c, tr(c, "common.member_notfound"))
 	}
 
 	// Get verified phone from OTP
@@ -367,7 +355,7 @@ func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 		// Clear OTP
 		h.otpService.ClearOTP(lineUserID)
 
-		return response.Success(c, "ผูก LINE กับบัญชีสำเร็จ", fiber.Map{
+		return response.Success(c, tr(c, "liff.line_linked"), fiber.Map{
 			"memb_no":   membNo,
 			"full_name": fullName,
 			"linked":    true,
@@ -389,7 +377,7 @@ func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 	// Clear OTP
 	h.otpService.ClearOTP(lineUserID)
 
-	return response.Success(c, "ลงทะเบียนสำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.registered"), fiber.Map{
 		"memb_no":   membNo,
 		"full_name": fullName,
 		"linked":    false,
@@ -411,27 +399,27 @@ func (h *LIFFHandler) Register(c *fiber.Ctx) error {
 func (h *LIFFHandler) LoginWithLiff(c *fiber.Ctx) error {
 	var req LIFFLoginRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	if req.LineAccessToken == "" {
-		return response.BadRequest(c, "กรุณาระบุ LINE Access Token")
+		return response.BadRequest(c, tr(c, "liff.token_required"))
 	}
 	if req.DeviceID == "" {
-		return response.BadRequest(c, "กรุณาระบุ Device ID")
+		return response.BadRequest(c, tr(c, "liff.device_required"))
 	}
 
 	// ✅ Login: อนุญาต WiFi ได้ (บังคับ Cellular เฉพาะ Register เท่านั้น)
 	//    Security ตอน Login ใช้ LINE Token Verify + Device ID Check แทน
 	if nt := strings.ToLower(strings.TrimSpace(req.NetworkType)); nt == "wifi" {
-		log.Printf("⚠️ Login via WiFi (allowed) - will verify LINE token + device ID")
+		logger.FromContext(c.Context()).Info("LIFF login via WiFi (allowed, LINE token + device ID verified)")
 	}
 
 	// ✅ Verify LINE Token แล้วดึง profile จาก LINE โดยตรง
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		log.Printf("LINE token verify failed: %v", err)
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง กรุณา login LINE ใหม่")
+		logger.FromContext(c.Context()).Warn("LINE token verify failed", "error", err)
+		return response.Unauthorized(c, tr(c, "liff.token_invalid_relogin"))
 	}
 
 	lineUserID := profile.UserID
@@ -446,13 +434,13 @@ func (h *LIFFHandler) LoginWithLiff(c *fiber.Ctx) error {
 	err = row.Scan(&id, &username, &fullName, &email, &role, &membNo,
 		&deptName, &phone, &linePictureURL, &lineDisplayName, &deviceID)
 	if err != nil || id == 0 {
-		return response.NotFound(c, "ไม่พบผู้ใช้ในระบบ กรุณาลงทะเบียน")
+		return response.NotFound(c, tr(c, "liff.user_not_registered"))
 	}
 
 	// ✅ ตรวจ Device ID - ต้องตรงกับที่ลงทะเบียนไว้
 	if deviceID != nil && *deviceID != "" && *deviceID != req.DeviceID && role != "ADMIN" && role != "OFFICER" {
-		log.Printf("⚠️ Device mismatch for user %d: registered=%s, current=%s", id, *deviceID, req.DeviceID)
-		return response.Forbidden(c, "เครื่องนี้ไม่ตรงกับที่ลงทะเบียนไว้ กรุณาติดต่อสหกรณ์เพื่อเปลี่ยนเครื่อง")
+		logger.FromContext(c.Context()).Warn("LIFF device mismatch", "user_id", id, "registered_device", *deviceID, "device", req.DeviceID)
+		return response.Forbidden(c, tr(c, "liff.device_mismatch"))
 	}
 
 	// อัพเดท LINE profile + network type + last login
@@ -466,22 +454,23 @@ func (h *LIFFHandler) LoginWithLiff(c *fiber.Ctx) error {
 	// ถ้ายังไม่ได้ผูก device (user เก่าก่อนอัพเดท) ให้ผูกเลย
 	if deviceID == nil || *deviceID == "" {
 		h.db.Exec("UPDATE users SET device_id = ? WHERE id = ?", req.DeviceID, id)
-		log.Printf("📱 Auto-bound device %s to user %d", req.DeviceID, id)
+		logger.FromContext(c.Context()).Info("LIFF device auto-bound", "user_id", id, "device", req.DeviceID)
 	}
 
 	// Generate JWT tokens
-	accessToken, err := jwt.GenerateAccessToken(id, membNo, username, role, h.jwtSecret, h.accessTokenExp)
+	accessTokenExp, refreshTokenExp := h.jwtCfg.LineTokenExpiry(role)
+	accessToken, err := jwt.GenerateAccessToken(id, membNo, username, role, h.jwtSecret, accessTokenExp)
 	if err != nil {
-		return response.InternalServerError(c, "ไม่สามารถสร้าง Token ได้")
+		return response.InternalServerError(c, tr(c, "common.token_failed"))
 	}
 	tokenID := uuid.New().String()
-	refreshToken, err := jwt.GenerateRefreshToken(id, tokenID, h.jwtSecret, h.refreshTokenExp)
+	refreshToken, err := jwt.GenerateRefreshToken(id, tokenID, h.jwtSecret, refreshTokenExp)
 	if err != nil {
-		return response.InternalServerError(c, "ไม่สามารถสร้าง Token ได้")
+		return response.InternalServerError(c, tr(c, "common.token_failed"))
 	}
 
 	// Save refresh token
-	expiresAt := time.Now().AddDate(0, 0, h.refreshTokenExp)
+	expiresAt := time.Now().AddDate(0, 0, refreshTokenExp)
 	h.db.Exec("INSERT INTO refresh_tokens (user_id, token_hash, expires_at, created_at, updated_at) VALUES (?, ?, ?, NOW(), NOW())",
 		id, refreshToken, expiresAt)
 
@@ -493,7 +482,7 @@ func (h *LIFFHandler) LoginWithLiff(c *fiber.Ctx) error {
 		lineDisplayName = &req.LineDisplayName
 	}
 
-	return response.Success(c, "เข้าสู่ระบบสำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.login_ok"), fiber.Map{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
 		"user": fiber.Map{
@@ -524,17 +513,17 @@ func (h *LIFFHandler) LoginWithLiff(c *fiber.Ctx) error {
 func (h *LIFFHandler) ChangeDevice(c *fiber.Ctx) error {
 	var req DeviceChangeRequest
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	if req.LineAccessToken == "" || req.NewDeviceID == "" || req.OTPCode == "" {
-		return response.BadRequest(c, "กรุณาระบุข้อมูลให้ครบ")
+		return response.BadRequest(c, tr(c, "common.missing_fields"))
 	}
 
 	// Verify LINE Token
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง")
+		return response.Unauthorized(c, tr(c, "liff.token_invalid"))
 	}
 
 	lineUserID := profile.UserID
@@ -549,22 +538,22 @@ func (h *LIFFHandler) ChangeDevice(c *fiber.Ctx) error {
 	h.db.Raw("SELECT COUNT(*) FROM users WHERE device_id = ? AND line_user_id != ? AND deleted_at IS NULL",
 		req.NewDeviceID, lineUserID).Scan(&deviceCount)
 	if deviceCount > 0 {
-		return response.BadRequest(c, "เครื่องนี้ลงทะเบียนกับบัญชีอื่นแล้ว")
+		return response.BadRequest(c, tr(c, "liff.device_taken"))
 	}
 
 	// อัพเดท Device ID
 	result := h.db.Exec("UPDATE users SET device_id = ?, updated_at = NOW() WHERE line_user_id = ? AND deleted_at IS NULL",
 		req.NewDeviceID, lineUserID)
 	if result.RowsAffected == 0 {
-		return response.NotFound(c, "ไม่พบผู้ใช้ในระบบ")
+		return response.NotFound(c, tr(c, "common.user_notfound"))
 	}
 
 	// Clear OTP
 	h.otpService.ClearOTP(lineUserID)
 
-	log.Printf("📱 Device changed for LINE user %s: new device = %s", lineUserID, req.NewDeviceID)
+	logger.FromContext(c.Context()).Info("LIFF device changed", "line_user_id", lineUserID, "device", req.NewDeviceID)
 
-	return response.Success(c, "เปลี่ยนเครื่องสำเร็จ", fiber.Map{
+	return response.Success(c, tr(c, "liff.device_changed"), fiber.Map{
 		"new_device_id": req.NewDeviceID,
 	})
 }
@@ -582,13 +571,13 @@ func (h *LIFFHandler) GetDeviceInfo(c *fiber.Ctx) error {
 		LineAccessToken string `json:"line_access_token"`
 	}
 	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "ข้อมูลไม่ถูกต้อง")
+		return response.BadRequest(c, tr(c, "common.invalid_body"))
 	}
 
 	// Verify LINE Token
 	profile, err := h.lineService.VerifyAndGetProfile(req.LineAccessToken)
 	if err != nil {
-		return response.Unauthorized(c, "LINE Token ไม่ถูกต้อง")
+		return response.Unauthorized(c, tr(c, "liff.token_invalid"))
 	}
 
 	var result struct {
@@ -599,7 +588,7 @@ func (h *LIFFHandler) GetDeviceInfo(c *fiber.Ctx) error {
 	h.db.Raw("SELECT device_id, phone_verified, last_login FROM users WHERE line_user_id = ? AND deleted_at IS NULL",
 		profile.UserID).Scan(&result)
 
-	return response.Success(c, "ข้อมูลอุปกรณ์", fiber.Map{
+	return response.Success(c, tr(c, "liff.device_info"), fiber.Map{
 		"device_id":      result.DeviceID,
 		"phone_verified": result.PhoneVerified,
 		"last_login":     result.LastLogin,
@@ -611,7 +600,7 @@ func (h *LIFFHandler) GetDeviceInfo(c *fiber.Ctx) error {
 // ============================================================
 
 // validateNetworkType ตรวจว่าเป็น cellular หรือไม่
-func (h *LIFFHandler) validateNetworkType(networkType string) error {
+func (h *LIFFHandler) validateNetworkType(lang, networkType string) error {
 	// ถ้าไม่ส่งมา ให้ผ่าน (backward compatible / LIFF อาจตรวจไม่ได้)
 	if networkType == "" {
 		return nil
@@ -632,10 +621,10 @@ func (h *LIFFHandler) validateNetworkType(networkType string) error {
 	if !allowedTypes[nt] {
 		// WiFi หรือ type อื่นจะไม่อนุญาต
 		if nt == "wifi" {
-			return fmt.Errorf("กรุณาใช้อินเทอร์เน็ตมือถือ (Cellular) ในการเข้าสู่ระบบ ไม่สามารถใช้ WiFi ได้")
+			return errors.New(i18n.T(lang, "liff.wifi_not_allowed"))
 		}
 		// Unknown type - log แต่ให้ผ่าน (เพื่อ backward compatible)
-		log.Printf("⚠️ Unknown network type: %s - allowing", nt)
+		slog.Warn("unknown network type, allowing", "network_type", nt)
 		return nil
 	}
 
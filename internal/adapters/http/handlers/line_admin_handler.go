@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+
+	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// lineBindingErrorCodes - service errors of the LINE binding admin tools
+var lineBindingErrorCodes = []errorCode{
+	{services.ErrUserNotFoundSvc, fiber.StatusNotFound, "USER_NOT_FOUND", "User not found"},
+	{services.ErrLINENotBound, fiber.StatusConflict, "LINE_NOT_BOUND", "User has no LINE binding"},
+	{services.ErrLINENotDuplicate, fiber.StatusConflict, "LINE_NOT_DUPLICATE", "LINE binding is not shared with another user"},
+}
+
+// LINEAdminHandler handles admin tools for LINE bindings
+type LINEAdminHandler struct {
+	bindingService *services.LINEBindingService
+}
+
+// NewLINEAdminHandler creates a new LINE admin handler
+func NewLINEAdminHandler(bindingService *services.LINEBindingService) *LINEAdminHandler {
+	return &LINEAdminHandler{
+		bindingService: bindingService,
+	}
+}
+
+// ListDuplicates reports LINE ids bound to more than one user
+// @Summary List duplicate LINE bindings
+// @Description List LINE ids bound to more than one non-deleted user, each with the users holding it (most recently linked first) (Admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /admin/line/duplicates [get]
+func (h *LINEAdminHandler) ListDuplicates(c *fiber.Ctx) error {
+	duplicates, err := h.bindingService.ListDuplicates(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list duplicate LINE bindings")
+	}
+
+	return response.Success(c, "Duplicate LINE bindings retrieved successfully", fiber.Map{
+		"duplicates": duplicates,
+		"total":      len(duplicates),
+	})
+}
+
+// UnbindDuplicate removes a stale LINE binding
+// @Summary Unbind duplicate LINE binding
+// @Description Clear the LINE binding of a user whose LINE id is also bound to another user. The other user keeps the binding; the action is recorded in the audit log (Admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "User ID of the stale row"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /admin/line/duplicates/{user_id}/unbind [post]
+func (h *LINEAdminHandler) UnbindDuplicate(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("user_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	adminID, _ := c.Locals("userID").(uint)
+
+	if err := h.bindingService.UnbindDuplicate(c.Context(), uint(userID), adminID, getClientIP(c)); err != nil {
+		return mapError(c, err, lineBindingErrorCodes, "Failed to unbind LINE")
+	}
+
+	return response.Success(c, "LINE binding removed successfully", nil)
+}
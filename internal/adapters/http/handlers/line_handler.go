@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"spsc-loaneasy/internal/config"
 	"spsc-loaneasy/internal/core/services"
 	"spsc-loaneasy/internal/pkg/jwt"
 	"spsc-loaneasy/internal/pkg/response"
@@ -18,46 +19,28 @@ import (
 
 // LINEHandler handles LINE related requests
 type LINEHandler struct {
-	lineService     *services.LINEService
-	db              *gorm.DB
-	jwtSecret       string
-	accessTokenExp  int // minutes
-	refreshTokenExp int // days
+	lineService *services.LINEService
+	db          *gorm.DB
+	jwtSecret   string
+	jwtCfg      config.JWTConfig // token lifetimes per role (LineTokenExpiry)
 }
 
 // NewLINEHandler creates a new LINE handler
-func NewLINEHandler(db *gorm.DB) *LINEHandler {
+func NewLINEHandler(db *gorm.DB, cfg *config.Config) *LINEHandler {
 	channelID := os.Getenv("LINE_CHANNEL_ID")
 	channelSecret := os.Getenv("LINE_CHANNEL_SECRET")
 	callbackURL := os.Getenv("LINE_CALLBACK_URL")
 	liffChannelID := os.Getenv("LIFF_CHANNEL_ID") // ✅ LIFF Channel ID
-	jwtSecret := os.Getenv("PROD_JWT_SECRET")
 
 	if callbackURL == "" {
 		callbackURL = "https://api.loanspsc.com/api/v1/auth/line/callback"
 	}
 
-	// Token expiry settings
-	accessTokenExp := 1440 // 24 hours in minutes
-	if exp := os.Getenv("ACCESS_TOKEN_EXPIRY"); exp != "" {
-		if val, err := strconv.Atoi(exp); err == nil {
-			accessTokenExp = val
-		}
-	}
-
-	refreshTokenExp := 7 // 7 days
-	if exp := os.Getenv("REFRESH_TOKEN_EXPIRY"); exp != "" {
-		if val, err := strconv.Atoi(exp); err == nil {
-			refreshTokenExp = val
-		}
-	}
-
 	return &LINEHandler{
-		lineService:     services.NewLINEService(db, channelID, channelSecret, callbackURL, liffChannelID),
-		db:              db,
-		jwtSecret:       jwtSecret,
-		accessTokenExp:  accessTokenExp,
-		refreshTokenExp: refreshTokenExp,
+		lineService: services.NewLINEService(db, channelID, channelSecret, callbackURL, liffChannelID),
+		db:          db,
+		jwtSecret:   cfg.JWT.Secret,
+		jwtCfg:      cfg.JWT,
 	}
 }
 
@@ -201,6 +184,8 @@ func (h *LINEHandler) LINECallback(c *fiber.Ctx) error {
 	}
 
 	// User found - generate JWT tokens
+	accessTokenExp, refreshTokenExp := h.jwtCfg.LineTokenExpiry(user.Role)
+
 	// GenerateAccessToken(userID uint, membNo, username, role, secret string, expiryMinutes int)
 	accessToken, err := jwt.GenerateAccessToken(
 		user.ID,
@@ -208,7 +193,7 @@ func (h *LINEHandler) LINECallback(c *fiber.Ctx) error {
 		user.Username,
 		user.Role,
 		h.jwtSecret,
-		h.accessTokenExp,
+		accessTokenExp,
 	)
 	if err != nil {
 		return c.Redirect(frontendURL + "/login?error=token_generation_failed")
@@ -222,14 +207,14 @@ func (h *LINEHandler) LINECallback(c *fiber.Ctx) error {
 		user.ID,
 		tokenID,
 		h.jwtSecret,
-		h.refreshTokenExp,
+		refreshTokenExp,
 	)
 	if err != nil {
 		return c.Redirect(frontendURL + "/login?error=token_generation_failed")
 	}
 
 	// Save refresh token to database
-	expiresAt := time.Now().AddDate(0, 0, h.refreshTokenExp)
+	expiresAt := time.Now().AddDate(0, 0, refreshTokenExp)
 	h.db.Exec(`
 		INSERT INTO refresh_tokens (user_id, token_hash, expires_at, created_at, updated_at)
 		VALUES (?, ?, ?, NOW(), NOW())
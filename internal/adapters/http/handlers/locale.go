@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"spsc-loaneasy/internal/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tr translates a message id into the language requested by the client (?lang= / Accept-Language)
+func tr(c *fiber.Ctx, key string, args ...interface{}) string {
+	return i18n.T(i18n.FromRequest(c), key, args...)
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/core/services"
 	"spsc-loaneasy/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +18,8 @@ type MasterHandler struct {
 	loanStepRepo *repositories.LoanStepRepository
 	loanDocRepo  *repositories.LoanDocRepository
 	loanApptRepo *repositories.LoanApptRepository
+
+	masterService *services.MasterService
 }
 
 // NewMasterHandler creates a new master handler
@@ -24,15 +28,72 @@ func NewMasterHandler(
 	loanStepRepo *repositories.LoanStepRepository,
 	loanDocRepo *repositories.LoanDocRepository,
 	loanApptRepo *repositories.LoanApptRepository,
+	masterService *services.MasterService,
 ) *MasterHandler {
 	return &MasterHandler{
-		loanTypeRepo: loanTypeRepo,
-		loanStepRepo: loanStepRepo,
-		loanDocRepo:  loanDocRepo,
-		loanApptRepo: loanApptRepo,
+		loanTypeRepo:  loanTypeRepo,
+		loanStepRepo:  loanStepRepo,
+		loanDocRepo:   loanDocRepo,
+		loanApptRepo:  loanApptRepo,
+		masterService: masterService,
 	}
 }
 
+// ============================================================
+// Bulk (setup wizard)
+// ============================================================
+
+// GetAll returns all master data sets in one response
+// @Summary Get all master data
+// @Description Get loan types, steps, docs and appointment types, including inactive rows (Admin only)
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /master/all [get]
+func (h *MasterHandler) GetAll(c *fiber.Ctx) error {
+	bundle, err := h.masterService.GetAll(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get master data")
+	}
+
+	return response.Success(c, "Master data retrieved successfully", bundle)
+}
+
+// Import upserts a master data bundle by code
+// @Summary Import master data
+// @Description Create or update loan types, steps, docs and appointment types by code in one transaction. The result must keep an active first step and active APPROVED / REJECTED steps (Admin only)
+// @Tags Master
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body services.MasterBundle true "Master data bundle"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /master/import [post]
+func (h *MasterHandler) Import(c *fiber.Ctx) error {
+	var bundle services.MasterBundle
+	if err := c.BodyParser(&bundle); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	result, err := h.masterService.Import(c.Context(), &bundle)
+	if err != nil {
+		var importErr *services.MasterImportError
+		if errors.As(err, &importErr) {
+			return response.ErrorWithData(c, fiber.StatusBadRequest, "INVALID_MASTER_IMPORT",
+				"Master data import is invalid", fiber.Map{"problems": importErr.Problems})
+		}
+		return response.InternalServerError(c, "Failed to import master data")
+	}
+
+	return response.Success(c, "Master data imported successfully", result)
+}
+
 // ============================================================
 // Loan Type
 // ============================================================
@@ -101,10 +162,20 @@ func (h *MasterHandler) GetLoanType(c *fiber.Ctx) error {
 
 // CreateLoanTypeRequest represents create loan type request
 type CreateLoanTypeRequest struct {
-	Code         string  `json:"code"`
-	Name         string  `json:"name"`
+	Code         string  `json:"code" validate:"required"`
+	Name         string  `json:"name" validate:"required"`
 	Description  string  `json:"description,omitempty"`
 	InterestRate float64 `json:"interest_rate"`
+
+	// Amount limits (0 = no limit). On update, omit to keep the current value
+	MinAmount *float64 `json:"min_amount,omitempty" validate:"omitempty,gte=0"`
+	MaxAmount *float64 `json:"max_amount,omitempty" validate:"omitempty,gte=0"`
+
+	// RequiresGuarantor - on update, omit to keep the current value
+	RequiresGuarantor *bool `json:"requires_guarantor,omitempty"`
+
+	// RequiresDualApproval - approvals need a second user to confirm; on update, omit to keep the current value
+	RequiresDualApproval *bool `json:"requires_dual_approval,omitempty"`
 }
 
 // CreateLoanType creates a new loan type
@@ -117,6 +188,7 @@ type CreateLoanTypeRequest struct {
 // @Param body body CreateLoanTypeRequest true "Loan type data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /master/loan-types [post]
@@ -126,8 +198,8 @@ func (h *MasterHandler) CreateLoanType(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.Code == "" || req.Name == "" {
-		return response.BadRequest(c, "Code and name are required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	loanType := &models.LoanType{
@@ -137,6 +209,16 @@ func (h *MasterHandler) CreateLoanType(c *fiber.Ctx) error {
 		InterestRate: req.InterestRate,
 		IsActive:     true,
 	}
+	if req.RequiresGuarantor != nil {
+		loanType.RequiresGuarantor = *req.RequiresGuarantor
+	}
+	if req.RequiresDualApproval != nil {
+		loanType.RequiresDualApproval = *req.RequiresDualApproval
+	}
+	applyAmountLimits(loanType, &req)
+	if !validAmountLimits(loanType) {
+		return response.BadRequest(c, "min_amount must not be greater than max_amount")
+	}
 
 	if err := h.loanTypeRepo.Create(c.Context(), loanType); err != nil {
 		return response.InternalServerError(c, "Failed to create loan type")
@@ -147,6 +229,21 @@ func (h *MasterHandler) CreateLoanType(c *fiber.Ctx) error {
 	})
 }
 
+// applyAmountLimits copies the amount limits that were sent in the request
+func applyAmountLimits(loanType *models.LoanType, req *CreateLoanTypeRequest) {
+	if req.MinAmount != nil {
+		loanType.MinAmount = *req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		loanType.MaxAmount = *req.MaxAmount
+	}
+}
+
+// validAmountLimits checks min <= max when both limits are set
+func validAmountLimits(loanType *models.LoanType) bool {
+	return loanType.MinAmount == 0 || loanType.MaxAmount == 0 || loanType.MinAmount <= loanType.MaxAmount
+}
+
 // UpdateLoanType updates a loan type
 // @Summary Update loan type
 // @Description Update a loan type (Admin only)
@@ -190,6 +287,19 @@ func (h *MasterHandler) UpdateLoanType(c *fiber.Ctx) error {
 	if req.InterestRate > 0 {
 		loanType.InterestRate = req.InterestRate
 	}
+	if req.RequiresGuarantor != nil {
+		loanType.RequiresGuarantor = *req.RequiresGuarantor
+	}
+	if req.RequiresDualApproval != nil {
+		loanType.RequiresDualApproval = *req.RequiresDualApproval
+	}
+	if (req.MinAmount != nil && *req.MinAmount < 0) || (req.MaxAmount != nil && *req.MaxAmount < 0) {
+		return response.BadRequest(c, "Amount limits must not be negative")
+	}
+	applyAmountLimits(loanType, &req)
+	if !validAmountLimits(loanType) {
+		return response.BadRequest(c, "min_amount must not be greater than max_amount")
+	}
 
 	if err := h.loanTypeRepo.Update(c.Context(), loanType); err != nil {
 		return response.InternalServerError(c, "Failed to update loan type")
@@ -294,8 +404,8 @@ func (h *MasterHandler) GetLoanStep(c *fiber.Ctx) error {
 
 // CreateLoanStepRequest represents create loan step request
 type CreateLoanStepRequest struct {
-	Code        string `json:"code"`
-	Name        string `json:"name"`
+	Code        string `json:"code" validate:"required"`
+	Name        string `json:"name" validate:"required"`
 	Description string `json:"description,omitempty"`
 	StepOrder   int    `json:"step_order"`
 	Color       string `json:"color,omitempty"`
@@ -312,6 +422,7 @@ type CreateLoanStepRequest struct {
 // @Param body body CreateLoanStepRequest true "Loan step data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /master/loan-steps [post]
@@ -321,8 +432,8 @@ func (h *MasterHandler) CreateLoanStep(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.Code == "" || req.Name == "" {
-		return response.BadRequest(c, "Code and name are required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	loanStep := &models.LoanStep{
@@ -495,9 +606,19 @@ func (h *MasterHandler) GetLoanDoc(c *fiber.Ctx) error {
 
 // CreateLoanDocRequest represents create loan doc request
 type CreateLoanDocRequest struct {
-	Code        string `json:"code"`
-	Name        string `json:"name"`
+	Code        string `json:"code" validate:"required"`
+	Name        string `json:"name" validate:"required"`
 	Description string `json:"description,omitempty"`
+	IsRequired  bool   `json:"is_required,omitempty"`
+}
+
+// UpdateLoanDocRequest represents update loan doc request
+// Empty fields are left unchanged
+type UpdateLoanDocRequest struct {
+	Code        string `json:"code,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	IsRequired  *bool  `json:"is_required,omitempty"`
 }
 
 // CreateLoanDoc creates a new loan doc
@@ -510,6 +631,7 @@ type CreateLoanDocRequest struct {
 // @Param body body CreateLoanDocRequest true "Loan doc data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /master/loan-docs [post]
@@ -519,14 +641,15 @@ func (h *MasterHandler) CreateLoanDoc(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.Code == "" || req.Name == "" {
-		return response.BadRequest(c, "Code and name are required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	loanDoc := &models.LoanDoc{
 		Code:        req.Code,
 		Name:        req.Name,
 		Description: req.Description,
+		IsRequired:  req.IsRequired,
 		IsActive:    true,
 	}
 
@@ -547,7 +670,7 @@ func (h *MasterHandler) CreateLoanDoc(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Loan Doc ID"
-// @Param body body CreateLoanDocRequest true "Loan doc data"
+// @Param body body UpdateLoanDocRequest true "Loan doc data"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
@@ -565,7 +688,7 @@ func (h *MasterHandler) UpdateLoanDoc(c *fiber.Ctx) error {
 		return response.NotFound(c, "Loan doc not found")
 	}
 
-	var req CreateLoanDocRequest
+	var req UpdateLoanDocRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
@@ -579,6 +702,9 @@ func (h *MasterHandler) UpdateLoanDoc(c *fiber.Ctx) error {
 	if req.Description != "" {
 		loanDoc.Description = req.Description
 	}
+	if req.IsRequired != nil {
+		loanDoc.IsRequired = *req.IsRequired
+	}
 
 	if err := h.loanDocRepo.Update(c.Context(), loanDoc); err != nil {
 		return response.InternalServerError(c, "Failed to update loan doc")
@@ -683,8 +809,8 @@ func (h *MasterHandler) GetLoanAppt(c *fiber.Ctx) error {
 
 // CreateLoanApptRequest represents create loan appt request
 type CreateLoanApptRequest struct {
-	Code            string `json:"code"`
-	Name            string `json:"name"`
+	Code            string `json:"code" validate:"required"`
+	Name            string `json:"name" validate:"required"`
 	Description     string `json:"description,omitempty"`
 	DefaultLocation string `json:"default_location,omitempty"`
 }
@@ -699,6 +825,7 @@ type CreateLoanApptRequest struct {
 // @Param body body CreateLoanApptRequest true "Loan appt data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /master/loan-appts [post]
@@ -708,8 +835,8 @@ func (h *MasterHandler) CreateLoanAppt(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.Code == "" || req.Name == "" {
-		return response.BadRequest(c, "Code and name are required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	loanAppt := &models.LoanAppt{
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
 	"spsc-loaneasy/internal/pkg/pagination"
@@ -18,6 +22,7 @@ type MobileHandler struct {
 	loanDocRepo     *repositories.LoanDocRepository
 	loanApptRepo    *repositories.LoanApptRepository
 	transactionRepo *repositories.TransactionRepository
+	noteRepo        *repositories.MortgageNoteRepository
 }
 
 func NewMobileHandler(
@@ -28,6 +33,7 @@ func NewMobileHandler(
 	loanDocRepo *repositories.LoanDocRepository,
 	loanApptRepo *repositories.LoanApptRepository,
 	transactionRepo *repositories.TransactionRepository,
+	noteRepo *repositories.MortgageNoteRepository,
 ) *MobileHandler {
 	return &MobileHandler{
 		db:              db,
@@ -37,6 +43,7 @@ func NewMobileHandler(
 		loanDocRepo:     loanDocRepo,
 		loanApptRepo:    loanApptRepo,
 		transactionRepo: transactionRepo,
+		noteRepo:        noteRepo,
 	}
 }
 
@@ -48,6 +55,17 @@ type MasterDataResponse struct {
 }
 
 func (h *MobileHandler) GetMasterData(c *fiber.Ctx) error {
+	// ETag จาก updated_at ล่าสุดของ master ทุกตาราง - แก้ master เมื่อไหร่ ETag เปลี่ยนเอง
+	c.Set("Cache-Control", "public, max-age=3600")
+	etag, lastModified := h.masterDataVersion()
+	if etag != "" {
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+		if match := c.Get(fiber.HeaderIfNoneMatch); match != "" && match == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
 	loanTypes, _ := h.loanTypeRepo.List(c.Context())
 	loanSteps, _ := h.loanStepRepo.List(c.Context())
 	loanDocs, _ := h.loanDocRepo.List(c.Context())
@@ -70,12 +88,37 @@ func (h *MobileHandler) GetMasterData(c *fiber.Ctx) error {
 		appts[i] = *a
 	}
 
-	c.Set("Cache-Control", "public, max-age=3600")
 	return response.Success(c, "Master data retrieved successfully", fiber.Map{
 		"master": MasterDataResponse{LoanTypes: types, LoanSteps: steps, LoanDocs: docs, LoanAppts: appts},
 	})
 }
 
+// masterDataVersion returns a weak ETag and the last modified time of the master tables
+// Row counts are part of the tag so that soft deletes (which don't touch updated_at) also change it
+func (h *MobileHandler) masterDataVersion() (string, time.Time) {
+	var latest time.Time
+	tag := ""
+	for _, table := range []string{"loan_types", "loan_steps", "loan_docs", "loan_appts"} {
+		var row struct {
+			MaxUpdated *time.Time
+			Total      int64
+		}
+		err := h.db.Table(table).
+			Where("deleted_at IS NULL").
+			Select("MAX(updated_at) AS max_updated, COUNT(*) AS total").
+			Scan(&row).Error
+		if err != nil {
+			return "", time.Time{}
+		}
+		if row.MaxUpdated != nil && row.MaxUpdated.After(latest) {
+			latest = *row.MaxUpdated
+		}
+		tag += "-" + strconv.FormatInt(row.Total, 10)
+	}
+
+	return `W/"master-` + strconv.FormatInt(latest.Unix(), 10) + tag + `"`, latest
+}
+
 type MyLoansLiteResponse struct {
 	ID           uint    `json:"id"`
 	MembNo       string  `json:"memb_no"`
@@ -95,11 +138,10 @@ func (h *MobileHandler) GetMyLoans(c *fiber.Ctx) error {
 	}
 
 	params := pagination.GetParams(c)
-	var total int64
-	h.db.Model(&models.Mortgage{}).Where("memb_no = ?", membNo).Count(&total)
-
-	var mortgages []models.Mortgage
-	h.db.Preload("LoanType").Preload("CurrentStep").Where("memb_no = ?", membNo).Order("created_at DESC").Offset(params.Offset).Limit(params.Limit).Find(&mortgages)
+	mortgages, total, err := h.mortgageRepo.GetByMembNo(c.Context(), membNo, params.Offset, params.Limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get loans")
+	}
 
 	liteLoans := make([]MyLoansLiteResponse, len(mortgages))
 	for i, m := range mortgages {
@@ -211,3 +253,288 @@ func (h *MobileHandler) GetDashboard(c *fiber.Ctx) error {
 	c.Set("Cache-Control", "private, max-age=60")
 	return response.Success(c, "Dashboard retrieved successfully", dashboard)
 }
+
+// ============================================================
+// Case Detail (single call for the mobile loan screen)
+// ============================================================
+
+type MobileLoanDetailResponse struct {
+	Loan         MobileLoanInfo       `json:"loan"`
+	Timeline     []MobileTimelineStep `json:"timeline"`
+	Docs         MobileDocChecklist   `json:"docs"`
+	Appointment  *MobileAppointment   `json:"appointment,omitempty"`
+	Appointments []MobileApptHistory  `json:"appointments"`
+	Notes        []MobileNote         `json:"notes"`
+}
+
+type MobileLoanInfo struct {
+	ID           uint    `json:"id"`
+	ContractNo   string  `json:"contract_no,omitempty"`
+	MembNo       string  `json:"memb_no"`
+	Amount       float64 `json:"amount"`
+	InterestRate float64 `json:"interest_rate"`
+	Purpose      string  `json:"purpose,omitempty"`
+	LoanTypeName string  `json:"loan_type_name"`
+	CurrentStep  string  `json:"current_step"`
+	StepColor    string  `json:"step_color"`
+	OfficerName  string  `json:"officer_name,omitempty"`
+	ApprovedAt   string  `json:"approved_at,omitempty"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+type MobileTimelineStep struct {
+	StepID    uint   `json:"step_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	StepOrder int    `json:"step_order"`
+	Status    string `json:"status"` // done / current / upcoming
+	ReachedAt string `json:"reached_at,omitempty"`
+}
+
+type MobileDocChecklist struct {
+	Items     []MobileDocItem `json:"items"`
+	Submitted int             `json:"submitted"`
+	Total     int             `json:"total"`
+	Percent   int             `json:"percent"`
+
+	// Required docs must all be submitted before approval
+	RequiredSubmitted int `json:"required_submitted"`
+	RequiredTotal     int `json:"required_total"`
+}
+
+type MobileDocItem struct {
+	DocID       uint   `json:"doc_id"`
+	Name        string `json:"name"`
+	IsRequired  bool   `json:"is_required"`
+	Submitted   bool   `json:"submitted"`
+	IsCurrent   bool   `json:"is_current"`
+	SubmittedAt string `json:"submitted_at,omitempty"`
+}
+
+type MobileAppointment struct {
+	ApptID   uint   `json:"appt_id"`
+	Name     string `json:"name"`
+	Date     string `json:"date"`
+	Time     string `json:"time,omitempty"`
+	Location string `json:"location,omitempty"`
+	Status   string `json:"status"`
+}
+
+// MobileNote is a member-visible note (internal notes are never returned)
+type MobileNote struct {
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+type MobileApptHistory struct {
+	ApptID uint   `json:"appt_id"`
+	Name   string `json:"name"`
+	Action string `json:"action"` // APPT_CREATE / APPT_COMPLETE / APPT_CANCEL
+	At     string `json:"at"`
+}
+
+// GetLoanDetail returns a mortgage with its step timeline, doc checklist and appointments
+// in one payload. Scoped to the authenticated member - other members' loans return 404
+func (h *MobileHandler) GetLoanDetail(c *fiber.Ctx) error {
+	membNo, ok := c.Locals("membNo").(string)
+	if !ok || membNo == "" {
+		return response.Unauthorized(c, "User not found in context")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	m, err := h.mortgageRepo.GetByID(c.Context(), uint(id))
+	if err != nil || m.MembNo != membNo {
+		return response.NotFound(c, "Loan not found")
+	}
+
+	txs, err := h.transactionRepo.GetByMortgageID(c.Context(), m.ID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get loan history")
+	}
+	steps, err := h.loanStepRepo.List(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get loan steps")
+	}
+	docs, err := h.loanDocRepo.List(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get loan documents")
+	}
+	submissions, err := h.transactionRepo.GetDocSubmissions(c.Context(), []uint{m.ID})
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get loan documents")
+	}
+	appts, _ := h.loanApptRepo.ListAll(c.Context())
+
+	detail := MobileLoanDetailResponse{
+		Loan:         mobileLoanInfo(m),
+		Timeline:     mobileTimeline(m, steps, txs),
+		Docs:         mobileDocChecklist(m, docs, submissions[m.ID]),
+		Appointments: []MobileApptHistory{},
+		Notes:        []MobileNote{},
+	}
+
+	notes, _ := h.noteRepo.ListByMortgageID(c.Context(), m.ID, false)
+	for _, n := range notes {
+		detail.Notes = append(detail.Notes, MobileNote{
+			Body:      n.Body,
+			CreatedAt: n.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	apptNames := make(map[uint]string, len(appts))
+	for _, a := range appts {
+		apptNames[a.ID] = a.Name
+	}
+
+	// txs เรียงใหม่สุดก่อน - รายการแรกที่เป็น APPT_* คือสถานะล่าสุดของนัดหมาย
+	apptStatus := models.ApptStatusPending
+	statusFound := false
+	for _, tx := range txs {
+		if tx.ToApptID == nil {
+			continue
+		}
+		switch tx.TransactionType {
+		case models.TxTypeApptCreate, models.TxTypeApptComplete, models.TxTypeApptCancel:
+		default:
+			continue
+		}
+		if !statusFound {
+			statusFound = true
+			switch tx.TransactionType {
+			case models.TxTypeApptComplete:
+				apptStatus = models.ApptStatusCompleted
+			case models.TxTypeApptCancel:
+				apptStatus = models.ApptStatusCancelled
+			}
+		}
+		detail.Appointments = append(detail.Appointments, MobileApptHistory{
+			ApptID: *tx.ToApptID,
+			Name:   apptNames[*tx.ToApptID],
+			Action: tx.TransactionType,
+			At:     tx.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	if m.CurrentApptID != nil && m.ApptDate != nil {
+		detail.Appointment = &MobileAppointment{
+			ApptID:   *m.CurrentApptID,
+			Date:     m.ApptDate.Format("2006-01-02"),
+			Time:     m.ApptTime,
+			Location: m.ApptLocation,
+			Status:   apptStatus,
+		}
+		if m.CurrentAppt != nil {
+			detail.Appointment.Name = m.CurrentAppt.Name
+		}
+	}
+
+	c.Set("Cache-Control", "private, max-age=30")
+	return response.Success(c, "Loan detail retrieved successfully", detail)
+}
+
+func mobileLoanInfo(m *models.Mortgage) MobileLoanInfo {
+	info := MobileLoanInfo{
+		ID:           m.ID,
+		MembNo:       m.MembNo,
+		Amount:       m.Amount,
+		InterestRate: m.InterestRate,
+		Purpose:      m.Purpose,
+		CreatedAt:    m.CreatedAt.Format("2006-01-02"),
+		UpdatedAt:    m.UpdatedAt.Format("2006-01-02 15:04"),
+	}
+	if m.ContractNo != nil {
+		info.ContractNo = *m.ContractNo
+	}
+	if m.LoanType != nil {
+		info.LoanTypeName = m.LoanType.Name
+	}
+	if m.CurrentStep != nil {
+		info.CurrentStep = m.CurrentStep.Name
+		info.StepColor = m.CurrentStep.Color
+	}
+	if m.Officer != nil {
+		info.OfficerName = m.Officer.Username
+	}
+	if m.ApprovedAt != nil {
+		info.ApprovedAt = m.ApprovedAt.Format("2006-01-02")
+	}
+	return info
+}
+
+// mobileTimeline marks steps before the current one as done
+// reached_at มาจาก STATUS_CHANGE ล่าสุดที่เข้า step นั้น (step แรกใช้วันที่สร้าง)
+func mobileTimeline(m *models.Mortgage, steps []*models.LoanStep, txs []*models.Transaction) []MobileTimelineStep {
+	reachedAt := make(map[uint]string)
+	for _, tx := range txs {
+		if tx.ToStepID == nil {
+			continue
+		}
+		if _, ok := reachedAt[*tx.ToStepID]; !ok {
+			reachedAt[*tx.ToStepID] = tx.CreatedAt.Format("2006-01-02 15:04")
+		}
+	}
+
+	currentOrder := 0
+	if m.CurrentStep != nil {
+		currentOrder = m.CurrentStep.StepOrder
+	}
+
+	timeline := make([]MobileTimelineStep, 0, len(steps))
+	for i, step := range steps {
+		item := MobileTimelineStep{
+			StepID:    step.ID,
+			Name:      step.Name,
+			Color:     step.Color,
+			StepOrder: step.StepOrder,
+			Status:    "upcoming",
+			ReachedAt: reachedAt[step.ID],
+		}
+		switch {
+		case step.ID == m.CurrentStepID:
+			item.Status = "current"
+		case step.StepOrder < currentOrder:
+			item.Status = "done"
+		}
+		if i == 0 && item.ReachedAt == "" && item.Status != "upcoming" {
+			item.ReachedAt = m.CreatedAt.Format("2006-01-02 15:04")
+		}
+		timeline = append(timeline, item)
+	}
+	return timeline
+}
+
+// mobileDocChecklist marks docs submitted from the case's current submission state
+// (latest DOC_CHECK / DOC_UNCHECK per doc, see TransactionRepository.GetDocSubmissions)
+func mobileDocChecklist(m *models.Mortgage, docs []*models.LoanDoc, submitted map[uint]time.Time) MobileDocChecklist {
+	checklist := MobileDocChecklist{Items: make([]MobileDocItem, 0, len(docs)), Total: len(docs)}
+	for _, doc := range docs {
+		at, isSubmitted := submitted[doc.ID]
+		item := MobileDocItem{
+			DocID:      doc.ID,
+			Name:       doc.Name,
+			IsRequired: doc.IsRequired,
+			Submitted:  isSubmitted,
+			IsCurrent:  m.CurrentDocID != nil && *m.CurrentDocID == doc.ID,
+		}
+		if isSubmitted {
+			item.SubmittedAt = at.Format("2006-01-02 15:04")
+			checklist.Submitted++
+		}
+		checklist.Items = append(checklist.Items, item)
+		if doc.IsRequired {
+			checklist.RequiredTotal++
+			if isSubmitted {
+				checklist.RequiredSubmitted++
+			}
+		}
+	}
+	if checklist.Total > 0 {
+		checklist.Percent = checklist.Submitted * 100 / checklist.Total
+	}
+	return checklist
+}
@@ -2,10 +2,20 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/config"
 	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/i18n"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/money"
+	"spsc-loaneasy/internal/pkg/pagination"
 	"spsc-loaneasy/internal/pkg/response"
+	"spsc-loaneasy/internal/pkg/timeutil"
+	"spsc-loaneasy/internal/pkg/upload"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,15 +23,32 @@ import (
 // MortgageHandler handles mortgage endpoints
 type MortgageHandler struct {
 	mortgageService *services.MortgageService
+	pdfService      *services.PDFService
+	uploadRules     upload.Rules
+	scanner         upload.FileScanner
 }
 
 // NewMortgageHandler creates a new mortgage handler
-func NewMortgageHandler(mortgageService *services.MortgageService) *MortgageHandler {
+func NewMortgageHandler(mortgageService *services.MortgageService, pdfService *services.PDFService, cfg *config.Config) *MortgageHandler {
 	return &MortgageHandler{
 		mortgageService: mortgageService,
+		pdfService:      pdfService,
+		uploadRules: upload.Rules{
+			MaxBytes:     cfg.Upload.MaxBytes,
+			AllowedTypes: cfg.Upload.AllowedTypes,
+		},
+		scanner: upload.NoopScanner{},
 	}
 }
 
+// SetFileScanner sets the scanner every uploaded file must pass before it is stored
+func (h *MortgageHandler) SetFileScanner(scanner upload.FileScanner) {
+	h.scanner = scanner
+}
+
+// maxFilesPerUpload limits the number of files in one upload request
+const maxFilesPerUpload = 10
+
 // getClientIP gets client IP address
 func getClientIP(c *fiber.Ctx) string {
 	ip := c.Get("X-Real-IP")
@@ -34,15 +61,30 @@ func getClientIP(c *fiber.Ctx) string {
 	return ip
 }
 
+// mortgageResponse converts a mortgage for output
+// With ?amount_format=thb the amounts are also sent as formatted strings (e.g. ฿1,250,000.00)
+func mortgageResponse(c *fiber.Ctx, m *models.Mortgage) *models.MortgageResponse {
+	resp := m.ToResponse()
+	if strings.EqualFold(c.Query("amount_format"), "thb") {
+		resp.AmountText = money.FormatTHB(resp.Amount)
+		if resp.ApprovedAmount != nil {
+			resp.ApprovedAmountText = money.FormatTHB(*resp.ApprovedAmount)
+		}
+	}
+	return resp
+}
+
 // CreateRequest represents create mortgage request
 type CreateMortgageRequest struct {
-	MembNo          string  `json:"memb_no"`
-	LoanTypeID      uint    `json:"loan_type_id"`
-	Amount          float64 `json:"amount"`
+	MembNo          string  `json:"memb_no" validate:"required"`
+	LoanTypeID      uint    `json:"loan_type_id" validate:"required"`
+	Amount          float64 `json:"amount" validate:"required,gt=0"`
 	Collateral      string  `json:"collateral,omitempty"`
 	Purpose         string  `json:"purpose,omitempty"`
 	GuarantorMembNo string  `json:"guarantor_memb_no,omitempty"`
 	Remark          string  `json:"remark,omitempty"`
+	OfficerID       uint    `json:"officer_id,omitempty"` // ผู้รับผิดชอบ (ไม่ส่ง = ผู้สร้าง)
+	Unassigned      bool    `json:"unassigned,omitempty"` // true = เข้าคิวรอมอบหมาย (ไม่สน officer_id)
 }
 
 // Create creates a new mortgage
@@ -55,6 +97,7 @@ type CreateMortgageRequest struct {
 // @Param body body CreateMortgageRequest true "Mortgage data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /mortgages [post]
@@ -65,14 +108,8 @@ func (h *MortgageHandler) Create(c *fiber.Ctx) error {
 	}
 
 	// Validate required fields
-	if req.MembNo == "" {
-		return response.BadRequest(c, "Member number is required")
-	}
-	if req.LoanTypeID == 0 {
-		return response.BadRequest(c, "Loan type is required")
-	}
-	if req.Amount <= 0 {
-		return response.BadRequest(c, "Amount must be greater than 0")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
@@ -86,37 +123,80 @@ func (h *MortgageHandler) Create(c *fiber.Ctx) error {
 		Purpose:         req.Purpose,
 		GuarantorMembNo: req.GuarantorMembNo,
 		Remark:          req.Remark,
+		OfficerID:       req.OfficerID,
+		Unassigned:      req.Unassigned,
 	}
 
 	mortgage, err := h.mortgageService.Create(c.Context(), input, userID, ipAddress)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrMemberNotFoundMortgage):
-			return response.NotFound(c, "Member not found")
-		case errors.Is(err, services.ErrLoanTypeNotFound):
-			return response.NotFound(c, "Loan type not found")
-		default:
-			return response.InternalServerError(c, "Failed to create mortgage")
+		var limit *services.AmountLimitError
+		if errors.As(err, &limit) {
+			return response.ErrorWithData(c, fiber.StatusBadRequest, "AMOUNT_OUT_OF_RANGE", limit.Error(), fiber.Map{
+				"min_amount": limit.MinAmount,
+				"max_amount": limit.MaxAmount,
+			})
 		}
+		return mortgageError(c, err, "Failed to create mortgage")
 	}
 
 	return response.Created(c, "Mortgage created successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
+	})
+}
+
+// Clone creates a new draft from a finished case
+// @Summary Clone mortgage
+// @Description Create a new mortgage at the first step copying member, loan type, amount, collateral, purpose and guarantor from a case in a final step (Officer only)
+// @Tags Mortgages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Source mortgage ID"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /mortgages/{id}/clone [post]
+func (h *MortgageHandler) Clone(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	ipAddress := getClientIP(c)
+
+	mortgage, err := h.mortgageService.Clone(c.Context(), uint(id), userID, ipAddress)
+	if err != nil {
+		var limit *services.AmountLimitError
+		if errors.As(err, &limit) {
+			return response.ErrorWithData(c, fiber.StatusBadRequest, "AMOUNT_OUT_OF_RANGE", limit.Error(), fiber.Map{
+				"min_amount": limit.MinAmount,
+				"max_amount": limit.MaxAmount,
+			})
+		}
+		return mortgageError(c, err, "Failed to clone mortgage")
+	}
+
+	return response.Created(c, "Mortgage cloned successfully", fiber.Map{
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
 
 // List lists mortgages
 // @Summary List mortgages
-// @Description List all mortgages (Officer only)
+// @Description List mortgages. Officers only see mortgages assigned to them; admins see all and may filter by officer_id
 // @Tags Mortgages
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
-// @Param officer_id query int false "Filter by officer ID"
+// @Param officer_id query int false "Filter by officer ID (Admin only, ignored for officers)"
 // @Param step_id query int false "Filter by step ID"
+// @Param approved_from query string false "Approved on or after (YYYY-MM-DD)"
+// @Param approved_to query string false "Approved on or before (YYYY-MM-DD)"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Router /mortgages [get]
@@ -125,11 +205,18 @@ func (h *MortgageHandler) List(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
 	input := &services.ListInput{
-		Page:  page,
-		Limit: limit,
+		Page:         page,
+		Limit:        limit,
+		ApprovedFrom: c.Query("approved_from"),
+		ApprovedTo:   c.Query("approved_to"),
 	}
 
-	if officerID := c.Query("officer_id"); officerID != "" {
+	// OFFICER เห็นเฉพาะเคสของตัวเอง (ไม่สน officer_id ที่ส่งมา), ADMIN เห็นทั้งหมด
+	role, _ := c.Locals("role").(string)
+	if role == "OFFICER" {
+		userID, _ := c.Locals("userID").(uint)
+		input.OfficerID = &userID
+	} else if officerID := c.Query("officer_id"); officerID != "" {
 		id, _ := strconv.ParseUint(officerID, 10, 32)
 		uid := uint(id)
 		input.OfficerID = &uid
@@ -143,7 +230,7 @@ func (h *MortgageHandler) List(c *fiber.Ctx) error {
 
 	result, err := h.mortgageService.List(c.Context(), input)
 	if err != nil {
-		return response.InternalServerError(c, "Failed to list mortgages")
+		return mortgageError(c, err, "Failed to list mortgages")
 	}
 
 	return response.Success(c, "Mortgages retrieved successfully", result)
@@ -157,6 +244,7 @@ func (h *MortgageHandler) List(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Mortgage ID"
+// @Param amount_format query string false "thb = also return formatted amount_text / approved_amount_text"
 // @Success 200 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
@@ -170,24 +258,66 @@ func (h *MortgageHandler) GetByID(c *fiber.Ctx) error {
 
 	mortgage, err := h.mortgageService.GetByID(c.Context(), uint(id))
 	if err != nil {
-		if errors.Is(err, services.ErrMortgageNotFound) {
-			return response.NotFound(c, "Mortgage not found")
-		}
-		return response.InternalServerError(c, "Failed to get mortgage")
+		return mortgageError(c, err, "Failed to get mortgage")
 	}
 
 	return response.Success(c, "Mortgage retrieved successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
 
+// GetPDF renders a printable mortgage summary
+// @Summary Get mortgage summary PDF
+// @Description Download a one-page PDF summary of the mortgage. Officer/Admin can get any, members only their own
+// @Tags Mortgages
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Success 200 {file} file
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /mortgages/{id}/pdf [get]
+func (h *MortgageHandler) GetPDF(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	mortgage, err := h.mortgageService.GetByID(c.Context(), uint(id))
+	if err != nil {
+		return mortgageError(c, err, "Failed to get mortgage")
+	}
+
+	// สมาชิกดูได้เฉพาะของตัวเอง (ตอบ 404 ไม่บอกว่ามีเคสนี้อยู่)
+	role, _ := c.Locals("role").(string)
+	if role != "OFFICER" && role != "ADMIN" {
+		membNo, _ := c.Locals("membNo").(string)
+		if membNo == "" || mortgage.MembNo != membNo {
+			return response.NotFound(c, "Mortgage not found")
+		}
+	}
+
+	pdf, err := h.pdfService.MortgageSummary(c.Context(), mortgage)
+	if err != nil {
+		return mortgageError(c, err, "Failed to generate PDF")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Attachment(fmt.Sprintf("mortgage-%d.pdf", mortgage.ID))
+	return c.Send(pdf)
+}
+
 // GetMyMortgages gets member's own mortgages
 // @Summary Get my mortgages
-// @Description Get current user's mortgages
+// @Description Get current user's mortgages, newest first
 // @Tags Mortgages
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Param amount_format query string false "thb = also return formatted amount_text / approved_amount_text"
 // @Success 200 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Router /mortgages/my [get]
@@ -197,7 +327,8 @@ func (h *MortgageHandler) GetMyMortgages(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Unauthorized")
 	}
 
-	mortgages, err := h.mortgageService.GetByMembNo(c.Context(), membNo)
+	params := pagination.GetParams(c)
+	mortgages, total, err := h.mortgageService.GetByMembNo(c.Context(), membNo, params.Offset, params.Limit)
 	if err != nil {
 		return response.InternalServerError(c, "Failed to get mortgages")
 	}
@@ -206,7 +337,7 @@ func (h *MortgageHandler) GetMyMortgages(c *fiber.Ctx) error {
 	// Convert to response using ToResponse()
 	var result []interface{}
 	for _, m := range mortgages {
-		result = append(result, m.ToResponse())
+		result = append(result, mortgageResponse(c, m))
 	}
 
 	// Return empty array if no mortgages
@@ -214,13 +345,48 @@ func (h *MortgageHandler) GetMyMortgages(c *fiber.Ctx) error {
 		result = []interface{}{}
 	}
 
-	return response.Success(c, "Mortgages retrieved successfully", result)
+	return response.Success(c, "Mortgages retrieved successfully", fiber.Map{
+		"mortgages": result,
+		"meta":      pagination.GetMeta(params, total),
+	})
+}
+
+// GetMyActivity gets the member's activity feed
+// @Summary Get my activity
+// @Description Get one time-ordered feed of what happened across all of the current user's mortgages (submitted, documents received, appointments, status changes, approval), newest first. Descriptions follow ?lang= / Accept-Language (th/en)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Param lang query string false "Language (th/en)"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /mortgages/my/activity [get]
+func (h *MortgageHandler) GetMyActivity(c *fiber.Ctx) error {
+	membNo, ok := c.Locals("membNo").(string)
+	if !ok || membNo == "" {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	params := pagination.GetParams(c)
+	items, total, err := h.mortgageService.GetActivity(c.Context(), membNo, i18n.FromRequest(c), params.Offset, params.Limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get activity")
+	}
+
+	return response.Success(c, "Activity retrieved successfully", fiber.Map{
+		"activity": items,
+		"meta":     pagination.GetMeta(params, total),
+	})
 }
 
 // ChangeStepRequest represents change step request
 type ChangeStepRequest struct {
-	StepID uint   `json:"step_id"`
-	Remark string `json:"remark,omitempty"`
+	StepID  uint   `json:"step_id" validate:"required"`
+	Remark  string `json:"remark,omitempty"`
+	Version uint   `json:"version,omitempty"`
 }
 
 // ChangeStep changes mortgage step
@@ -232,11 +398,14 @@ type ChangeStepRequest struct {
 // @Security BearerAuth
 // @Param id path int true "Mortgage ID"
 // @Param body body ChangeStepRequest true "Step data"
+// @Param preview query bool false "Validate and return the resulting step and notification texts without saving or sending"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/step [put]
 func (h *MortgageHandler) ChangeStep(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -249,44 +418,50 @@ func (h *MortgageHandler) ChangeStep(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.StepID == 0 {
-		return response.BadRequest(c, "Step ID is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
 	ipAddress := getClientIP(c)
 
 	input := &services.ChangeStepInput{
-		StepID: req.StepID,
-		Remark: req.Remark,
+		StepID:  req.StepID,
+		Remark:  req.Remark,
+		Version: req.Version,
+	}
+
+	if c.QueryBool("preview") {
+		preview, err := h.mortgageService.PreviewChangeStep(c.Context(), uint(id), input)
+		if err != nil {
+			return mortgageError(c, err, "Failed to preview step change")
+		}
+		return response.Success(c, "Step change preview (nothing was saved)", preview)
 	}
 
 	mortgage, err := h.mortgageService.ChangeStep(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrMortgageNotFound):
-			return response.NotFound(c, "Mortgage not found")
-		case errors.Is(err, services.ErrLoanStepNotFound):
-			return response.NotFound(c, "Step not found")
-		default:
-			return response.InternalServerError(c, "Failed to change step")
-		}
+		return mortgageError(c, err, "Failed to change step")
 	}
 
 	return response.Success(c, "Step changed successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
 
 // ApproveRequest represents approve request
 type ApproveRequest struct {
-	ContractNo string `json:"contract_no"`
-	Remark     string `json:"remark,omitempty"`
+	ContractNo   string `json:"contract_no" validate:"required"`
+	Remark       string `json:"remark,omitempty"`
+	Version      uint   `json:"version,omitempty"`
+	OverrideDocs bool   `json:"override_docs,omitempty"` // อนุมัติแม้เอกสารบังคับยังไม่ครบ
+
+	ApprovedAmount *float64 `json:"approved_amount,omitempty"` // อนุมัติบางส่วน (ไม่ส่ง = เต็มจำนวน)
 }
 
 // Approve approves a mortgage
 // @Summary Approve mortgage
-// @Description Approve a mortgage (Officer only)
+// @Description Approve a mortgage (Officer only). Send approved_amount to approve less than the requested amount. Amounts above the approver's approval limit return 403 EXCEEDS_APPROVAL_LIMIT and need an admin. Loan types flagged requires_dual_approval and amounts at or above DUAL_APPROVAL_THRESHOLD only move to PENDING_FINAL; a different user then confirms via /mortgages/{id}/approve/confirm
 // @Tags Mortgages
 // @Accept json
 // @Produce json
@@ -295,9 +470,11 @@ type ApproveRequest struct {
 // @Param body body ApproveRequest true "Approve data"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/approve [put]
 func (h *MortgageHandler) Approve(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -310,38 +487,109 @@ func (h *MortgageHandler) Approve(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.ContractNo == "" {
-		return response.BadRequest(c, "Contract number is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
 	ipAddress := getClientIP(c)
 
 	input := &services.ApproveInput{
-		ContractNo: req.ContractNo,
-		Remark:     req.Remark,
+		ContractNo:   req.ContractNo,
+		Remark:       req.Remark,
+		Version:      req.Version,
+		OverrideDocs: req.OverrideDocs,
+
+		ApprovedAmount: req.ApprovedAmount,
 	}
 
 	mortgage, err := h.mortgageService.Approve(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrMortgageNotFound):
-			return response.NotFound(c, "Mortgage not found")
-		case errors.Is(err, services.ErrAlreadyApproved):
-			return response.BadRequest(c, "Mortgage already approved")
-		default:
-			return response.InternalServerError(c, "Failed to approve mortgage")
+		var missing *services.MissingDocsError
+		if errors.As(err, &missing) {
+			return response.ErrorWithData(c, fiber.StatusConflict, "DOCS_OUTSTANDING",
+				"Required documents are not submitted", fiber.Map{"missing_docs": missing.Docs})
 		}
+		var overLimit *services.ApprovalLimitError
+		if errors.As(err, &overLimit) {
+			return response.ErrorWithData(c, fiber.StatusForbidden, "EXCEEDS_APPROVAL_LIMIT",
+				"Amount exceeds your approval limit, an admin must approve",
+				fiber.Map{"approval_limit": overLimit.Limit, "amount": overLimit.Amount})
+		}
+		return mortgageError(c, err, "Failed to approve mortgage")
+	}
+
+	message := "Mortgage approved successfully"
+	if mortgage.ApprovedAt == nil {
+		message = "Mortgage recommended for approval, waiting for a second approver"
+	}
+	return response.Success(c, message, fiber.Map{
+		"mortgage": mortgageResponse(c, mortgage),
+	})
+}
+
+// ConfirmApprovalRequest represents the second approver's confirmation
+type ConfirmApprovalRequest struct {
+	Remark  string `json:"remark,omitempty"`
+	Version uint   `json:"version,omitempty"`
+}
+
+// ConfirmApproval confirms a recommended approval
+// @Summary Confirm mortgage approval
+// @Description Confirm a mortgage waiting in PENDING_FINAL (Officer only). The confirming user must differ from the recommending user and is held to their own approval limit
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param body body ConfirmApprovalRequest false "Confirm data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /mortgages/{id}/approve/confirm [put]
+func (h *MortgageHandler) ConfirmApproval(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	var req ConfirmApprovalRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+
+	input := &services.ConfirmApprovalInput{
+		Remark:  req.Remark,
+		Version: req.Version,
+	}
+
+	mortgage, err := h.mortgageService.ConfirmApproval(c.Context(), uint(id), input, userID, getClientIP(c))
+	if err != nil {
+		var overLimit *services.ApprovalLimitError
+		if errors.As(err, &overLimit) {
+			return response.ErrorWithData(c, fiber.StatusForbidden, "EXCEEDS_APPROVAL_LIMIT",
+				"Amount exceeds your approval limit, an admin must approve",
+				fiber.Map{"approval_limit": overLimit.Limit, "amount": overLimit.Amount})
+		}
+		return mortgageError(c, err, "Failed to confirm approval")
 	}
 
 	return response.Success(c, "Mortgage approved successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
 
 // RejectRequest represents reject request
 type RejectRequest struct {
-	Remark string `json:"remark"`
+	Remark  string `json:"remark" validate:"required"`
+	Version uint   `json:"version,omitempty"`
 }
 
 // Reject rejects a mortgage
@@ -355,9 +603,11 @@ type RejectRequest struct {
 // @Param body body RejectRequest true "Reject data"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/reject [put]
 func (h *MortgageHandler) Reject(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -370,39 +620,39 @@ func (h *MortgageHandler) Reject(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.Remark == "" {
-		return response.BadRequest(c, "Reason is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
 	ipAddress := getClientIP(c)
 
 	input := &services.RejectInput{
-		Remark: req.Remark,
+		Remark:  req.Remark,
+		Version: req.Version,
 	}
 
 	mortgage, err := h.mortgageService.Reject(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		if errors.Is(err, services.ErrMortgageNotFound) {
-			return response.NotFound(c, "Mortgage not found")
-		}
-		return response.InternalServerError(c, "Failed to reject mortgage")
+		return mortgageError(c, err, "Failed to reject mortgage")
 	}
 
 	return response.Success(c, "Mortgage rejected successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
 
 // GetHistory gets mortgage history
 // @Summary Get mortgage history
-// @Description Get mortgage transaction history
+// @Description Get mortgage transaction history, newest first. transaction_type filters by a comma separated list of types (e.g. STATUS_CHANGE,DOC_CHECK); unknown types return 400 INVALID_TRANSACTION_TYPE
 // @Tags Mortgages
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Mortgage ID"
+// @Param transaction_type query string false "Comma separated transaction types"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Router /mortgages/{id}/history [get]
@@ -412,12 +662,16 @@ func (h *MortgageHandler) GetHistory(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid mortgage ID")
 	}
 
-	transactions, err := h.mortgageService.GetHistory(c.Context(), uint(id))
-	if err != nil {
-		if errors.Is(err, services.ErrMortgageNotFound) {
-			return response.NotFound(c, "Mortgage not found")
+	var types []string
+	for _, t := range strings.Split(c.Query("transaction_type"), ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
 		}
-		return response.InternalServerError(c, "Failed to get history")
+	}
+
+	transactions, err := h.mortgageService.GetHistory(c.Context(), uint(id), types)
+	if err != nil {
+		return mortgageError(c, err, "Failed to get history")
 	}
 
 	return response.Success(c, "History retrieved successfully", fiber.Map{
@@ -425,6 +679,85 @@ func (h *MortgageHandler) GetHistory(c *fiber.Ctx) error {
 	})
 }
 
+// AddNoteRequest represents add note request
+type AddNoteRequest struct {
+	Body       string `json:"body" validate:"required,max=5000"`
+	IsInternal *bool  `json:"is_internal,omitempty"` // default true (ไม่แสดงให้สมาชิกเห็น)
+}
+
+// ListNotes lists mortgage notes
+// @Summary List mortgage notes
+// @Description List notes on a mortgage, including internal notes (Officer only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /mortgages/{id}/notes [get]
+func (h *MortgageHandler) ListNotes(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	notes, err := h.mortgageService.ListNotes(c.Context(), uint(id), true)
+	if err != nil {
+		return mortgageError(c, err, "Failed to get notes")
+	}
+
+	return response.Success(c, "Notes retrieved successfully", fiber.Map{
+		"notes": notes,
+	})
+}
+
+// AddNote adds a note to a mortgage
+// @Summary Add mortgage note
+// @Description Add a note to a mortgage. Notes are internal by default; set is_internal=false to show it to the member (Officer only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param body body AddNoteRequest true "Note"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Router /mortgages/{id}/notes [post]
+func (h *MortgageHandler) AddNote(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	var req AddNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+
+	note, err := h.mortgageService.AddNote(c.Context(), uint(id), &services.AddNoteInput{
+		Body:       req.Body,
+		IsInternal: req.IsInternal,
+	}, userID)
+	if err != nil {
+		return mortgageError(c, err, "Failed to add note")
+	}
+
+	return response.Created(c, "Note added successfully", fiber.Map{
+		"note": note,
+	})
+}
+
 // GetDocs gets mortgage documents
 // @Summary Get mortgage documents
 // @Description Get mortgage document checklist
@@ -453,11 +786,41 @@ func (h *MortgageHandler) GetDocs(c *fiber.Ctx) error {
 	})
 }
 
+// GetOutstandingDocs lists required documents not yet submitted
+// @Summary Get outstanding documents
+// @Description List required documents that still block approval of a mortgage
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /mortgages/{id}/docs/outstanding [get]
+func (h *MortgageHandler) GetOutstandingDocs(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	docs, err := h.mortgageService.OutstandingDocs(c.Context(), uint(id))
+	if err != nil {
+		return mortgageError(c, err, "Failed to get outstanding documents")
+	}
+
+	return response.Success(c, "Outstanding documents retrieved successfully", fiber.Map{
+		"missing_docs":     docs,
+		"ready_to_approve": len(docs) == 0,
+	})
+}
+
 // UpdateDocRequest represents update doc request
 type UpdateDocRequest struct {
-	DocID       uint   `json:"doc_id"`
+	DocID       uint   `json:"doc_id" validate:"required"`
 	IsSubmitted bool   `json:"is_submitted"`
 	Remark      string `json:"remark,omitempty"`
+	Version     uint   `json:"version,omitempty"`
 }
 
 // UpdateDoc updates document status
@@ -471,9 +834,11 @@ type UpdateDocRequest struct {
 // @Param body body UpdateDocRequest true "Document data"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/docs [put]
 func (h *MortgageHandler) UpdateDoc(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -486,8 +851,8 @@ func (h *MortgageHandler) UpdateDoc(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.DocID == 0 {
-		return response.BadRequest(c, "Document ID is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
@@ -497,14 +862,12 @@ func (h *MortgageHandler) UpdateDoc(c *fiber.Ctx) error {
 		DocID:       req.DocID,
 		IsSubmitted: req.IsSubmitted,
 		Remark:      req.Remark,
+		Version:     req.Version,
 	}
 
 	err = h.mortgageService.UpdateDoc(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		if errors.Is(err, services.ErrLoanDocNotFound) {
-			return response.NotFound(c, "Document not found")
-		}
-		return response.InternalServerError(c, "Failed to update document")
+		return mortgageError(c, err, "Failed to update document")
 	}
 
 	return response.Success(c, "Document updated successfully", nil)
@@ -512,11 +875,12 @@ func (h *MortgageHandler) UpdateDoc(c *fiber.Ctx) error {
 
 // CreateApptRequest represents create appointment request
 type CreateApptRequest struct {
-	LoanApptID uint   `json:"loan_appt_id"`
-	ApptDate   string `json:"appt_date"`
-	ApptTime   string `json:"appt_time,omitempty"`
+	LoanApptID uint   `json:"loan_appt_id" validate:"required"`
+	ApptDate   string `json:"appt_date" validate:"required,datetime=2006-01-02"`
+	ApptTime   string `json:"appt_time,omitempty"` // 24h HH:MM ("9:30" is stored as "09:30")
 	Location   string `json:"location,omitempty"`
 	Remark     string `json:"remark,omitempty"`
+	Version    uint   `json:"version,omitempty"`
 }
 
 // CreateAppt creates an appointment
@@ -530,9 +894,11 @@ type CreateApptRequest struct {
 // @Param body body CreateApptRequest true "Appointment data"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/appts [post]
 func (h *MortgageHandler) CreateAppt(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -545,11 +911,8 @@ func (h *MortgageHandler) CreateAppt(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.LoanApptID == 0 {
-		return response.BadRequest(c, "Appointment type is required")
-	}
-	if req.ApptDate == "" {
-		return response.BadRequest(c, "Appointment date is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
@@ -561,18 +924,12 @@ func (h *MortgageHandler) CreateAppt(c *fiber.Ctx) error {
 		ApptTime:   req.ApptTime,
 		Location:   req.Location,
 		Remark:     req.Remark,
+		Version:    req.Version,
 	}
 
 	appt, err := h.mortgageService.CreateAppt(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrMortgageNotFound):
-			return response.NotFound(c, "Mortgage not found")
-		case errors.Is(err, services.ErrLoanApptNotFound):
-			return response.NotFound(c, "Appointment type not found")
-		default:
-			return response.InternalServerError(c, "Failed to create appointment")
-		}
+		return mortgageError(c, err, "Failed to create appointment")
 	}
 
 	return response.Created(c, "Appointment created successfully", fiber.Map{
@@ -608,6 +965,53 @@ func (h *MortgageHandler) GetAppts(c *fiber.Ctx) error {
 	})
 }
 
+// ListApptsByDate lists appointments across mortgages for a date
+// @Summary List appointments by date
+// @Description List all appointments for a date (Officer sees own, Admin can filter by officer)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Date (YYYY-MM-DD), default today"
+// @Param officer_id query int false "Filter by officer ID (Admin only)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/appointments [get]
+func (h *MortgageHandler) ListApptsByDate(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(string)
+
+	input := &services.ListApptsByDateInput{
+		Date: c.Query("date", timeutil.Today()),
+	}
+
+	if role == "ADMIN" {
+		if officerID := c.Query("officer_id"); officerID != "" {
+			id, err := strconv.ParseUint(officerID, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid officer ID")
+			}
+			uid := uint(id)
+			input.OfficerID = &uid
+		}
+	} else {
+		// Officers only see their own appointments
+		input.OfficerID = &userID
+	}
+
+	appts, err := h.mortgageService.ListApptsByDate(c.Context(), input)
+	if err != nil {
+		return mortgageError(c, err, "Failed to get appointments")
+	}
+
+	return response.Success(c, "Appointments retrieved successfully", fiber.Map{
+		"date":         input.Date,
+		"appointments": appts,
+	})
+}
+
 // CompleteAppt completes an appointment
 // @Summary Complete appointment
 // @Description Mark appointment as completed (Officer only)
@@ -638,19 +1042,319 @@ func (h *MortgageHandler) CompleteAppt(c *fiber.Ctx) error {
 
 	err = h.mortgageService.CompleteAppt(c.Context(), uint(id), uint(apptID), userID, ipAddress)
 	if err != nil {
-		if errors.Is(err, services.ErrApptNotFound) {
-			return response.NotFound(c, "Appointment not found")
-		}
-		return response.InternalServerError(c, "Failed to complete appointment")
+		return mortgageError(c, err, "Failed to complete appointment")
 	}
 
 	return response.Success(c, "Appointment completed successfully", nil)
 }
 
+// CompleteApptBatchRequest represents batch appointment completion
+type CompleteApptBatchRequest struct {
+	Items []services.ApptCompleteItem `json:"items" validate:"required,min=1,max=50,dive"`
+}
+
+// ApptBatchItemResult is the per-item outcome of a batch completion
+type ApptBatchItemResult struct {
+	MortgageID uint   `json:"mortgage_id"`
+	ApptID     uint   `json:"appt_id"`
+	Success    bool   `json:"success"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// CompleteApptBatch completes several appointments
+// @Summary Complete appointments (batch)
+// @Description Mark up to 50 appointments as completed in one call. Each item is completed on its own and reported with success or an error code (APPT_NOT_FOUND, NOT_AUTHORIZED, APPT_ALREADY_COMPLETED, ...). Officers may only complete appointments of cases assigned to them (Officer only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CompleteApptBatchRequest true "Appointments to complete"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/appts/complete-batch [post]
+func (h *MortgageHandler) CompleteApptBatch(c *fiber.Ctx) error {
+	var req CompleteApptBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(string)
+
+	results, err := h.mortgageService.CompleteApptBatch(c.Context(), req.Items, userID, role == "ADMIN", getClientIP(c))
+	if err != nil {
+		return mortgageError(c, err, "Failed to complete appointments")
+	}
+
+	items := make([]ApptBatchItemResult, len(results))
+	completed := 0
+	for i, r := range results {
+		items[i] = ApptBatchItemResult{MortgageID: r.MortgageID, ApptID: r.ApptID, Success: r.Err == nil}
+		if r.Err == nil {
+			completed++
+			continue
+		}
+		if ec, ok := lookupErrorCode(r.Err, mortgageErrorCodes); ok {
+			items[i].Code, items[i].Message = ec.Code, ec.Message
+		} else {
+			items[i].Code, items[i].Message = "INTERNAL_ERROR", "Failed to complete appointment"
+		}
+	}
+
+	return response.Success(c, "Appointments processed", fiber.Map{
+		"items":     items,
+		"completed": completed,
+		"failed":    len(items) - completed,
+	})
+}
+
+// UploadApptFiles attaches files to an appointment
+// @Summary Upload appointment files
+// @Description Attach one or more files (e.g. signed forms) to the mortgage's current appointment. Multipart field "files" (Officer only)
+// @Tags Mortgages
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param appt_id path int true "Appointment ID"
+// @Param files formData file true "Files (PDF/JPEG/PNG)"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /mortgages/{id}/appts/{appt_id}/files [post]
+func (h *MortgageHandler) UploadApptFiles(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	apptID, err := strconv.ParseUint(c.Params("appt_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid appointment ID")
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return response.BadRequest(c, "Invalid multipart form")
+	}
+
+	headers := append(form.File["files"], form.File["file"]...)
+	if len(headers) == 0 {
+		return response.BadRequest(c, "No files uploaded")
+	}
+	if len(headers) > maxFilesPerUpload {
+		return response.BadRequest(c, fmt.Sprintf("Too many files (max %d)", maxFilesPerUpload))
+	}
+
+	// ตรวจทุกไฟล์ก่อน ถ้ามีไฟล์ไหนไม่ผ่านจะไม่บันทึกเลย
+	errs := map[string]string{}
+	contentTypes := make([]string, len(headers))
+	for i, fh := range headers {
+		contentType, err := upload.Check(fh, h.uploadRules)
+		if err != nil {
+			errs[fh.Filename] = uploadErrorMessage(err, h.uploadRules)
+			continue
+		}
+		contentTypes[i] = contentType
+
+		if err := upload.ScanFile(c.Context(), h.scanner, fh); err != nil {
+			if !errors.Is(err, upload.ErrFileInfected) {
+				logger.FromContext(c.Context()).Error("file scan failed", "file", fh.Filename, "error", err)
+				return response.ErrorWithCode(c, fiber.StatusServiceUnavailable, "SCANNER_UNAVAILABLE", "File scanner is unavailable, please try again later")
+			}
+			logger.FromContext(c.Context()).Warn("infected upload rejected", "mortgage_id", id, "file", fh.Filename, "result", err.Error())
+			errs[fh.Filename] = uploadErrorMessage(err, h.uploadRules)
+		}
+	}
+	if len(errs) > 0 {
+		return response.ValidationError(c, errs)
+	}
+
+	files := make([]*services.ApptFileInput, 0, len(headers))
+	for i, fh := range headers {
+		f, err := fh.Open()
+		if err != nil {
+			return response.InternalServerError(c, "Failed to read uploaded file")
+		}
+		defer f.Close()
+
+		files = append(files, &services.ApptFileInput{
+			FileName:    fh.Filename,
+			ContentType: contentTypes[i],
+			Size:        fh.Size,
+			Content:     f,
+		})
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	ipAddress := getClientIP(c)
+
+	attachments, err := h.mortgageService.AddApptFiles(c.Context(), uint(id), uint(apptID), files, userID, ipAddress)
+	if err != nil {
+		return mortgageError(c, err, "Failed to save files")
+	}
+
+	return response.Created(c, "Files uploaded successfully", fiber.Map{
+		"files": attachments,
+	})
+}
+
+// ListApptFiles lists files attached to an appointment
+// @Summary List appointment files
+// @Description List files attached to a mortgage appointment (Officer only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param appt_id path int true "Appointment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /mortgages/{id}/appts/{appt_id}/files [get]
+func (h *MortgageHandler) ListApptFiles(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	apptID, err := strconv.ParseUint(c.Params("appt_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid appointment ID")
+	}
+
+	attachments, err := h.mortgageService.ListApptFiles(c.Context(), uint(id), uint(apptID))
+	if err != nil {
+		return mortgageError(c, err, "Failed to list files")
+	}
+
+	return response.Success(c, "Files retrieved successfully", fiber.Map{
+		"files":        attachments,
+		"has_evidence": len(attachments) > 0,
+	})
+}
+
+// DownloadApptFile downloads a file attached to an appointment
+// @Summary Download appointment file
+// @Description Download a file attached to a mortgage appointment (Officer only)
+// @Tags Mortgages
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param appt_id path int true "Appointment ID"
+// @Param file_id path int true "File ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a signed download URL (S3 storage)"
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /mortgages/{id}/appts/{appt_id}/files/{file_id} [get]
+func (h *MortgageHandler) DownloadApptFile(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	apptID, err := strconv.ParseUint(c.Params("appt_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid appointment ID")
+	}
+
+	fileID, err := strconv.ParseUint(c.Params("file_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid file ID")
+	}
+
+	file, err := h.mortgageService.OpenApptFile(c.Context(), uint(id), uint(apptID), uint(fileID))
+	if err != nil {
+		return mortgageError(c, err, "Failed to open file")
+	}
+
+	// Object storage: send the client to a short-lived signed link instead of proxying the file
+	if file.URL != "" {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Redirect(file.URL, fiber.StatusFound)
+	}
+
+	c.Set(fiber.HeaderContentType, file.Attachment.ContentType)
+	c.Attachment(file.Attachment.FileName)
+	return c.SendStream(file.Content, int(file.Attachment.Size))
+}
+
+// uploadErrorMessage maps upload validation errors to messages
+func uploadErrorMessage(err error, rules upload.Rules) string {
+	switch {
+	case errors.Is(err, upload.ErrEmptyFile):
+		return "file is empty"
+	case errors.Is(err, upload.ErrFileTooLarge):
+		return fmt.Sprintf("file is larger than %d MB", rules.MaxBytes>>20)
+	case errors.Is(err, upload.ErrFileTypeNotAllowed):
+		return "file type is not allowed"
+	case errors.Is(err, upload.ErrFileInfected):
+		return "file failed the virus scan"
+	default:
+		return "file could not be read"
+	}
+}
+
+// SendApptReminder sends an appointment reminder to the member's LINE now
+// @Summary Send appointment reminder
+// @Description Send the appointment reminder to the member's LINE immediately (Officer only, 1 per 10 min per appointment)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param appt_id path int true "Appointment ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /mortgages/{id}/appts/{appt_id}/remind [post]
+func (h *MortgageHandler) SendApptReminder(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	apptID, err := strconv.ParseUint(c.Params("appt_id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid appointment ID")
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	ipAddress := getClientIP(c)
+
+	result, err := h.mortgageService.SendApptReminder(c.Context(), uint(id), uint(apptID), userID, ipAddress)
+	if err != nil {
+		return mortgageError(c, err, "Failed to send reminder")
+	}
+
+	message := "Reminder sent successfully"
+	if !result.Sent {
+		message = "Reminder not sent"
+	}
+
+	return response.Success(c, message, result)
+}
+
 // ChangeOfficerRequest represents change officer request
 type ChangeOfficerRequest struct {
-	OfficerID uint   `json:"officer_id"`
+	OfficerID uint   `json:"officer_id" validate:"required"`
 	Remark    string `json:"remark,omitempty"`
+	Version   uint   `json:"version,omitempty"`
 }
 
 // ChangeOfficer changes the responsible officer
@@ -664,9 +1368,11 @@ type ChangeOfficerRequest struct {
 // @Param body body ChangeOfficerRequest true "Officer data"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /mortgages/{id}/officer [put]
 func (h *MortgageHandler) ChangeOfficer(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
@@ -679,8 +1385,8 @@ func (h *MortgageHandler) ChangeOfficer(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	if req.OfficerID == 0 {
-		return response.BadRequest(c, "Officer ID is required")
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
 	}
 
 	userID, _ := c.Locals("userID").(uint)
@@ -689,21 +1395,204 @@ func (h *MortgageHandler) ChangeOfficer(c *fiber.Ctx) error {
 	input := &services.ChangeOfficerInput{
 		OfficerID: req.OfficerID,
 		Remark:    req.Remark,
+		Version:   req.Version,
 	}
 
 	mortgage, err := h.mortgageService.ChangeOfficer(c.Context(), uint(id), input, userID, ipAddress)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrMortgageNotFound):
-			return response.NotFound(c, "Mortgage not found")
-		case errors.Is(err, services.ErrOfficerNotFound):
-			return response.NotFound(c, "Officer not found")
-		default:
-			return response.InternalServerError(c, "Failed to change officer")
-		}
+		return mortgageError(c, err, "Failed to change officer")
 	}
 
 	return response.Success(c, "Officer changed successfully", fiber.Map{
-		"mortgage": mortgage.ToResponse(),
+		"mortgage": mortgageResponse(c, mortgage),
+	})
+}
+
+// DeleteMortgageRequest represents the optional delete request body
+type DeleteMortgageRequest struct {
+	Remark  string `json:"remark"`
+	Version uint   `json:"version"`
+}
+
+// Delete moves a mortgage to the trash
+// @Summary Delete mortgage
+// @Description Soft delete a mortgage; it can be restored from the trash (Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Param body body DeleteMortgageRequest false "Reason and version"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /mortgages/{id} [delete]
+func (h *MortgageHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	var req DeleteMortgageRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	ipAddress := getClientIP(c)
+
+	input := &services.DeleteInput{
+		Remark:  req.Remark,
+		Version: req.Version,
+	}
+
+	if err := h.mortgageService.Delete(c.Context(), uint(id), input, userID, ipAddress); err != nil {
+		return mortgageError(c, err, "Failed to delete mortgage")
+	}
+
+	return response.Success(c, "Mortgage moved to trash", nil)
+}
+
+// Restore restores a mortgage from the trash
+// @Summary Restore mortgage
+// @Description Restore a soft-deleted mortgage (Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /mortgages/{id}/restore [post]
+func (h *MortgageHandler) Restore(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+	ipAddress := getClientIP(c)
+
+	mortgage, err := h.mortgageService.Restore(c.Context(), uint(id), userID, ipAddress)
+	if err != nil {
+		return mortgageError(c, err, "Failed to restore mortgage")
+	}
+
+	return response.Success(c, "Mortgage restored successfully", fiber.Map{
+		"mortgage": mortgageResponse(c, mortgage),
+	})
+}
+
+// ListUnassigned lists the intake pool
+// @Summary List unassigned mortgages
+// @Description List mortgages waiting for an officer to claim them, oldest first (Officer/Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/unassigned [get]
+func (h *MortgageHandler) ListUnassigned(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	result, err := h.mortgageService.ListUnassigned(c.Context(), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list unassigned mortgages")
+	}
+
+	return response.Success(c, "Unassigned mortgages retrieved successfully", result)
+}
+
+// Claim assigns an unassigned mortgage to the current officer
+// @Summary Claim mortgage
+// @Description Pick up a mortgage from the intake pool and become its responsible officer (Officer/Admin only)
+// @Tags Mortgages
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mortgage ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /mortgages/{id}/claim [post]
+func (h *MortgageHandler) Claim(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid mortgage ID")
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+
+	mortgage, err := h.mortgageService.Claim(c.Context(), uint(id), userID, getClientIP(c))
+	if err != nil {
+		return mortgageError(c, err, "Failed to claim mortgage")
+	}
+
+	return response.Success(c, "Mortgage claimed successfully", fiber.Map{
+		"mortgage": mortgageResponse(c, mortgage),
 	})
 }
+
+// ListTrash lists soft-deleted mortgages
+// @Summary List trashed mortgages
+// @Description List soft-deleted mortgages, most recently deleted first (Admin only)
+// @Tags Mortgages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /mortgages/trash [get]
+func (h *MortgageHandler) ListTrash(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	result, err := h.mortgageService.ListTrash(c.Context(), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list trashed mortgages")
+	}
+
+	return response.Success(c, "Trashed mortgages retrieved successfully", result)
+}
+
+// GetGuarantees lists the mortgages a member guarantees
+// @Summary Member guarantee obligations
+// @Description List mortgages where the member is the guarantor, with amount, step and officer, plus total exposure (Officer/Admin only)
+// @Tags Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param memb_no path string true "Member number"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /members/{memb_no}/guarantees/detail [get]
+func (h *MortgageHandler) GetGuarantees(c *fiber.Ctx) error {
+	membNo := c.Params("memb_no")
+
+	detail, err := h.mortgageService.GetGuarantees(c.Context(), membNo)
+	if err != nil {
+		return mortgageError(c, err, "Failed to get guarantees")
+	}
+
+	return response.Success(c, "Guarantees retrieved successfully", detail)
+}
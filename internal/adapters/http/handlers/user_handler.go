@@ -1,24 +1,29 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 
 	"spsc-loaneasy/internal/core/services"
 	"spsc-loaneasy/internal/pkg/response"
+	"spsc-loaneasy/internal/pkg/timeutil"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // UserHandler handles user management endpoints
 type UserHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	privacyService *services.PrivacyService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService *services.UserService, privacyService *services.PrivacyService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		privacyService: privacyService,
 	}
 }
 
@@ -89,6 +94,9 @@ type UpdateUserRequest struct {
 	Email    *string `json:"email"`
 	Role     *string `json:"role"`
 	IsActive *bool   `json:"is_active"`
+
+	ApprovalLimit      *float64 `json:"approval_limit,omitempty"`       // largest amount the user may approve (0 = no limit)
+	ResetApprovalLimit bool     `json:"reset_approval_limit,omitempty"` // use the role default (OFFICER_APPROVAL_LIMIT) again
 }
 
 // UpdateUser handles updating a user (Admin only)
@@ -124,6 +132,9 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		Email:    req.Email,
 		Role:     req.Role,
 		IsActive: req.IsActive,
+
+		ApprovalLimit:      req.ApprovalLimit,
+		ResetApprovalLimit: req.ResetApprovalLimit,
 	}
 
 	user, err := h.userService.UpdateUserByAdmin(c.Context(), uint(id), adminID, input)
@@ -135,6 +146,10 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 			return response.Conflict(c, "Email already exists")
 		case errors.Is(err, services.ErrCannotChangeOwnRole):
 			return response.BadRequest(c, "Cannot change your own role")
+		case errors.Is(err, services.ErrCannotChangeOwnLimit):
+			return response.BadRequest(c, "Cannot change your own approval limit")
+		case errors.Is(err, services.ErrInvalidApprovalLimit):
+			return response.BadRequest(c, "Approval limit must not be negative")
 		default:
 			return response.InternalServerError(c, "Failed to update user")
 		}
@@ -255,6 +270,68 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	})
 }
 
+// GetNotificationPreferences handles getting own LINE notification preferences
+// @Summary Get notification preferences
+// @Description Get which LINE notifications the current user receives (all on by default)
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /profile/notifications [get]
+func (h *UserHandler) GetNotificationPreferences(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	pref, err := h.userService.GetNotificationPreference(c.Context(), userID)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to get notification preferences")
+	}
+
+	return response.Success(c, "Notification preferences retrieved successfully", fiber.Map{
+		"preferences": pref,
+	})
+}
+
+// UpdateNotificationPreferences handles updating own LINE notification preferences
+// @Summary Update notification preferences
+// @Description Turn LINE reminder / status change / appointment notifications on or off and pick the message language (th/en). Omitted fields are unchanged
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body services.UpdateNotificationPreferenceInput true "Preferences"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /profile/notifications [put]
+func (h *UserHandler) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	var input services.UpdateNotificationPreferenceInput
+	if err := c.BodyParser(&input); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	pref, err := h.userService.UpdateNotificationPreference(c.Context(), userID, &input)
+	if errors.Is(err, services.ErrInvalidLanguage) {
+		return response.BadRequest(c, "language must be 'th' or 'en'")
+	}
+	if err != nil {
+		return response.InternalServerError(c, "Failed to update notification preferences")
+	}
+
+	return response.Success(c, "Notification preferences updated successfully", fiber.Map{
+		"preferences": pref,
+	})
+}
+
 // ChangePasswordRequest represents change password request body
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password"`
@@ -359,3 +436,85 @@ func (h *UserHandler) SetUserRole(c *fiber.Ctx) error {
 
 	return response.Success(c, "User role updated successfully", nil)
 }
+
+// ExportMemberData handles a PDPA personal data export
+// @Summary Export member personal data
+// @Description Download everything stored about a member as JSON: account (LINE/device identifiers masked), notification settings, mortgages with history, appointments and guarantees. Other members' data is left out. Members can export only their own data, admins any member. Every export is recorded in the audit log
+// @Tags Members
+// @Produce json
+// @Security BearerAuth
+// @Param memb_no path string true "Member number"
+// @Success 200 {file} file
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /members/{memb_no}/export [get]
+func (h *UserHandler) ExportMemberData(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	membNo := c.Params("memb_no")
+	role, _ := c.Locals("role").(string)
+	ownMembNo, _ := c.Locals("membNo").(string)
+	if role != "ADMIN" && (ownMembNo == "" || ownMembNo != membNo) {
+		return response.Forbidden(c, "You can only export your own data")
+	}
+
+	export, err := h.privacyService.ExportMember(c.Context(), membNo, userID, getClientIP(c))
+	if err != nil {
+		if errors.Is(err, services.ErrExportMemberNotFound) {
+			return response.NotFound(c, "Member not found")
+		}
+		return response.InternalServerError(c, "Failed to export member data")
+	}
+
+	body, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return response.InternalServerError(c, "Failed to export member data")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Attachment(fmt.Sprintf("member-%s-%s.json", membNo, timeutil.Now().Format("20060102")))
+	return c.Send(body)
+}
+
+// AnonymizeUser handles a PDPA erasure request (Admin only)
+// @Summary Anonymize user
+// @Description Scrub a user's personal data (name, phone, LINE and device ids), keeping the row as an ANON-<id> tombstone so mortgage records stay intact for accounting. Deactivates the account, revokes all refresh tokens and records the action in the audit log. Only app-side data is erased; the flommast member register is not changed (Admin only)
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /users/{id}/anonymize [post]
+func (h *UserHandler) AnonymizeUser(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID")
+	}
+
+	adminID, _ := c.Locals("userID").(uint)
+
+	result, err := h.privacyService.AnonymizeUser(c.Context(), uint(id), adminID, getClientIP(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFoundSvc):
+			return response.NotFound(c, "User not found")
+		case errors.Is(err, services.ErrCannotDeleteSelf):
+			return response.BadRequest(c, "Cannot anonymize your own account")
+		case errors.Is(err, services.ErrAlreadyAnonymized):
+			return response.Error(c, fiber.StatusConflict, "User is already anonymized")
+		default:
+			return response.InternalServerError(c, "Failed to anonymize user")
+		}
+	}
+
+	return response.Success(c, "User anonymized successfully", result)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"spsc-loaneasy/internal/pkg/response"
+	"spsc-loaneasy/internal/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validateRequest validates a parsed request body using its `validate` tags
+// Returns true if valid, otherwise writes a 422 response with field-level errors
+func validateRequest(c *fiber.Ctx, req interface{}) (bool, error) {
+	errs := validator.Validate(req)
+	if errs == nil {
+		return true, nil
+	}
+
+	return false, response.ValidationError(c, errs)
+}
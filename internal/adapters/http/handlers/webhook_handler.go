@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"strconv"
+
+	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles webhook subscription endpoints
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// WebhookRequest represents create/update webhook request
+type WebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1"`
+	Description string   `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// ListWebhooks lists webhook subscriptions
+// @Summary List webhooks
+// @Description List webhook subscriptions and available event types (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	subs, err := h.webhookService.List(c.Context())
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list webhooks")
+	}
+
+	return response.Success(c, "Webhooks retrieved successfully", fiber.Map{
+		"webhooks":    subs,
+		"event_types": services.WebhookEventTypes,
+	})
+}
+
+// GetWebhook gets a webhook subscription
+// @Summary Get webhook
+// @Description Get a webhook subscription by ID (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	sub, err := h.webhookService.GetByID(c.Context(), uint(id))
+	if err != nil {
+		return response.NotFound(c, "Webhook not found")
+	}
+
+	return response.Success(c, "Webhook retrieved successfully", fiber.Map{
+		"webhook": sub,
+	})
+}
+
+// CreateWebhook creates a webhook subscription
+// @Summary Create webhook
+// @Description Create a webhook subscription. The signing secret is only returned here and on rotate (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body WebhookRequest true "Webhook data"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	userID, _ := c.Locals("userID").(uint)
+
+	sub, err := h.webhookService.Create(c.Context(), &services.WebhookInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	}, userID)
+	if err != nil {
+		return webhookError(c, err, "Failed to create webhook")
+	}
+
+	return response.Created(c, "Webhook created successfully", fiber.Map{
+		"webhook": sub,
+		"secret":  sub.Secret,
+	})
+}
+
+// UpdateWebhook updates a webhook subscription
+// @Summary Update webhook
+// @Description Update a webhook subscription (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param body body WebhookRequest true "Webhook data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	sub, err := h.webhookService.Update(c.Context(), uint(id), &services.WebhookInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	})
+	if err != nil {
+		return webhookError(c, err, "Failed to update webhook")
+	}
+
+	return response.Success(c, "Webhook updated successfully", fiber.Map{
+		"webhook": sub,
+	})
+}
+
+// RotateWebhookSecret generates a new signing secret
+// @Summary Rotate webhook secret
+// @Description Generate a new signing secret for a webhook (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateWebhookSecret(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	sub, err := h.webhookService.RotateSecret(c.Context(), uint(id))
+	if err != nil {
+		return webhookError(c, err, "Failed to rotate webhook secret")
+	}
+
+	return response.Success(c, "Webhook secret rotated successfully", fiber.Map{
+		"webhook": sub,
+		"secret":  sub.Secret,
+	})
+}
+
+// DeleteWebhook deletes a webhook subscription
+// @Summary Delete webhook
+// @Description Delete a webhook subscription (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	if err := h.webhookService.Delete(c.Context(), uint(id)); err != nil {
+		return webhookError(c, err, "Failed to delete webhook")
+	}
+
+	return response.Success(c, "Webhook deleted successfully", nil)
+}
+
+// ListWebhookDeliveries lists recent deliveries of a webhook
+// @Summary List webhook deliveries
+// @Description List the 50 most recent deliveries of a webhook (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid ID")
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Context(), uint(id))
+	if err != nil {
+		return webhookError(c, err, "Failed to list deliveries")
+	}
+
+	return response.Success(c, "Deliveries retrieved successfully", fiber.Map{
+		"deliveries": deliveries,
+	})
+}
+
+// webhookError maps webhook service errors to responses
+func webhookError(c *fiber.Ctx, err error, fallback string) error {
+	return mapError(c, err, webhookErrorCodes, fallback)
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"strconv"
 	"strings"
 
 	"spsc-loaneasy/internal/config"
@@ -10,6 +12,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// UserStatusChecker reports whether a user may still use access tokens already issued to them
+type UserStatusChecker interface {
+	IsActive(ctx context.Context, id uint) (bool, error)
+}
+
+var userStatus UserStatusChecker
+
+// SetUserStatusChecker makes AuthMiddleware reject the tokens of deactivated, deleted or
+// anonymized users on the next request instead of when they expire (nil disables the check)
+func SetUserStatusChecker(checker UserStatusChecker) {
+	userStatus = checker
+}
+
 // AuthMiddleware creates authentication middleware
 func AuthMiddleware(cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -40,16 +55,53 @@ func AuthMiddleware(cfg *config.Config) fiber.Handler {
 			return response.Unauthorized(c, "Invalid access token")
 		}
 
-		// 5. Set user info in context
+		// 5. Reject tokens of users deactivated or anonymized after the token was issued
+		if userStatus != nil {
+			active, err := userStatus.IsActive(c.Context(), claims.UserID)
+			if err != nil {
+				return response.InternalServerError(c, "Failed to check user status")
+			}
+			if !active {
+				return response.Unauthorized(c, "Account is no longer active")
+			}
+		}
+
+		// 6. Set user info in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("membNo", claims.MembNo)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		if claims.ImpersonatedBy != 0 {
+			if !isReadOnlyMethod(c.Method()) {
+				return response.Forbidden(c, "This action is not allowed while impersonating a user")
+			}
+			c.Locals("impersonatedBy", claims.ImpersonatedBy)
+			c.Set("X-Impersonated-By", strconv.FormatUint(uint64(claims.ImpersonatedBy), 10))
+		}
 
 		return c.Next()
 	}
 }
 
+// isReadOnlyMethod reports whether a request cannot change state. Impersonation tokens
+// are read-only: support staff see what the member sees but never act as them
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isUserActive is the UserStatusChecker lookup for optional auth, where errors mean anonymous
+func isUserActive(c *fiber.Ctx, userID uint) bool {
+	if userStatus == nil {
+		return true
+	}
+	active, err := userStatus.IsActive(c.Context(), userID)
+	return err == nil && active
+}
+
 // RoleMiddleware creates role-based authorization middleware
 func RoleMiddleware(allowedRoles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -98,11 +150,14 @@ func OptionalAuth(cfg *config.Config) fiber.Handler {
 		// If token exists, validate and set user info
 		if accessToken != "" {
 			claims, err := jwt.ValidateAccessToken(accessToken, cfg.JWT.Secret)
-			if err == nil {
+			if err == nil && (claims.ImpersonatedBy == 0 || isReadOnlyMethod(c.Method())) && isUserActive(c, claims.UserID) {
 				c.Locals("userID", claims.UserID)
 				c.Locals("membNo", claims.MembNo)
 				c.Locals("username", claims.Username)
 				c.Locals("role", claims.Role)
+				if claims.ImpersonatedBy != 0 {
+					c.Locals("impersonatedBy", claims.ImpersonatedBy)
+				}
 			}
 		}
 
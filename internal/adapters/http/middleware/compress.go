@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"strings"
+
+	"spsc-loaneasy/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Compress compresses responses (brotli/gzip/deflate per Accept-Encoding)
+// Same as fiber's compress middleware but only for bodies >= cfg.Compress.MinBytes
+// and never for event-stream (SSE) or streamed bodies, which must not be buffered
+func Compress(cfg *config.Config) fiber.Handler {
+	var compressor fasthttp.RequestHandler
+	noop := func(ctx *fasthttp.RequestCtx) {}
+
+	switch cfg.Compress.Level {
+	case "speed":
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBestSpeed)
+	case "default":
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression)
+	case "best":
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliBestCompression, fasthttp.CompressBestCompression)
+	default:
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	minBytes := cfg.Compress.MinBytes
+
+	return func(c *fiber.Ctx) error {
+		// SSE: ห้ามบีบอัด ไม่งั้น event จะค้างอยู่ใน buffer
+		if isEventStream(c.Get(fiber.HeaderAccept)) {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.IsBodyStream() ||
+			isEventStream(string(resp.Header.ContentType())) ||
+			len(resp.Body()) < minBytes {
+			return nil
+		}
+
+		compressor(c.Context())
+		return nil
+	}
+}
+
+func isEventStream(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "text/event-stream")
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"spsc-loaneasy/internal/config"
+	"spsc-loaneasy/internal/pkg/maintenance"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maintenanceExemptPaths stay writable during maintenance
+// (health checks, login/LINE/LIFF auth, and the toggle itself so an admin can turn it off)
+var maintenanceExemptPaths = []string{
+	"/health",
+	"/api/v1/auth",
+	"/api/v1/system/maintenance",
+}
+
+// Maintenance blocks write requests with 503 + Retry-After while maintenance mode is on
+// GET/HEAD/OPTIONS are always allowed so the apps stay readable during data migrations
+func Maintenance(cfg *config.Config) fiber.Handler {
+	if cfg.Maintenance.Enabled {
+		maintenance.Set(true, "", "MAINTENANCE_MODE")
+	}
+	retryAfter := strconv.Itoa(cfg.Maintenance.RetryAfterSecs)
+
+	return func(c *fiber.Ctx) error {
+		if !maintenance.Enabled() {
+			return c.Next()
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		path := c.Path()
+		for _, prefix := range maintenanceExemptPaths {
+			if strings.HasPrefix(path, prefix) {
+				return c.Next()
+			}
+		}
+
+		message := "ระบบอยู่ระหว่างปรับปรุง ไม่สามารถบันทึกข้อมูลได้ชั่วคราว กรุณาลองใหม่ภายหลัง"
+		if m := maintenance.Get().Message; m != "" {
+			message = m
+		}
+
+		c.Set(fiber.HeaderRetryAfter, retryAfter)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Service under maintenance",
+			"code":    "MAINTENANCE",
+			"message": message,
+		})
+	}
+}
@@ -1,28 +1,31 @@
 package middleware
 
 import (
+	"strconv"
 	"time"
 
 	"spsc-loaneasy/internal/config"
+	"spsc-loaneasy/internal/pkg/i18n"
+	"spsc-loaneasy/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 // Setup configures all middlewares for the application
 func Setup(app *fiber.App, cfg *config.Config) {
-	// Recover middleware - catches panics
-	app.Use(recover.New())
+	// Request ID - ส่งกลับใน X-Request-ID และใช้ใน log
+	app.Use(requestid.New())
 
-	// Gzip Compression middleware - ลด response size 60-70%
-	app.Use(compress.New(compress.Config{
-		Level: compress.LevelBestSpeed, // เร็วที่สุด เหมาะกับ API
-	}))
+	// Recover middleware - catches panics, logs stack + request id, returns JSON 500
+	app.Use(Recover())
+
+	// Compression middleware - ลด response size 60-70% (COMPRESS_LEVEL / COMPRESS_MIN_BYTES)
+	app.Use(Compress(cfg))
 
 	// Security Headers middleware (Helmet)
 	app.Use(helmet.New(helmet.Config{
@@ -57,11 +60,11 @@ func Setup(app *fiber.App, cfg *config.Config) {
 	// Logger middleware
 	if cfg.IsDev() {
 		app.Use(logger.New(logger.Config{
-			Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path}\n",
+			Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${locals:requestid}\n",
 		}))
 	} else {
 		app.Use(logger.New(logger.Config{
-			Format:     "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+			Format:     "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${locals:requestid} | ${error}\n",
 			TimeFormat: "2006-01-02 15:04:05",
 		}))
 	}
@@ -84,43 +87,98 @@ func Setup(app *fiber.App, cfg *config.Config) {
 			AllowCredentials: true,
 		}))
 	}
+
+	// Maintenance mode - 503 for writes while ops run data migrations (reads stay available)
+	app.Use(Maintenance(cfg))
 }
 
 // AuthRateLimiter creates a stricter rate limiter for auth endpoints
 // 5 requests per minute per IP (for login, register, etc.)
 func AuthRateLimiter() fiber.Handler {
+	const max, window = 5, 1 * time.Minute
 	return limiter.New(limiter.Config{
-		Max:        5,
-		Expiration: 1 * time.Minute,
+		Max:        max,
+		Expiration: window,
 		KeyGenerator: func(c *fiber.Ctx) string {
 			return c.IP() + "-auth"
 		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error":   "Too many login attempts",
-				"message": "คุณพยายาม login มากเกินไป กรุณารอ 1 นาที",
-			})
-		},
+		LimitReached: rateLimitReached(max, window, "TOO_MANY_LOGIN_ATTEMPTS", "ratelimit.auth"),
 	})
 }
 
 // StrictRateLimiter creates an even stricter rate limiter for sensitive operations
 // 3 requests per minute per IP (for password reset, etc.)
 func StrictRateLimiter() fiber.Handler {
+	const max, window = 3, 1 * time.Minute
 	return limiter.New(limiter.Config{
-		Max:        3,
-		Expiration: 1 * time.Minute,
+		Max:        max,
+		Expiration: window,
 		KeyGenerator: func(c *fiber.Ctx) string {
 			return c.IP() + "-strict"
 		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error":   "Rate limit exceeded",
-				"message": "กรุณารอสักครู่ก่อนลองใหม่",
+		LimitReached: rateLimitReached(max, window, "RATE_LIMITED", "ratelimit.strict"),
+	})
+}
+
+// rateLimitReached answers a limited request in the standard envelope with the limit headers
+// The limiter sets Retry-After itself but skips X-RateLimit-* on 429, so they are added here.
+// The message (th/en via Accept-Language) tells the client how long to wait
+func rateLimitReached(max int, window time.Duration, code, messageKey string) fiber.Handler {
+	windowSecs := int(window.Seconds())
+	return func(c *fiber.Ctx) error {
+		retryAfter, err := strconv.Atoi(string(c.Response().Header.Peek(fiber.HeaderRetryAfter)))
+		if err != nil || retryAfter <= 0 {
+			retryAfter = windowSecs
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(max))
+		c.Set("X-RateLimit-Remaining", "0")
+		c.Set("X-RateLimit-Reset", strconv.Itoa(retryAfter))
+
+		lang := i18n.FromRequest(c)
+		return response.ErrorWithData(c, fiber.StatusTooManyRequests, code,
+			i18n.T(lang, messageKey, max, windowSecs, retryAfter),
+			fiber.Map{
+				"limit":               max,
+				"window_seconds":      windowSecs,
+				"retry_after_seconds": retryAfter,
 			})
+	}
+}
+
+// WriteRateLimiter creates a per-user rate limiter for write endpoints
+// Keyed by user id (falls back to IP if not authenticated), GET/HEAD requests are exempt
+func WriteRateLimiter(cfg *config.Config) fiber.Handler {
+	max, window := cfg.RateLimit.WriteMax, time.Duration(cfg.RateLimit.WriteWindowSecs)*time.Second
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		Next: func(c *fiber.Ctx) bool {
+			return c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+		},
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if userID, ok := c.Locals("userID").(uint); ok && userID > 0 {
+				return "write-user-" + strconv.FormatUint(uint64(userID), 10)
+			}
+			return c.IP() + "-write"
+		},
+		LimitReached: rateLimitReached(max, window, "RATE_LIMITED", "ratelimit.write"),
+	})
+}
+
+// ApptReminderRateLimiter limits manual reminders to 1 per 10 minutes per appointment
+// Keyed by mortgage id + appointment id so staff can't spam a member
+func ApptReminderRateLimiter() fiber.Handler {
+	const max, window = 1, 10 * time.Minute
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "remind-" + c.Params("id") + "-" + c.Params("appt_id")
 		},
+		LimitReached:       rateLimitReached(max, window, "REMINDER_ALREADY_SENT", "ratelimit.reminder"),
+		SkipFailedRequests: true,
 	})
 }
 
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"spsc-loaneasy/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Recover catches panics in handlers, logs the stack with the request id
+// and returns a JSON 500 in the standard response envelope.
+// Errors returned by handlers (including *fiber.Error) are passed through untouched
+// so CustomErrorHandler still handles them.
+func Recover() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				requestID, _ := c.Locals("requestid").(string)
+				slog.Error("panic recovered",
+					"request_id", requestID,
+					"method", c.Method(),
+					"path", c.Path(),
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+
+				// response อาจถูกเขียนไปบางส่วนแล้ว - เคลียร์ก่อนตอบ 500
+				c.Response().ResetBody()
+				err = response.InternalServerError(c, fmt.Sprintf("Internal Server Error (request_id: %s)", requestID))
+			}
+		}()
+
+		return c.Next()
+	}
+}
@@ -1,11 +1,19 @@
 package routes
 
 import (
+	"log/slog"
+	"os"
+	"time"
+
 	"spsc-loaneasy/internal/adapters/http/handlers"
 	"spsc-loaneasy/internal/adapters/http/middleware"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
 	"spsc-loaneasy/internal/config"
+	"spsc-loaneasy/internal/core/events"
 	"spsc-loaneasy/internal/core/services"
+	"spsc-loaneasy/internal/pkg/email"
+	"spsc-loaneasy/internal/pkg/storage"
+	"spsc-loaneasy/internal/pkg/upload"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
@@ -19,6 +27,9 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
 	memberRepo := repositories.NewMemberRepository(db)
 
+	// Access tokens stop working as soon as a user is deactivated or anonymized
+	middleware.SetUserStatusChecker(userRepo)
+
 	// Phase 4: Master repositories
 	loanTypeRepo := repositories.NewLoanTypeRepository(db)
 	loanStepRepo := repositories.NewLoanStepRepository(db)
@@ -28,18 +39,45 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 	// Phase 4: Mortgage repositories
 	mortgageRepo := repositories.NewMortgageRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db)
+	noteRepo := repositories.NewMortgageNoteRepository(db)
+	attachmentRepo := repositories.NewApptAttachmentRepository(db)
+
+	// Webhook repository
+	webhookRepo := repositories.NewWebhookRepository(db)
 
 	// Initialize services
+	auditRepo := repositories.NewAuditLogRepository(db)
 	authService := services.NewAuthService(userRepo, refreshTokenRepo, memberRepo, cfg)
-	userService := services.NewUserService(userRepo, memberRepo)
+	authService.SetAuditLogRepository(auditRepo)
+	prefRepo := repositories.NewNotificationPreferenceRepository(db)
+	userService := services.NewUserService(userRepo, memberRepo, prefRepo)
+	privacyService := services.NewPrivacyService(db, auditRepo)
 
 	// Phase 4: Notification service
 	notifyService := services.NewNotificationService()
+	notifyService.SetPreferenceRepository(prefRepo)
+	notifyService.SetHighValueAlert(cfg.Mortgage.HighValueAlertAmount, cfg.Mortgage.HighValueAlertGroupID)
+	if cfg.SMTP.Host != "" {
+		sender, err := email.NewSMTPSender(email.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+		if err != nil {
+			slog.Warn("member emails disabled", "error", err)
+		} else {
+			notifyService.SetEmailSender(sender, userRepo)
+		}
+	}
 
 	// Phase 4: Mortgage service
 	mortgageService := services.NewMortgageService(
 		mortgageRepo,
 		transactionRepo,
+		noteRepo,
+		attachmentRepo,
 		loanTypeRepo,
 		loanStepRepo,
 		loanDocRepo,
@@ -49,30 +87,74 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 		notifyService,
 	)
 
+	if err := mortgageService.SetContractNoFormat(cfg.Mortgage.ContractNoPattern); err != nil {
+		slog.Warn("contract number format check disabled", "error", err)
+	}
+	mortgageService.SetRequireDocsOnApprove(cfg.Mortgage.RequireDocsOnApprove)
+	mortgageService.SetAutoAdvanceOnDocs(cfg.Mortgage.AutoAdvanceFromStep, cfg.Mortgage.AutoAdvanceToStep)
+	mortgageService.SetOfficerApprovalLimit(cfg.Mortgage.OfficerApprovalLimit)
+	mortgageService.SetDualApprovalThreshold(cfg.Mortgage.DualApprovalThreshold)
+
+	// Printable documents
+	pdfService := services.NewPDFService(memberRepo, transactionRepo, loanApptRepo, cfg)
+
+	// Outbound webhooks (mortgage status events)
+	webhookService := services.NewWebhookService(webhookRepo)
+
+	// Domain events: the mortgage service publishes, LINE notifications and webhooks subscribe
+	eventBus := events.NewBus()
+	notifyService.Subscribe(eventBus)
+	webhookService.Subscribe(eventBus)
+	mortgageService.SetEventBus(eventBus)
+
+	// File storage for uploads (appointment attachments)
+	if fileStorage, err := newFileStorage(cfg.Upload); err != nil {
+		slog.Warn("file uploads disabled", "driver", cfg.Upload.Driver, "error", err)
+	} else {
+		mortgageService.SetFileStorage(fileStorage, time.Duration(cfg.Upload.URLExpiryMins)*time.Minute)
+	}
+
 	// Phase 5: Dashboard service
-	dashboardService := services.NewDashboardService(db)
+	dashboardService := services.NewDashboardService(db, loanDocRepo, transactionRepo)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler()
 	authHandler := handlers.NewAuthHandler(authService, cfg)
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, privacyService)
 
 	// Phase 4: Handlers
-	mortgageHandler := handlers.NewMortgageHandler(mortgageService)
-	masterHandler := handlers.NewMasterHandler(loanTypeRepo, loanStepRepo, loanDocRepo, loanApptRepo)
+	mortgageHandler := handlers.NewMortgageHandler(mortgageService, pdfService, cfg)
+	// A bad scanner config stops startup instead of silently accepting unscanned files
+	if scanner, err := upload.NewScanner(cfg.Upload.Scanner, cfg.Upload.ScannerAddr, time.Duration(cfg.Upload.ScanTimeoutSecs)*time.Second); err != nil {
+		slog.Error("invalid FILE_SCANNER config", "error", err)
+		os.Exit(1)
+	} else {
+		mortgageHandler.SetFileScanner(scanner)
+	}
+	masterHandler := handlers.NewMasterHandler(loanTypeRepo, loanStepRepo, loanDocRepo, loanApptRepo, services.NewMasterService(db))
 
 	// Phase 5: Dashboard handler
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 
+	// Webhook handler
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	// LINE binding admin tools (duplicate report)
+	lineAdminHandler := handlers.NewLINEAdminHandler(services.NewLINEBindingService(db))
+
 	// LINE Handler
-	lineHandler := handlers.NewLINEHandler(db)
+	lineHandler := handlers.NewLINEHandler(db, cfg)
 
 	// ============================================================
 	// ✅ LIFF Handler v2 - รับ lineService + otpService
 	// ============================================================
 	lineService := lineHandler.GetLINEService()
 	otpService := services.NewOTPService(db)
-	liffHandler := handlers.NewLIFFHandler(db, lineService, otpService)
+	liffHandler := handlers.NewLIFFHandler(db, lineService, otpService, cfg)
+
+	// Manual appointment reminders and member notifications are sent through LINE Messaging
+	mortgageService.SetLINEService(lineService)
+	notifyService.SetLINEService(lineService)
 
 	// v2.2.2: Mobile Handler (Aggregated APIs)
 	mobileHandler := handlers.NewMobileHandler(
@@ -83,6 +165,7 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 		loanDocRepo,
 		loanApptRepo,
 		transactionRepo,
+		noteRepo,
 	)
 
 	// Health check & root routes
@@ -94,13 +177,28 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 
 	// API v1 group
 	apiV1 := app.Group("/api/v1")
-	setupAPIV1Routes(apiV1, healthHandler, authHandler, userHandler, mortgageHandler, masterHandler, dashboardHandler, lineHandler, liffHandler, cfg)
+	setupAPIV1Routes(apiV1, healthHandler, authHandler, userHandler, mortgageHandler, masterHandler, dashboardHandler, lineHandler, liffHandler, webhookHandler, lineAdminHandler, cfg)
 
 	// API v2 group (Mobile-optimized)
 	apiV2 := app.Group("/api/v2")
 	setupAPIV2Routes(apiV2, mobileHandler, cfg)
 }
 
+// newFileStorage builds the upload storage selected by STORAGE_DRIVER
+func newFileStorage(cfg config.UploadConfig) (storage.Storage, error) {
+	if cfg.Driver == storage.DriverS3 {
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		})
+	}
+	return storage.NewLocalStorage(cfg.Dir)
+}
+
 // setupAPIV1Routes configures API v1 routes
 func setupAPIV1Routes(
 	router fiber.Router,
@@ -112,6 +210,8 @@ func setupAPIV1Routes(
 	dashboardHandler *handlers.DashboardHandler,
 	lineHandler *handlers.LINEHandler,
 	liffHandler *handlers.LIFFHandler,
+	webhookHandler *handlers.WebhookHandler,
+	lineAdminHandler *handlers.LINEAdminHandler,
 	cfg *config.Config,
 ) {
 	// API Info
@@ -142,7 +242,13 @@ func setupAPIV1Routes(
 	// Phase 4: Mortgage routes (Officer/Admin)
 	mortgageRoutes := router.Group("/mortgages")
 	mortgageRoutes.Use(middleware.AuthMiddleware(cfg))
-	setupMortgageRoutes(mortgageRoutes, mortgageHandler, cfg)
+	setupMortgageRoutes(mortgageRoutes, mortgageHandler, dashboardHandler, cfg)
+
+	// Member lookups (Officer/Admin) + PDPA export (self or Admin, checked in handler)
+	memberRoutes := router.Group("/members")
+	memberRoutes.Use(middleware.AuthMiddleware(cfg))
+	memberRoutes.Get("/:memb_no/guarantees/detail", middleware.OfficerOrAdmin(), mortgageHandler.GetGuarantees)
+	memberRoutes.Get("/:memb_no/export", middleware.StrictRateLimiter(), userHandler.ExportMemberData)
 
 	// Phase 4: Master routes (Admin only)
 	masterRoutes := router.Group("/master")
@@ -153,6 +259,27 @@ func setupAPIV1Routes(
 	dashboardRoutes := router.Group("/dashboard")
 	dashboardRoutes.Use(middleware.AuthMiddleware(cfg))
 	setupDashboardRoutes(dashboardRoutes, dashboardHandler)
+
+	// Webhook subscription routes (Admin only)
+	webhookRoutes := router.Group("/webhooks")
+	webhookRoutes.Use(middleware.AuthMiddleware(cfg))
+	webhookRoutes.Use(middleware.AdminOnly())
+	setupWebhookRoutes(webhookRoutes, webhookHandler)
+
+	// System routes (Admin only) - maintenance mode toggle
+	systemRoutes := router.Group("/system")
+	systemRoutes.Use(middleware.AuthMiddleware(cfg))
+	systemRoutes.Use(middleware.AdminOnly())
+	systemRoutes.Get("/maintenance", healthHandler.GetMaintenance)
+	systemRoutes.Put("/maintenance", healthHandler.SetMaintenance)
+
+	// Admin tools (Admin only) - LINE binding integrity, support impersonation
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(middleware.AuthMiddleware(cfg))
+	adminRoutes.Use(middleware.AdminOnly())
+	adminRoutes.Get("/line/duplicates", lineAdminHandler.ListDuplicates)
+	adminRoutes.Post("/line/duplicates/:user_id/unbind", lineAdminHandler.UnbindDuplicate)
+	adminRoutes.Post("/users/:id/impersonate", middleware.StrictRateLimiter(), authHandler.Impersonate)
 }
 
 // setupAuthRoutes configures authentication routes
@@ -165,6 +292,7 @@ func setupAuthRoutes(router fiber.Router, handler *handlers.AuthHandler, cfg *co
 
 	// Protected routes
 	router.Get("/me", middleware.AuthMiddleware(cfg), handler.Me)
+	router.Get("/whoami", middleware.AuthMiddleware(cfg), handler.Whoami)
 	router.Post("/logout-all", middleware.AuthMiddleware(cfg), handler.LogoutAll)
 }
 
@@ -214,9 +342,10 @@ func setupLIFFRoutes(router fiber.Router, handler *handlers.LIFFHandler) {
 func setupUserRoutes(router fiber.Router, handler *handlers.UserHandler) {
 	router.Get("/", handler.ListUsers)
 	router.Get("/:id", handler.GetUser)
-	router.Put("/:id", handler.UpdateUser)
-	router.Delete("/:id", handler.DeleteUser)
-	router.Put("/:id/role", handler.SetUserRole)
+	router.Put("/:id", middleware.AdminOnly(), handler.UpdateUser)
+	router.Delete("/:id", middleware.AdminOnly(), handler.DeleteUser)
+	router.Put("/:id/role", middleware.AdminOnly(), handler.SetUserRole)
+	router.Post("/:id/anonymize", middleware.AdminOnly(), handler.AnonymizeUser)
 }
 
 // setupProfileRoutes configures profile routes (Authenticated)
@@ -224,38 +353,81 @@ func setupProfileRoutes(router fiber.Router, handler *handlers.UserHandler) {
 	router.Get("/", handler.GetProfile)
 	router.Put("/", handler.UpdateProfile)
 	router.Put("/password", handler.ChangePassword)
+	router.Get("/notifications", handler.GetNotificationPreferences)
+	router.Put("/notifications", handler.UpdateNotificationPreferences)
 }
 
 // setupMortgageRoutes configures mortgage routes (Phase 4)
-func setupMortgageRoutes(router fiber.Router, handler *handlers.MortgageHandler, cfg *config.Config) {
+func setupMortgageRoutes(router fiber.Router, handler *handlers.MortgageHandler, dashboardHandler *handlers.DashboardHandler, cfg *config.Config) {
 	// Member can view their own mortgages
 	router.Get("/my", handler.GetMyMortgages)
+	router.Get("/my/activity", handler.GetMyActivity)
+
+	// Summary PDF - member (own only) or Officer/Admin
+	router.Get("/:id/pdf", handler.GetPDF)
 
 	// Officer/Admin routes
 	officerRoutes := router.Group("")
 	officerRoutes.Use(middleware.OfficerOrAdmin())
 
-	officerRoutes.Post("/", handler.Create)
+	// Per-user write limiter (shared across write endpoints, GET is exempt)
+	writeLimiter := middleware.WriteRateLimiter(cfg)
+
+	officerRoutes.Post("/", writeLimiter, handler.Create)
 	officerRoutes.Get("/", handler.List)
+	officerRoutes.Get("/appointments", handler.ListApptsByDate)
+	officerRoutes.Post("/appts/complete-batch", writeLimiter, handler.CompleteApptBatch)
+	// Static paths must be registered before /:id
+	officerRoutes.Get("/trash", middleware.AdminOnly(), handler.ListTrash)
+	officerRoutes.Get("/suggest-officer", middleware.AdminOnly(), dashboardHandler.SuggestOfficer)
+	officerRoutes.Get("/unassigned", handler.ListUnassigned)
+	officerRoutes.Get("/outstanding-docs", dashboardHandler.GetOutstandingDocs)
 	officerRoutes.Get("/:id", handler.GetByID)
 	officerRoutes.Get("/:id/history", handler.GetHistory)
+	officerRoutes.Get("/:id/notes", handler.ListNotes)
+	officerRoutes.Post("/:id/notes", writeLimiter, handler.AddNote)
 	officerRoutes.Get("/:id/docs", handler.GetDocs)
-	officerRoutes.Put("/:id/docs", handler.UpdateDoc)
+	officerRoutes.Get("/:id/docs/outstanding", handler.GetOutstandingDocs)
+	officerRoutes.Put("/:id/docs", writeLimiter, handler.UpdateDoc)
 	officerRoutes.Get("/:id/appts", handler.GetAppts)
 	officerRoutes.Post("/:id/appts", handler.CreateAppt)
 	officerRoutes.Put("/:id/appts/:appt_id/complete", handler.CompleteAppt)
-	officerRoutes.Put("/:id/step", handler.ChangeStep)
-	officerRoutes.Put("/:id/approve", handler.Approve)
-	officerRoutes.Put("/:id/reject", handler.Reject)
+	officerRoutes.Get("/:id/appts/:appt_id/files", handler.ListApptFiles)
+	officerRoutes.Post("/:id/appts/:appt_id/files", writeLimiter, handler.UploadApptFiles)
+	officerRoutes.Get("/:id/appts/:appt_id/files/:file_id", handler.DownloadApptFile)
+	officerRoutes.Post("/:id/appts/:appt_id/remind", middleware.ApptReminderRateLimiter(), handler.SendApptReminder)
+	officerRoutes.Put("/:id/step", writeLimiter, handler.ChangeStep)
+	officerRoutes.Put("/:id/approve", writeLimiter, handler.Approve)
+	officerRoutes.Put("/:id/approve/confirm", writeLimiter, handler.ConfirmApproval)
+	officerRoutes.Put("/:id/reject", writeLimiter, handler.Reject)
+	officerRoutes.Post("/:id/clone", writeLimiter, handler.Clone)
+	officerRoutes.Post("/:id/claim", writeLimiter, handler.Claim)
 
 	// Admin only
 	adminRoutes := router.Group("")
 	adminRoutes.Use(middleware.AdminOnly())
 	adminRoutes.Put("/:id/officer", handler.ChangeOfficer)
+	adminRoutes.Delete("/:id", handler.Delete)
+	adminRoutes.Post("/:id/restore", handler.Restore)
+}
+
+// setupWebhookRoutes configures webhook subscription routes (Admin only)
+func setupWebhookRoutes(router fiber.Router, handler *handlers.WebhookHandler) {
+	router.Get("/", handler.ListWebhooks)
+	router.Post("/", handler.CreateWebhook)
+	router.Get("/:id", handler.GetWebhook)
+	router.Put("/:id", handler.UpdateWebhook)
+	router.Delete("/:id", handler.DeleteWebhook)
+	router.Post("/:id/rotate-secret", handler.RotateWebhookSecret)
+	router.Get("/:id/deliveries", handler.ListWebhookDeliveries)
 }
 
 // setupMasterRoutes configures master data routes (Admin only) (Phase 4)
 func setupMasterRoutes(router fiber.Router, handler *handlers.MasterHandler) {
+	// All master sets at once (setup wizard)
+	router.Get("/all", middleware.AdminOnly(), handler.GetAll)
+	router.Post("/import", middleware.AdminOnly(), handler.Import)
+
 	// Loan Types
 	router.Get("/loan-types", handler.ListLoanTypes)
 	router.Get("/loan-types/:id", handler.GetLoanType)
@@ -298,6 +470,12 @@ func setupDashboardRoutes(router fiber.Router, handler *handlers.DashboardHandle
 
 	// Admin dashboard (Admin only)
 	router.Get("/admin", middleware.AdminOnly(), handler.GetAdminDashboard)
+
+	// Officer performance over a period (Admin only)
+	router.Get("/admin/officers", middleware.AdminOnly(), handler.GetOfficerPerformance)
+
+	// Step pipeline with case counts (Admin only)
+	router.Get("/admin/pipeline", middleware.AdminOnly(), handler.GetPipeline)
 }
 
 // setupAPIV2Routes configures API v2 routes (Mobile-optimized)
@@ -312,6 +490,9 @@ func setupAPIV2Routes(router fiber.Router, mobileHandler *handlers.MobileHandler
 	// GET /api/v2/mobile/my-loans
 	mobileRoutes.Get("/my-loans", mobileHandler.GetMyLoans)
 
+	// GET /api/v2/mobile/loans/:id - mortgage + timeline + docs + appointments
+	mobileRoutes.Get("/loans/:id", mobileHandler.GetLoanDetail)
+
 	// GET /api/v2/mobile/master
 	mobileRoutes.Get("/master", mobileHandler.GetMasterData)
 }
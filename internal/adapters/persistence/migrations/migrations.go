@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// precheck reports data that must be fixed by hand before a migration can succeed
+// A failing check leaves that migration and the ones after it pending without stopping startup
+type precheck struct {
+	Version uint
+	Check   func(db *sql.DB) error
+}
+
+// prechecks must stay sorted by version
+var prechecks = []precheck{
+	{Version: 2, Check: checkLineUserIDDuplicates},
+}
+
+// checkLineUserIDDuplicates guards 000002_users_line_user_id_unique
+// Registration binds line_user_id with raw SQL after a check-then-insert, so older databases
+// may already hold duplicates; those must be resolved first (GET /api/v1/admin/line/duplicates)
+func checkLineUserIDDuplicates(db *sql.DB) error {
+	var count int64
+	err := db.QueryRow(`SELECT COUNT(*) FROM (
+		SELECT line_user_id FROM users
+		WHERE deleted_at IS NULL AND line_user_id IS NOT NULL AND line_user_id <> ''
+		GROUP BY line_user_id HAVING COUNT(*) > 1
+	) d`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%d LINE ids are bound to more than one user, resolve them via GET /api/v1/admin/line/duplicates", count)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+
+	"spsc-loaneasy/internal/config"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// files holds the versioned migrations as <version>_<name>.up.sql / .down.sql pairs
+// เพิ่ม migration ใหม่ต่อท้ายเสมอ ห้ามแก้หรือเรียงลำดับไฟล์ที่ deploy ไปแล้ว
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Migrator applies the SQL migrations in sql/ with golang-migrate
+// AutoMigrate ยังใช้สร้างตารางใหม่ได้ แต่การเปลี่ยน schema ของตารางเดิม (เช่น users) ให้ทำผ่าน migration
+// และ column ที่ migration เป็นเจ้าของต้องติด tag gorm:"-:migration" ใน model
+type Migrator struct {
+	db *sql.DB
+	m  *migrate.Migrate
+}
+
+// Open connects a migrator to the configured database
+func Open(cfg *config.Config) (*Migrator, error) {
+	db, err := config.OpenMigrationDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// New creates a migrator on db, which must allow multiStatements (see config.OpenMigrationDB)
+func New(db *sql.DB) (*Migrator, error) {
+	src, err := iofs.New(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	driver, err := mysql.WithInstance(db, &mysql.Config{})
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "mysql", driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, m: m}, nil
+}
+
+// Close releases the migration connection
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
+
+// Version returns the applied version (0 = none)
+// A dirty version means a migration failed halfway and must be fixed by hand, then cleared with -force
+func (m *Migrator) Version() (uint, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return version, fmt.Errorf("database is dirty at version %d, fix it by hand then run go run ./cmd/migrate -force %d", version, version)
+	}
+	return version, nil
+}
+
+// Up applies all pending migrations in order and returns the version reached
+// A migration whose precheck fails is postponed together with the ones after it, without an error
+func (m *Migrator) Up() (uint, error) {
+	current, err := m.Version()
+	if err != nil {
+		return current, err
+	}
+
+	// Prechecks run against the schema they guard, so migrate up to the version before each one first
+	postponed := false
+	for _, p := range prechecks {
+		if p.Version <= current {
+			continue
+		}
+		if p.Version-1 > current {
+			if err := m.m.Migrate(p.Version - 1); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+				version, _ := m.Version()
+				return version, err
+			}
+		}
+		if err := p.Check(m.db); err != nil {
+			slog.Warn("migration postponed", "version", p.Version, "reason", err)
+			postponed = true
+			break
+		}
+	}
+
+	if !postponed {
+		if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			version, _ := m.Version()
+			return version, err
+		}
+	}
+
+	version, err := m.Version()
+	if err == nil && version != current {
+		slog.Info("database migrated", "from_version", current, "to_version", version)
+	}
+	return version, err
+}
+
+// Down rolls back the most recently applied migration and returns the version it undid
+// Returns 0 if nothing has been applied
+func (m *Migrator) Down() (uint, error) {
+	current, err := m.Version()
+	if err != nil || current == 0 {
+		return 0, err
+	}
+	if err := m.m.Steps(-1); err != nil {
+		return 0, err
+	}
+
+	slog.Info("migration rolled back", "version", current)
+	return current, nil
+}
+
+// Force sets the recorded version without running migrations (clears a dirty state)
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(entries)/2)
+	for _, entry := range entries {
+		mig, err := source.Parse(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if mig.Direction != source.Up {
+			continue
+		}
+		statuses = append(statuses, MigrationStatus{
+			Version: mig.Version,
+			Name:    mig.Identifier,
+			Applied: mig.Version <= current,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
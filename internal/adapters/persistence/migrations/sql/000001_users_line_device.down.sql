@@ -0,0 +1,3 @@
+-- No-op: the up only adds columns that are missing, and production already had them
+-- (migration_device_otp.sql), so dropping them here would destroy real LINE links and bound devices
+DO 0;
@@ -0,0 +1,2 @@
+DROP INDEX idx_users_line_user_id ON users;
+CREATE INDEX idx_users_line_user_id ON users (line_user_id);
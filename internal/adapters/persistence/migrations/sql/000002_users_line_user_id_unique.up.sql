@@ -0,0 +1,15 @@
+-- One LINE account binds to one user (duplicates are checked first, see checkLineUserIDDuplicates)
+
+-- Empty strings and bindings left on deleted users would collide with the unique index
+UPDATE users SET line_user_id = NULL WHERE line_user_id = '';
+UPDATE users SET line_user_id = NULL, line_display_name = NULL, line_picture_url = NULL, line_linked_at = NULL
+  WHERE deleted_at IS NOT NULL AND line_user_id IS NOT NULL;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND INDEX_NAME = 'idx_users_line_user_id') > 0,
+  'DROP INDEX idx_users_line_user_id ON users', 'DO 0');
+PREPARE stmt FROM @ddl;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+CREATE UNIQUE INDEX idx_users_line_user_id ON users (line_user_id);
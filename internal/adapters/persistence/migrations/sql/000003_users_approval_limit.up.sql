@@ -0,0 +1,8 @@
+-- Per-user approval limit (NULL = role default, 0 = no limit)
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND COLUMN_NAME = 'approval_limit') = 0,
+  'ALTER TABLE users ADD COLUMN approval_limit DECIMAL(15,2) DEFAULT NULL COMMENT ''วงเงินอนุมัติสูงสุด (NULL = ตามบทบาท, 0 = ไม่จำกัด)''', 'DO 0');
+PREPARE stmt FROM @ddl;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
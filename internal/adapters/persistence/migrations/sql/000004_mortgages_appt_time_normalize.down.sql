@@ -0,0 +1,2 @@
+-- Padding is not undone, both forms are valid times
+DO 0;
@@ -0,0 +1,4 @@
+-- Zero-pad appt_time values saved before validation ("9:30" -> "09:30") so they sort as strings
+-- Values that are not H:MM / HH:MM are left for staff to fix
+UPDATE mortgages SET appt_time = TRIM(appt_time) WHERE appt_time <> TRIM(appt_time);
+UPDATE mortgages SET appt_time = CONCAT('0', appt_time) WHERE appt_time REGEXP '^[0-9]:[0-5][0-9]$';
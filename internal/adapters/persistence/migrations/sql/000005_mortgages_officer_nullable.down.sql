@@ -0,0 +1,3 @@
+-- Pooled cases go back to their creator so the column can be NOT NULL again
+UPDATE mortgages SET officer_id = user_id WHERE officer_id IS NULL;
+ALTER TABLE mortgages MODIFY officer_id BIGINT UNSIGNED NOT NULL;
@@ -0,0 +1,2 @@
+-- A case waiting in the intake pool has no officer (officer_id NULL)
+ALTER TABLE mortgages MODIFY officer_id BIGINT UNSIGNED NULL;
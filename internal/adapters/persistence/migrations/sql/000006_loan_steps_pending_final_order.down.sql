@@ -0,0 +1,4 @@
+SET @approved_order = (SELECT step_order FROM loan_steps WHERE code = 'APPROVED' LIMIT 1);
+UPDATE loan_steps SET step_order = 4 WHERE code = 'PENDING_FINAL' AND step_order = 5;
+UPDATE loan_steps SET step_order = step_order - 1
+  WHERE code IN ('APPROVED', 'REJECTED', 'CANCELLED') AND @approved_order = 6;
@@ -0,0 +1,6 @@
+-- PENDING_FINAL sits between PENDING_APPROVE (4) and APPROVED, so the final steps move down one
+-- Only applied to the default order, steps an admin renumbered are left alone
+SET @approved_order = (SELECT step_order FROM loan_steps WHERE code = 'APPROVED' LIMIT 1);
+UPDATE loan_steps SET step_order = step_order + 1
+  WHERE code IN ('APPROVED', 'REJECTED', 'CANCELLED') AND @approved_order = 5;
+UPDATE loan_steps SET step_order = 5 WHERE code = 'PENDING_FINAL' AND step_order = 4;
@@ -12,13 +12,29 @@ import (
 
 // User represents users table
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	MembNo    string         `gorm:"uniqueIndex;size:20;not null" json:"memb_no"`
-	Username  string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
-	Email     string         `gorm:"uniqueIndex;size:100;not null" json:"email"`
-	Password  string         `gorm:"size:255;not null" json:"-"`
-	Role      string         `gorm:"size:20;default:'USER'" json:"role"`
-	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	MembNo   string `gorm:"uniqueIndex;size:20;not null" json:"memb_no"`
+	Username string `gorm:"uniqueIndex;size:50;not null" json:"username"`
+	Email    string `gorm:"uniqueIndex;size:100;not null" json:"email"`
+	Password string `gorm:"size:255;not null" json:"-"`
+	Role     string `gorm:"size:20;default:'USER'" json:"role"`
+	IsActive bool   `gorm:"default:true" json:"is_active"`
+
+	// LINE / LIFF (ผูกบัญชี LINE + ผูกเครื่อง)
+	// columns and indexes are owned by migration 000001 (and 000002 for the unique line_user_id)
+	LineUserID      *string    `gorm:"-:migration" json:"-"`
+	LineDisplayName *string    `gorm:"-:migration" json:"line_display_name,omitempty"`
+	LinePictureURL  *string    `gorm:"-:migration" json:"line_picture_url,omitempty"`
+	LineLinkedAt    *time.Time `gorm:"-:migration" json:"line_linked_at,omitempty"`
+	DeviceID        *string    `gorm:"-:migration" json:"-"`
+	PhoneVerified   *string    `gorm:"-:migration" json:"-"`
+	NetworkType     *string    `gorm:"-:migration" json:"-"`
+	LastLogin       *time.Time `gorm:"-:migration" json:"last_login,omitempty"`
+
+	// ApprovalLimit caps the amount this user may approve (nil = role default, 0 = no limit)
+	// column owned by migration 000003
+	ApprovalLimit *float64 `gorm:"-:migration" json:"-"`
+
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -28,28 +44,49 @@ func (User) TableName() string {
 	return "users"
 }
 
+// IsLineLinked reports whether the user has linked a LINE account
+func (u *User) IsLineLinked() bool {
+	return u.LineUserID != nil && *u.LineUserID != ""
+}
+
 // UserResponse DTO
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	MembNo    string    `json:"memb_no"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	FullName  string    `json:"full_name,omitempty"`
-	DeptName  string    `json:"dept_name,omitempty"`
+	ID       uint   `json:"id"`
+	MembNo   string `json:"memb_no"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	IsActive bool   `json:"is_active"`
+	FullName string `json:"full_name,omitempty"`
+	DeptName string `json:"dept_name,omitempty"`
+
+	// LINE (ไม่ส่ง line_user_id / device_id ออกไป)
+	LineLinked      bool       `json:"line_linked"`
+	LineDisplayName *string    `json:"line_display_name,omitempty"`
+	LinePictureURL  *string    `json:"line_picture_url,omitempty"`
+	LineLinkedAt    *time.Time `json:"line_linked_at,omitempty"`
+	LastLogin       *time.Time `json:"last_login,omitempty"`
+
+	ApprovalLimit *float64 `json:"approval_limit,omitempty"` // nil = role default
+
 	CreatedAt time.Time `json:"created_at"`
 }
 
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		MembNo:    u.MembNo,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
+		ID:              u.ID,
+		MembNo:          u.MembNo,
+		Username:        u.Username,
+		Email:           u.Email,
+		Role:            u.Role,
+		IsActive:        u.IsActive,
+		LineLinked:      u.IsLineLinked(),
+		LineDisplayName: u.LineDisplayName,
+		LinePictureURL:  u.LinePictureURL,
+		LineLinkedAt:    u.LineLinkedAt,
+		LastLogin:       u.LastLogin,
+		ApprovalLimit:   u.ApprovalLimit,
+		CreatedAt:       u.CreatedAt,
 	}
 }
 
@@ -94,15 +131,21 @@ func (Flommast) TableName() string {
 
 // LoanType ประเภทเงินกู้ (Master)
 type LoanType struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Code         string         `gorm:"size:20;uniqueIndex;not null" json:"code"`
-	Name         string         `gorm:"size:100;not null" json:"name"`
-	Description  string         `gorm:"type:text" json:"description"`
-	InterestRate float64        `gorm:"type:decimal(5,2);not null" json:"interest_rate"`
-	IsActive     bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint    `gorm:"primaryKey" json:"id"`
+	Code         string  `gorm:"size:20;uniqueIndex;not null" json:"code"`
+	Name         string  `gorm:"size:100;not null" json:"name"`
+	Description  string  `gorm:"type:text" json:"description"`
+	InterestRate float64 `gorm:"type:decimal(5,2);not null" json:"interest_rate"`
+	MinAmount    float64 `gorm:"type:decimal(15,2);default:0" json:"min_amount"` // 0 = ไม่จำกัด
+	MaxAmount    float64 `gorm:"type:decimal(15,2);default:0" json:"max_amount"` // 0 = ไม่จำกัด
+	// RequiresGuarantor บังคับให้มีผู้ค้ำประกันตอนสร้างคำขอ
+	RequiresGuarantor bool `gorm:"default:false" json:"requires_guarantor"`
+	// RequiresDualApproval ให้เจ้าหน้าที่คนหนึ่งเสนออนุมัติ แล้วอีกคนยืนยัน (maker-checker)
+	RequiresDualApproval bool           `gorm:"default:false" json:"requires_dual_approval"`
+	IsActive             bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (LoanType) TableName() string {
@@ -134,6 +177,7 @@ type LoanDoc struct {
 	Code        string         `gorm:"size:20;uniqueIndex;not null" json:"code"`
 	Name        string         `gorm:"size:100;not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
+	IsRequired  bool           `gorm:"default:false" json:"is_required"` // ต้องส่งครบก่อนอนุมัติ
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
@@ -170,8 +214,8 @@ type Mortgage struct {
 	ID              uint           `gorm:"primaryKey" json:"id"`
 	ContractNo      *string        `gorm:"size:50;uniqueIndex" json:"contract_no"`
 	MembNo          string         `gorm:"size:20;not null;index" json:"memb_no"`
-	OfficerID       uint           `gorm:"not null" json:"officer_id"`
-	UserID          uint           `gorm:"not null" json:"user_id"`
+	OfficerID       *uint          `json:"officer_id"`              // ผู้รับผิดชอบ (เปลี่ยนได้, nil = รอมอบหมาย)
+	UserID          uint           `gorm:"not null" json:"user_id"` // ผู้สร้าง (ไม่เปลี่ยน)
 	Amount          float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
 	Collateral      string         `gorm:"type:text" json:"collateral"`
 	Purpose         string         `gorm:"type:text" json:"purpose"`
@@ -190,9 +234,20 @@ type Mortgage struct {
 	CurrentDocID *uint `json:"current_doc_id"` // FK to loan_docs (master) - เอกสารปัจจุบันที่ต้องส่ง
 
 	// Approval fields
-	ApprovedBy *uint      `json:"approved_by"`
-	ApprovedAt *time.Time `json:"approved_at"`
-	Remark     string     `gorm:"type:text" json:"remark"`
+	ApprovedBy     *uint      `json:"approved_by"`
+	ApprovedAt     *time.Time `gorm:"index" json:"approved_at"`
+	ApprovedAmount *float64   `gorm:"type:decimal(15,2)" json:"approved_amount"` // วงเงินที่อนุมัติ (nil = อนุมัติเต็มจำนวน Amount)
+	Remark         string     `gorm:"type:text" json:"remark"`
+
+	// Two-step approval: who recommended the case for final approval (nil = not recommended)
+	RecommendedBy *uint      `json:"recommended_by"`
+	RecommendedAt *time.Time `json:"recommended_at"`
+
+	// ClonedFromID is the case this one was resubmitted from (POST /mortgages/:id/clone)
+	ClonedFromID *uint `gorm:"index" json:"cloned_from_id"`
+
+	// Optimistic concurrency - เพิ่มขึ้นทุกครั้งที่ Update
+	Version uint `gorm:"not null;default:1" json:"version"`
 
 	// Timestamps
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
@@ -213,14 +268,34 @@ func (Mortgage) TableName() string {
 	return "mortgages"
 }
 
+// IsUnassigned reports whether the case is waiting in the intake pool (GET /mortgages/unassigned)
+func (m *Mortgage) IsUnassigned() bool {
+	return m.OfficerID == nil
+}
+
+// IsAssignedTo reports whether userID is the responsible officer
+func (m *Mortgage) IsAssignedTo(userID uint) bool {
+	return m.OfficerID != nil && *m.OfficerID == userID
+}
+
+// FinalAmount returns the approved amount, or the requested Amount when approved in full
+func (m *Mortgage) FinalAmount() float64 {
+	if m.ApprovedAmount != nil {
+		return *m.ApprovedAmount
+	}
+	return m.Amount
+}
+
 // MortgageResponse DTO
 type MortgageResponse struct {
 	ID              uint       `json:"id"`
 	ContractNo      *string    `json:"contract_no"`
 	MembNo          string     `json:"memb_no"`
 	MemberName      string     `json:"member_name,omitempty"`
-	OfficerID       uint       `json:"officer_id"`
+	OfficerID       *uint      `json:"officer_id"`
 	OfficerName     string     `json:"officer_name,omitempty"`
+	CreatorID       uint       `json:"creator_id"`
+	CreatorName     string     `json:"creator_name,omitempty"`
 	Amount          float64    `json:"amount"`
 	Collateral      string     `json:"collateral"`
 	Purpose         string     `json:"purpose"`
@@ -245,11 +320,21 @@ type MortgageResponse struct {
 	CurrentDoc     *LoanDoc `json:"current_doc,omitempty"`
 
 	// Approval info
-	ApprovedBy *uint      `json:"approved_by"`
-	ApprovedAt *time.Time `json:"approved_at"`
-	Remark     string     `json:"remark"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ApprovedBy     *uint      `json:"approved_by"`
+	ApprovedAt     *time.Time `json:"approved_at"`
+	ApprovedAmount *float64   `json:"approved_amount"`
+	Remark         string     `json:"remark"`
+	RecommendedBy  *uint      `json:"recommended_by,omitempty"`
+	RecommendedAt  *time.Time `json:"recommended_at,omitempty"`
+	ClonedFromID   *uint      `json:"cloned_from_id"`
+
+	// Formatted amounts (only when requested with ?amount_format=thb)
+	AmountText         string `json:"amount_text,omitempty"`
+	ApprovedAmountText string `json:"approved_amount_text,omitempty"`
+
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (m *Mortgage) ToResponse() *MortgageResponse {
@@ -258,6 +343,7 @@ func (m *Mortgage) ToResponse() *MortgageResponse {
 		ContractNo:      m.ContractNo,
 		MembNo:          m.MembNo,
 		OfficerID:       m.OfficerID,
+		CreatorID:       m.UserID,
 		Amount:          m.Amount,
 		Collateral:      m.Collateral,
 		Purpose:         m.Purpose,
@@ -271,7 +357,12 @@ func (m *Mortgage) ToResponse() *MortgageResponse {
 		CurrentDocID:    m.CurrentDocID,
 		ApprovedBy:      m.ApprovedBy,
 		ApprovedAt:      m.ApprovedAt,
+		ApprovedAmount:  m.ApprovedAmount,
 		Remark:          m.Remark,
+		RecommendedBy:   m.RecommendedBy,
+		RecommendedAt:   m.RecommendedAt,
+		ClonedFromID:    m.ClonedFromID,
+		Version:         m.Version,
 		CreatedAt:       m.CreatedAt,
 		UpdatedAt:       m.UpdatedAt,
 	}
@@ -284,6 +375,9 @@ func (m *Mortgage) ToResponse() *MortgageResponse {
 	if m.Officer != nil {
 		resp.OfficerName = m.Officer.Username
 	}
+	if m.Creator != nil {
+		resp.CreatorName = m.Creator.Username
+	}
 	if m.LoanType != nil {
 		resp.LoanTypeName = m.LoanType.Name
 	}
@@ -337,14 +431,224 @@ const (
 	TxTypeCreate        = "CREATE"
 	TxTypeUpdate        = "UPDATE"
 	TxTypeStatusChange  = "STATUS_CHANGE"
+	TxTypeAutoStep      = "AUTO_STEP" // ระบบเลื่อนขั้นอัตโนมัติ (ไม่ใช่เจ้าหน้าที่กดเอง)
 	TxTypeTypeChange    = "TYPE_CHANGE"
 	TxTypeDocCheck      = "DOC_CHECK"
+	TxTypeDocUncheck    = "DOC_UNCHECK" // ยกเลิกการรับเอกสาร (is_submitted = false)
 	TxTypeApptCreate    = "APPT_CREATE"
 	TxTypeApptComplete  = "APPT_COMPLETE"
 	TxTypeApptCancel    = "APPT_CANCEL"
+	TxTypeApptRemind    = "APPT_REMIND"
+	TxTypeApptAttach    = "APPT_ATTACH"
 	TxTypeApprove       = "APPROVE"
+	TxTypeRecommend     = "APPROVE_RECOMMEND" // เสนออนุมัติ รอผู้อนุมัติคนที่สองยืนยัน
 	TxTypeReject        = "REJECT"
 	TxTypeOfficerChange = "OFFICER_CHANGE"
+	TxTypeDelete        = "DELETE"
+	TxTypeRestore       = "RESTORE"
+)
+
+// TxTypes lists every transaction type (history filters are validated against it)
+var TxTypes = []string{
+	TxTypeCreate, TxTypeUpdate, TxTypeStatusChange, TxTypeAutoStep, TxTypeTypeChange, TxTypeDocCheck,
+	TxTypeDocUncheck, TxTypeApptCreate, TxTypeApptComplete, TxTypeApptCancel, TxTypeApptRemind, TxTypeApptAttach,
+	TxTypeApprove, TxTypeRecommend, TxTypeReject, TxTypeOfficerChange, TxTypeDelete, TxTypeRestore,
+}
+
+// Appointment Status (derived from the latest APPT_* transaction)
+const (
+	ApptStatusPending   = "PENDING"
+	ApptStatusCompleted = "COMPLETED"
+	ApptStatusCancelled = "CANCELLED"
+)
+
+// MortgageNote บันทึกภายในของเจ้าหน้าที่ (แยกจาก transaction remark)
+type MortgageNote struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	MortgageID uint      `gorm:"not null;index" json:"mortgage_id"`
+	AuthorID   uint      `gorm:"not null" json:"author_id"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	IsInternal bool      `gorm:"default:true" json:"is_internal"` // true = ไม่แสดงให้สมาชิกเห็น
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relations
+	Author *User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+}
+
+func (MortgageNote) TableName() string {
+	return "mortgage_notes"
+}
+
+// ApptAttachment ไฟล์แนบของนัดหมาย (เช่น เอกสารที่เซ็นแล้ว)
+type ApptAttachment struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	MortgageID  uint       `gorm:"not null;index:idx_appt_attachment" json:"mortgage_id"`
+	ApptID      uint       `gorm:"not null;index:idx_appt_attachment" json:"appt_id"` // FK to loan_appts (master)
+	ApptDate    *time.Time `gorm:"type:date" json:"appt_date"`                        // วันนัดตอนที่แนบไฟล์
+	FileName    string     `gorm:"size:255;not null" json:"file_name"`
+	StorageKey  string     `gorm:"size:500;not null" json:"-"`
+	ContentType string     `gorm:"size:100;not null" json:"content_type"`
+	Size        int64      `gorm:"not null" json:"size"`
+	UploadedBy  uint       `gorm:"not null" json:"uploaded_by"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relations
+	Uploader *User `gorm:"foreignKey:UploadedBy" json:"uploader,omitempty"`
+}
+
+func (ApptAttachment) TableName() string {
+	return "appt_attachments"
+}
+
+// ReminderLog บันทึกการส่งแจ้งเตือนนัดหมาย (กันส่งซ้ำ)
+type ReminderLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	MortgageID uint      `gorm:"not null;uniqueIndex:idx_reminder_unique" json:"mortgage_id"`
+	ApptDate   time.Time `gorm:"type:date;not null;uniqueIndex:idx_reminder_unique" json:"appt_date"`
+	Kind       string    `gorm:"size:20;not null;uniqueIndex:idx_reminder_unique" json:"kind"`
+	LineUserID string    `gorm:"size:50" json:"line_user_id"`
+	SentAt     time.Time `gorm:"autoCreateTime" json:"sent_at"`
+}
+
+func (ReminderLog) TableName() string {
+	return "reminder_logs"
+}
+
+// Reminder Kinds
+const (
+	ReminderKindEvening = "EVENING" // คืนก่อนวันนัด
+	ReminderKindMorning = "MORNING" // เช้าวันนัด
+)
+
+// NotificationPreference การตั้งค่าการรับแจ้งเตือน LINE ของสมาชิก
+// ถ้ายังไม่มี record ถือว่าเปิดรับทุกประเภท (ค่าเดิมก่อนมีการตั้งค่า)
+// ไม่ใส่ default:true เพราะ GORM จะข้ามค่า false ตอน insert
+type NotificationPreference struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	UserID       uint      `gorm:"not null;uniqueIndex" json:"-"`
+	Reminder     bool      `gorm:"not null" json:"reminder"`
+	StatusChange bool      `gorm:"not null" json:"status_change"`
+	Appointment  bool      `gorm:"not null" json:"appointment"`
+	Language     string    `gorm:"size:5" json:"language"` // th / en (ว่าง = ภาษาไทย)
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference returns the preference used when a user has not saved one
+func DefaultNotificationPreference(userID uint) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:       userID,
+		Reminder:     true,
+		StatusChange: true,
+		Appointment:  true,
+	}
+}
+
+// Allows reports whether the given notification kind is enabled
+func (p *NotificationPreference) Allows(kind string) bool {
+	switch kind {
+	case NotifyKindReminder:
+		return p.Reminder
+	case NotifyKindStatusChange:
+		return p.StatusChange
+	case NotifyKindAppointment:
+		return p.Appointment
+	}
+	return true
+}
+
+// Notification Kinds (member-facing LINE messages)
+const (
+	NotifyKindReminder     = "reminder"      // แจ้งเตือนก่อนวันนัด
+	NotifyKindStatusChange = "status_change" // เปลี่ยนสถานะ / อนุมัติ / ปฏิเสธ
+	NotifyKindAppointment  = "appointment"   // สร้างนัดหมายใหม่
+)
+
+// ============================================================
+// Webhooks (outbound events to external systems)
+// ============================================================
+
+// WebhookSubscription ปลายทางที่รับ event (เช่น core banking)
+type WebhookSubscription struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	URL         string         `gorm:"size:500;not null" json:"url"`
+	EventTypes  string         `gorm:"size:500;not null" json:"event_types"` // comma-separated, "*" = all
+	Secret      string         `gorm:"size:100;not null" json:"-"`
+	Description string         `gorm:"size:200" json:"description"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedBy   uint           `json:"created_by"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery การส่ง event แต่ละครั้ง (สำหรับ retry และตรวจสอบย้อนหลัง)
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;index" json:"subscription_id"`
+	EventType      string     `gorm:"size:50;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:20;not null;index" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	ResponseCode   int        `json:"response_code"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Webhook Delivery Status
+const (
+	WebhookStatusPending = "PENDING"
+	WebhookStatusSuccess = "SUCCESS"
+	WebhookStatusFailed  = "FAILED"
+)
+
+// Webhook Event Types
+const (
+	WebhookEventMortgageApproved      = "mortgage.approved"
+	WebhookEventMortgageRejected      = "mortgage.rejected"
+	WebhookEventMortgageStatusChanged = "mortgage.status_changed"
+)
+
+// ============================================================
+// Audit Log (PDPA / compliance)
+// ============================================================
+
+// AuditLog บันทึกการกระทำกับข้อมูลส่วนบุคคลที่ต้องตรวจสอบย้อนหลังได้
+type AuditLog struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Action       string    `gorm:"size:50;not null;index" json:"action"`
+	ActorID      uint      `gorm:"not null;index" json:"actor_id"` // ผู้กระทำ
+	TargetUserID *uint     `gorm:"index" json:"target_user_id"`
+	TargetMembNo string    `gorm:"size:20;index" json:"target_memb_no"`
+	Detail       string    `gorm:"type:text" json:"detail"`
+	IPAddress    string    `gorm:"size:50" json:"ip_address"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// Audit Actions
+const (
+	AuditActionMemberExport  = "MEMBER_EXPORT"    // ส่งออกข้อมูลส่วนบุคคลของสมาชิก
+	AuditActionUserAnonymize = "USER_ANONYMIZE"   // ลบข้อมูลส่วนบุคคลตามคำขอ (PDPA erasure)
+	AuditActionLINEUnbind    = "LINE_UNBIND"      // ยกเลิกการผูก LINE ที่ซ้ำกับบัญชีอื่น
+	AuditActionImpersonate   = "USER_IMPERSONATE" // admin ออก token เข้าดูระบบแทนสมาชิก (support)
 )
 
 // ============================================================
@@ -366,6 +670,15 @@ func AutoMigrate(db *gorm.DB) error {
 		// Phase 4: Main Tables
 		&Mortgage{},
 		&Transaction{},
+		&MortgageNote{},
+		&ApptAttachment{},
+		&ReminderLog{},
+		&NotificationPreference{},
+		// Webhooks
+		&WebhookSubscription{},
+		&WebhookDelivery{},
+		// Audit
+		&AuditLog{},
 		// ลบ _currents tables ออกแล้ว!
 	)
 }
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+)
+
+// ApptAttachmentRepository handles appointment attachment data access
+type ApptAttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewApptAttachmentRepository creates a new appointment attachment repository
+func NewApptAttachmentRepository(db *gorm.DB) *ApptAttachmentRepository {
+	return &ApptAttachmentRepository{db: db}
+}
+
+// Create creates a new attachment
+func (r *ApptAttachmentRepository) Create(ctx context.Context, attachment *models.ApptAttachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+// GetByID gets an attachment by ID
+func (r *ApptAttachmentRepository) GetByID(ctx context.Context, id uint) (*models.ApptAttachment, error) {
+	var attachment models.ApptAttachment
+	err := r.db.WithContext(ctx).First(&attachment, id).Error
+	return &attachment, err
+}
+
+// ListByAppt lists attachments of a mortgage appointment, newest first
+func (r *ApptAttachmentRepository) ListByAppt(ctx context.Context, mortgageID, apptID uint) ([]*models.ApptAttachment, error) {
+	var attachments []*models.ApptAttachment
+	err := r.db.WithContext(ctx).
+		Preload("Uploader").
+		Where("mortgage_id = ? AND appt_id = ?", mortgageID, apptID).
+		Order("created_at DESC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// Delete permanently removes an attachment record
+func (r *ApptAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.ApptAttachment{}, id).Error
+}
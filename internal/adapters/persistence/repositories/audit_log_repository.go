@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository handles audit log data access
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create records an audit entry
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
@@ -19,6 +19,7 @@ type UserRepository interface {
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	ExistsByMembNo(ctx context.Context, membNo string) (bool, error)
+	IsActive(ctx context.Context, id uint) (bool, error)
 }
 
 // RefreshTokenRepository defines refresh token repository interface
@@ -163,6 +163,13 @@ func (r *LoanDocRepository) List(ctx context.Context) ([]*models.LoanDoc, error)
 	return loanDocs, err
 }
 
+// ListRequired lists active loan docs that must be submitted before approval
+func (r *LoanDocRepository) ListRequired(ctx context.Context) ([]*models.LoanDoc, error) {
+	var loanDocs []*models.LoanDoc
+	err := r.db.WithContext(ctx).Where("is_active = ? AND is_required = ?", true, true).Find(&loanDocs).Error
+	return loanDocs, err
+}
+
 // ListAll lists all loan docs including inactive
 func (r *LoanDocRepository) ListAll(ctx context.Context) ([]*models.LoanDoc, error) {
 	var loanDocs []*models.LoanDoc
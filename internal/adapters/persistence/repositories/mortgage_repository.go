@@ -2,12 +2,30 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict is returned when a mortgage was modified since it was read
+var ErrVersionConflict = errors.New("mortgage version conflict")
+
+// ErrDuplicateKey is returned when an update hits a unique index (e.g. contract_no)
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// mysqlErrDuplicateEntry is MySQL's ER_DUP_ENTRY
+const mysqlErrDuplicateEntry = 1062
+
+// IsDuplicateKey reports whether err is a unique index violation
+func IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
+
 // MortgageRepository handles mortgage data access
 type MortgageRepository struct {
 	db *gorm.DB
@@ -38,17 +56,24 @@ func (r *MortgageRepository) GetByID(ctx context.Context, id uint) (*models.Mort
 	return &mortgage, err
 }
 
-// GetByMembNo gets mortgages by member number
-func (r *MortgageRepository) GetByMembNo(ctx context.Context, membNo string) ([]*models.Mortgage, error) {
+// GetByMembNo gets mortgages by member number with pagination (newest first)
+func (r *MortgageRepository) GetByMembNo(ctx context.Context, membNo string, offset, limit int) ([]*models.Mortgage, int64, error) {
 	var mortgages []*models.Mortgage
+	var total int64
+
+	r.db.WithContext(ctx).Model(&models.Mortgage{}).Where("memb_no = ?", membNo).Count(&total)
+
 	err := r.db.WithContext(ctx).
 		Preload("LoanType").
 		Preload("CurrentStep").
 		Preload("CurrentAppt").
 		Where("memb_no = ?", membNo).
 		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
 		Find(&mortgages).Error
-	return mortgages, err
+
+	return mortgages, total, err
 }
 
 // List lists all mortgages with pagination
@@ -60,6 +85,7 @@ func (r *MortgageRepository) List(ctx context.Context, offset, limit int) ([]*mo
 
 	err := r.db.WithContext(ctx).
 		Preload("Officer").
+		Preload("Creator").
 		Preload("LoanType").
 		Preload("CurrentStep").
 		Preload("CurrentAppt").
@@ -91,6 +117,28 @@ func (r *MortgageRepository) ListByOfficer(ctx context.Context, officerID uint,
 	return mortgages, total, err
 }
 
+// ListByOfficerAndStep lists mortgages of an officer at a given step
+func (r *MortgageRepository) ListByOfficerAndStep(ctx context.Context, officerID, stepID uint, offset, limit int) ([]*models.Mortgage, int64, error) {
+	var mortgages []*models.Mortgage
+	var total int64
+
+	r.db.WithContext(ctx).Model(&models.Mortgage{}).
+		Where("officer_id = ? AND current_step_id = ?", officerID, stepID).
+		Count(&total)
+
+	err := r.db.WithContext(ctx).
+		Preload("LoanType").
+		Preload("CurrentStep").
+		Preload("CurrentAppt").
+		Where("officer_id = ? AND current_step_id = ?", officerID, stepID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&mortgages).Error
+
+	return mortgages, total, err
+}
+
 // ListByStep lists mortgages by step
 func (r *MortgageRepository) ListByStep(ctx context.Context, stepID uint, offset, limit int) ([]*models.Mortgage, int64, error) {
 	var mortgages []*models.Mortgage
@@ -100,6 +148,7 @@ func (r *MortgageRepository) ListByStep(ctx context.Context, stepID uint, offset
 
 	err := r.db.WithContext(ctx).
 		Preload("Officer").
+		Preload("Creator").
 		Preload("LoanType").
 		Preload("CurrentStep").
 		Preload("CurrentAppt").
@@ -112,9 +161,117 @@ func (r *MortgageRepository) ListByStep(ctx context.Context, stepID uint, offset
 	return mortgages, total, err
 }
 
-// Update updates a mortgage
+// MortgageListFilter combines optional list filters (nil = not filtered)
+type MortgageListFilter struct {
+	OfficerID *uint
+	StepID    *uint
+
+	// Approval window: approved_at >= ApprovedFrom AND approved_at < ApprovedTo
+	ApprovedFrom *time.Time
+	ApprovedTo   *time.Time
+}
+
+// ListFiltered lists mortgages matching all set filters, newest first
+func (r *MortgageRepository) ListFiltered(ctx context.Context, filter MortgageListFilter, offset, limit int) ([]*models.Mortgage, int64, error) {
+	var mortgages []*models.Mortgage
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Mortgage{})
+	if filter.OfficerID != nil {
+		query = query.Where("officer_id = ?", *filter.OfficerID)
+	}
+	if filter.StepID != nil {
+		query = query.Where("current_step_id = ?", *filter.StepID)
+	}
+	if filter.ApprovedFrom != nil {
+		query = query.Where("approved_at >= ?", *filter.ApprovedFrom)
+	}
+	if filter.ApprovedTo != nil {
+		query = query.Where("approved_at < ?", *filter.ApprovedTo)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Officer").
+		Preload("Creator").
+		Preload("LoanType").
+		Preload("CurrentStep").
+		Preload("CurrentAppt").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&mortgages).Error
+
+	return mortgages, total, err
+}
+
+// ListUnassigned lists mortgages in the intake pool (no officer yet), oldest first
+func (r *MortgageRepository) ListUnassigned(ctx context.Context, offset, limit int) ([]*models.Mortgage, int64, error) {
+	var mortgages []*models.Mortgage
+	var total int64
+
+	r.db.WithContext(ctx).Model(&models.Mortgage{}).Where("officer_id IS NULL").Count(&total)
+
+	err := r.db.WithContext(ctx).
+		Preload("Creator").
+		Preload("LoanType").
+		Preload("CurrentStep").
+		Where("officer_id IS NULL").
+		Order("created_at ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&mortgages).Error
+
+	return mortgages, total, err
+}
+
+// ListByGuarantor lists mortgages guaranteed by a member, newest first
+func (r *MortgageRepository) ListByGuarantor(ctx context.Context, membNo string) ([]*models.Mortgage, error) {
+	var mortgages []*models.Mortgage
+	err := r.db.WithContext(ctx).
+		Preload("Officer").
+		Preload("LoanType").
+		Preload("CurrentStep").
+		Where("guarantor_memb_no = ?", membNo).
+		Order("created_at DESC").
+		Find(&mortgages).Error
+	return mortgages, err
+}
+
+// ListByApptDate lists mortgages with an appointment on the given date (YYYY-MM-DD)
+// Optionally filtered by officer, sorted by appointment time
+func (r *MortgageRepository) ListByApptDate(ctx context.Context, date string, officerID *uint) ([]*models.Mortgage, error) {
+	var mortgages []*models.Mortgage
+	query := r.db.WithContext(ctx).
+		Preload("Officer").
+		Preload("Creator").
+		Preload("CurrentAppt").
+		Where("DATE(appt_date) = ?", date)
+
+	if officerID != nil {
+		query = query.Where("officer_id = ?", *officerID)
+	}
+
+	err := query.Order("appt_time ASC, id ASC").Find(&mortgages).Error
+	return mortgages, err
+}
+
+// ExistsByContractNo checks if a contract number is used by another mortgage
+// Includes soft-deleted rows because the unique index still covers them
+func (r *MortgageRepository) ExistsByContractNo(ctx context.Context, contractNo string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Mortgage{}).
+		Where("contract_no = ? AND id <> ?", contractNo, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a mortgage if its version still matches, then bumps the version
 func (r *MortgageRepository) Update(ctx context.Context, mortgage *models.Mortgage) error {
-	return r.db.WithContext(ctx).Model(&models.Mortgage{}).Where("id = ?", mortgage.ID).Updates(map[string]interface{}{
+	result := r.db.WithContext(ctx).Model(&models.Mortgage{}).Where("id = ? AND version = ?", mortgage.ID, mortgage.Version).Updates(map[string]interface{}{
 		"contract_no":       mortgage.ContractNo,
 		"officer_id":        mortgage.OfficerID,
 		"amount":            mortgage.Amount,
@@ -131,8 +288,24 @@ func (r *MortgageRepository) Update(ctx context.Context, mortgage *models.Mortga
 		"appt_location":     mortgage.ApptLocation,
 		"approved_by":       mortgage.ApprovedBy,
 		"approved_at":       mortgage.ApprovedAt,
+		"approved_amount":   mortgage.ApprovedAmount,
+		"recommended_by":    mortgage.RecommendedBy,
+		"recommended_at":    mortgage.RecommendedAt,
 		"remark":            mortgage.Remark,
-	}).Error
+		"version":           gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		if IsDuplicateKey(result.Error) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	mortgage.Version++
+	return nil
 }
 
 // Delete soft deletes a mortgage
@@ -140,6 +313,53 @@ func (r *MortgageRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.Mortgage{}, id).Error
 }
 
+// ListTrashed lists soft-deleted mortgages, most recently deleted first
+func (r *MortgageRepository) ListTrashed(ctx context.Context, offset, limit int) ([]*models.Mortgage, int64, error) {
+	var mortgages []*models.Mortgage
+	var total int64
+
+	r.db.WithContext(ctx).Unscoped().Model(&models.Mortgage{}).Where("deleted_at IS NOT NULL").Count(&total)
+
+	err := r.db.WithContext(ctx).Unscoped().
+		Preload("Officer").
+		Preload("Creator").
+		Preload("LoanType").
+		Preload("CurrentStep").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&mortgages).Error
+
+	return mortgages, total, err
+}
+
+// GetTrashedByID gets a soft-deleted mortgage by ID
+func (r *MortgageRepository) GetTrashedByID(ctx context.Context, id uint) (*models.Mortgage, error) {
+	var mortgage models.Mortgage
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&mortgage, id).Error
+	return &mortgage, err
+}
+
+// Restore clears deleted_at of a soft-deleted mortgage and bumps the version
+func (r *MortgageRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Mortgage{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // TransactionRepository handles transaction data access
 type TransactionRepository struct {
 	db *gorm.DB
@@ -155,15 +375,102 @@ func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transacti
 	return r.db.WithContext(ctx).Create(tx).Error
 }
 
-// GetByMortgageID gets transactions by mortgage ID (History)
-func (r *TransactionRepository) GetByMortgageID(ctx context.Context, mortgageID uint) ([]*models.Transaction, error) {
+// GetByMortgageID gets transactions by mortgage ID (History), optionally only the given types
+func (r *TransactionRepository) GetByMortgageID(ctx context.Context, mortgageID uint, types ...string) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Preload("Performer").
 		Preload("FromStep").
 		Preload("ToStep").
-		Where("mortgage_id = ?", mortgageID).
-		Order("created_at DESC").
-		Find(&transactions).Error
+		Where("mortgage_id = ?", mortgageID)
+	if len(types) > 0 {
+		query = query.Where("transaction_type IN ?", types)
+	}
+	err := query.Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
+
+// ListByMembNo lists transactions of the given types across a member's mortgages, newest first
+// Transactions of deleted mortgages are left out
+func (r *TransactionRepository) ListByMembNo(ctx context.Context, membNo string, types []string, offset, limit int) ([]*models.Transaction, int64, error) {
+	var transactions []*models.Transaction
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Joins("JOIN mortgages ON mortgages.id = transactions.mortgage_id AND mortgages.deleted_at IS NULL").
+		Where("mortgages.memb_no = ? AND transactions.transaction_type IN ?", membNo, types)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("ToStep").
+		Order("transactions.created_at DESC, transactions.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&transactions).Error
+
+	return transactions, total, err
+}
+
+// GetDocSubmissions gets, for each mortgage, the documents currently marked submitted and when.
+// A document's state is decided by its latest DOC_CHECK / DOC_UNCHECK transaction
+func (r *TransactionRepository) GetDocSubmissions(ctx context.Context, mortgageIDs []uint) (map[uint]map[uint]time.Time, error) {
+	submissions := make(map[uint]map[uint]time.Time)
+	if len(mortgageIDs) == 0 {
+		return submissions, nil
+	}
+
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).
+		Select("id", "mortgage_id", "transaction_type", "to_doc_id", "created_at").
+		Where("mortgage_id IN ? AND transaction_type IN ? AND to_doc_id IS NOT NULL",
+			mortgageIDs, []string{models.TxTypeDocCheck, models.TxTypeDocUncheck}).
+		Order("id DESC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[[2]uint]bool)
+	for _, tx := range transactions {
+		key := [2]uint{tx.MortgageID, *tx.ToDocID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if tx.TransactionType != models.TxTypeDocCheck {
+			continue
+		}
+		if submissions[tx.MortgageID] == nil {
+			submissions[tx.MortgageID] = make(map[uint]time.Time)
+		}
+		submissions[tx.MortgageID][*tx.ToDocID] = tx.CreatedAt
+	}
+	return submissions, nil
+}
+
+// GetLatestByTypes gets the latest transaction of the given types for each mortgage
+func (r *TransactionRepository) GetLatestByTypes(ctx context.Context, mortgageIDs []uint, types []string) (map[uint]*models.Transaction, error) {
+	latest := make(map[uint]*models.Transaction)
+	if len(mortgageIDs) == 0 {
+		return latest, nil
+	}
+
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).
+		Where("mortgage_id IN ? AND transaction_type IN ?", mortgageIDs, types).
+		Order("id DESC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range transactions {
+		if _, ok := latest[tx.MortgageID]; !ok {
+			latest[tx.MortgageID] = tx
+		}
+	}
+	return latest, nil
+}
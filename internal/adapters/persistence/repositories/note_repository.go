@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+)
+
+// MortgageNoteRepository handles mortgage note data access
+type MortgageNoteRepository struct {
+	db *gorm.DB
+}
+
+// NewMortgageNoteRepository creates a new mortgage note repository
+func NewMortgageNoteRepository(db *gorm.DB) *MortgageNoteRepository {
+	return &MortgageNoteRepository{db: db}
+}
+
+// Create creates a new note
+func (r *MortgageNoteRepository) Create(ctx context.Context, note *models.MortgageNote) error {
+	return r.db.WithContext(ctx).Create(note).Error
+}
+
+// ListByMortgageID lists notes of a mortgage, newest first
+// Internal notes are excluded unless includeInternal is true
+func (r *MortgageNoteRepository) ListByMortgageID(ctx context.Context, mortgageID uint, includeInternal bool) ([]*models.MortgageNote, error) {
+	var notes []*models.MortgageNote
+	query := r.db.WithContext(ctx).
+		Preload("Author").
+		Where("mortgage_id = ?", mortgageID)
+
+	if !includeInternal {
+		query = query.Where("is_internal = ?", false)
+	}
+
+	err := query.Order("created_at DESC").Find(&notes).Error
+	return notes, err
+}
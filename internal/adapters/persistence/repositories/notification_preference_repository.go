@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreferenceRepository handles notification preference data access
+type NotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// GetByUserID gets a user's preference, or the all-on default if none is saved
+func (r *NotificationPreferenceRepository) GetByUserID(ctx context.Context, userID uint) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Upsert saves a user's preference
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, pref *models.NotificationPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reminder", "status_change", "appointment", "language", "updated_at"}),
+	}).Create(pref).Error
+}
+
+// GetByMembNo gets the preference of the user linked to a member number
+// Members without a user account (or without a saved preference) get the all-on default
+func (r *NotificationPreferenceRepository) GetByMembNo(ctx context.Context, membNo string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = notification_preferences.user_id AND users.deleted_at IS NULL").
+		Where("users.memb_no = ?", membNo).
+		First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreference(0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
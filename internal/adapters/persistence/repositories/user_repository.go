@@ -69,8 +69,21 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 }
 
 // Delete soft deletes a user
+// The LINE binding is released first so the LINE account can register again
+// (line_user_id is unique across all rows, including soft-deleted ones)
 func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"line_user_id":      nil,
+				"line_display_name": nil,
+				"line_picture_url":  nil,
+				"line_linked_at":    nil,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
 }
 
 // List lists users with pagination
@@ -111,3 +124,10 @@ func (r *userRepository) ExistsByMembNo(ctx context.Context, membNo string) (boo
 	err := r.db.WithContext(ctx).Model(&models.User{}).Where("memb_no = ?", membNo).Count(&count).Error
 	return count > 0, err
 }
+
+// IsActive reports whether a user exists, is active and is not deleted (anonymized users are both)
+func (r *userRepository) IsActive(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ? AND is_active = ?", id, true).Count(&count).Error
+	return count > 0, err
+}
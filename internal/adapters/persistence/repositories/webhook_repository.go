@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository handles webhook subscription and delivery data access
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create creates a new subscription
+func (r *WebhookRepository) Create(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(sub).Error
+}
+
+// GetByID gets a subscription by ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := r.db.WithContext(ctx).First(&sub, id).Error
+	return &sub, err
+}
+
+// ListAll lists all subscriptions
+func (r *WebhookRepository) ListAll(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	var subs []*models.WebhookSubscription
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&subs).Error
+	return subs, err
+}
+
+// ListActive lists active subscriptions
+func (r *WebhookRepository) ListActive(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	var subs []*models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Find(&subs).Error
+	return subs, err
+}
+
+// Update updates a subscription
+func (r *WebhookRepository) Update(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(sub).Error
+}
+
+// Delete soft deletes a subscription
+func (r *WebhookRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.WebhookSubscription{}, id).Error
+}
+
+// CreateDelivery creates a delivery record
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+// UpdateDelivery updates a delivery record
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+// ListDueDeliveries lists pending deliveries whose next attempt is due
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookStatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ListDeliveries lists recent deliveries of a subscription
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
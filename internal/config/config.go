@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -12,11 +13,26 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	AppMode  string
-	Port     string
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Cookie   CookieConfig
+	AppMode   string
+	Port      string
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Cookie    CookieConfig
+	RateLimit RateLimitConfig
+	Compress  CompressConfig
+	Upload    UploadConfig
+	PDF       PDFConfig
+	Mortgage  MortgageConfig
+
+	Maintenance MaintenanceConfig
+	Log         LogConfig
+	SMTP        SMTPConfig
+
+	// MoneyRounding is the rounding mode for amounts: half_up (default) / half_even
+	MoneyRounding string
+
+	// SeedOnStartup seeds master data when the server boots (use cmd/seed when false)
+	SeedOnStartup bool
 }
 
 // DatabaseConfig holds database configuration
@@ -34,6 +50,44 @@ type JWTConfig struct {
 	RefreshSecret    string
 	AccessTokenMins  int
 	RefreshTokenDays int
+
+	// LINE Login / LIFF sessions (ยาวกว่า web login เพราะเปิดจากมือถือ)
+	LineAccessTokenMins  int
+	LineRefreshTokenDays int
+
+	// ImpersonationTokenMins - admin support tokens (POST /admin/users/:id/impersonate)
+	ImpersonationTokenMins int
+
+	// RoleExpiry overrides the lifetimes above per role (ADMIN / OFFICER / USER)
+	RoleExpiry map[string]RoleTokenExpiry
+}
+
+// RoleTokenExpiry holds per-role token lifetimes (0 = use the global value)
+type RoleTokenExpiry struct {
+	AccessTokenMins  int
+	RefreshTokenDays int
+}
+
+// TokenExpiry returns the access token minutes and refresh token days of a web (password) login for role
+func (c JWTConfig) TokenExpiry(role string) (accessMins, refreshDays int) {
+	return c.roleExpiry(role, c.AccessTokenMins, c.RefreshTokenDays)
+}
+
+// LineTokenExpiry returns the access token minutes and refresh token days of a LINE Login / LIFF session for role
+func (c JWTConfig) LineTokenExpiry(role string) (accessMins, refreshDays int) {
+	return c.roleExpiry(role, c.LineAccessTokenMins, c.LineRefreshTokenDays)
+}
+
+func (c JWTConfig) roleExpiry(role string, accessMins, refreshDays int) (int, int) {
+	if e, ok := c.RoleExpiry[role]; ok {
+		if e.AccessTokenMins > 0 {
+			accessMins = e.AccessTokenMins
+		}
+		if e.RefreshTokenDays > 0 {
+			refreshDays = e.RefreshTokenDays
+		}
+	}
+	return accessMins, refreshDays
 }
 
 // CookieConfig holds cookie configuration (for Phase 2)
@@ -43,6 +97,105 @@ type CookieConfig struct {
 	Domain   string
 }
 
+// RateLimitConfig holds per-user rate limits for write endpoints
+type RateLimitConfig struct {
+	WriteMax        int
+	WriteWindowSecs int
+}
+
+// CompressConfig holds response compression settings
+type CompressConfig struct {
+	Level    string // off / speed / default / best
+	MinBytes int    // responses smaller than this are sent uncompressed
+}
+
+// UploadConfig holds file upload settings
+type UploadConfig struct {
+	Dir          string
+	MaxBytes     int64
+	AllowedTypes []string
+
+	// Driver selects where files are stored: local (Dir) / s3 (S3 or MinIO bucket)
+	Driver string
+	S3     S3Config
+
+	// URLExpiryMins is how long a signed download link stays valid (s3 only)
+	URLExpiryMins int
+
+	// Scanner checks uploads before they are stored: off (default) / clamav / http
+	Scanner         string
+	ScannerAddr     string // clamd host:port or scan service URL
+	ScanTimeoutSecs int
+}
+
+// S3Config holds S3 / MinIO bucket settings (STORAGE_DRIVER=s3)
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// PDFConfig holds settings for generated PDF documents
+// Thai text needs a TTF font with Thai glyphs (e.g. Sarabun / Noto Sans Thai)
+type PDFConfig struct {
+	CoopName     string
+	FontPath     string
+	BoldFontPath string
+}
+
+// MortgageConfig holds mortgage workflow rules
+type MortgageConfig struct {
+	// ContractNoPattern is the regex a contract number must match on approve
+	ContractNoPattern string
+
+	// RequireDocsOnApprove blocks approval until all required documents are checked (off by default)
+	RequireDocsOnApprove bool
+
+	// Auto-advance when the last required document is checked (step codes, both empty = off)
+	AutoAdvanceFromStep string
+	AutoAdvanceToStep   string
+
+	// OfficerApprovalLimit is the largest amount an OFFICER may approve (0 = no limit)
+	// Users can get their own limit; ADMIN is never limited
+	OfficerApprovalLimit float64
+
+	// DualApprovalThreshold sends cases of this amount or more through two-step approval
+	// (0 = off; loan types can also require it with requires_dual_approval)
+	DualApprovalThreshold float64
+
+	// High-value alert: new cases of HighValueAlertAmount or more are pushed to a staff LINE group
+	// (either empty = off; the LINE Messaging bot must be a member of the group)
+	HighValueAlertAmount  float64
+	HighValueAlertGroupID string
+}
+
+// SMTPConfig holds outgoing mail settings for member emails (empty Host = email off)
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// MaintenanceConfig holds maintenance mode settings (blocks writes, reads stay available)
+type MaintenanceConfig struct {
+	// Enabled starts the server in maintenance mode (can be toggled later by an admin)
+	Enabled bool
+
+	// RetryAfterSecs is sent in the Retry-After header of blocked requests
+	RetryAfterSecs int
+}
+
+// LogConfig holds structured logging settings
+type LogConfig struct {
+	Format string // json / text (default: json in prod, text in dev)
+	Level  string // debug / info / warn / error
+}
+
 // Global config instance
 var AppConfig *Config
 
@@ -61,11 +214,62 @@ func Load() (*Config, error) {
 
 	// Build config based on APP_MODE
 	config := &Config{
-		AppMode:  appMode,
-		Port:     getEnv("PORT", "3000"),
-		Database: loadDatabaseConfig(appMode),
-		JWT:      loadJWTConfig(appMode),
-		Cookie:   loadCookieConfig(appMode),
+		AppMode:   appMode,
+		Port:      getEnv("PORT", "3000"),
+		Database:  loadDatabaseConfig(appMode),
+		JWT:       loadJWTConfig(appMode),
+		Cookie:    loadCookieConfig(appMode),
+		RateLimit: loadRateLimitConfig(),
+		Compress:  loadCompressConfig(),
+		Upload:    loadUploadConfig(),
+		PDF: PDFConfig{
+			CoopName:     getEnv("COOP_NAME", "สหกรณ์ SPSC"),
+			FontPath:     getEnv("PDF_FONT_PATH", "/usr/share/fonts/noto/NotoSansThai-Regular.ttf"),
+			BoldFontPath: getEnv("PDF_FONT_BOLD_PATH", "/usr/share/fonts/noto/NotoSansThai-Bold.ttf"),
+		},
+	}
+	config.SeedOnStartup, _ = strconv.ParseBool(getEnv("SEED_ON_STARTUP", "true"))
+
+	config.Mortgage.ContractNoPattern = getEnv("CONTRACT_NO_PATTERN", `^[A-Za-z0-9][A-Za-z0-9/.\-]{0,49}$`)
+	if _, err := regexp.Compile(config.Mortgage.ContractNoPattern); err != nil {
+		return nil, fmt.Errorf("invalid CONTRACT_NO_PATTERN: %w", err)
+	}
+	config.Mortgage.RequireDocsOnApprove, _ = strconv.ParseBool(getEnv("REQUIRE_DOCS_ON_APPROVE", "false"))
+	config.Mortgage.AutoAdvanceFromStep = strings.TrimSpace(getEnv("AUTO_ADVANCE_DOCS_FROM", ""))
+	config.Mortgage.AutoAdvanceToStep = strings.TrimSpace(getEnv("AUTO_ADVANCE_DOCS_TO", ""))
+	if limit, err := strconv.ParseFloat(getEnv("OFFICER_APPROVAL_LIMIT", "0"), 64); err == nil && limit > 0 {
+		config.Mortgage.OfficerApprovalLimit = limit
+	}
+	if threshold, err := strconv.ParseFloat(getEnv("DUAL_APPROVAL_THRESHOLD", "0"), 64); err == nil && threshold > 0 {
+		config.Mortgage.DualApprovalThreshold = threshold
+	}
+	if amount, err := strconv.ParseFloat(getEnv("HIGH_VALUE_ALERT_AMOUNT", "0"), 64); err == nil && amount > 0 {
+		config.Mortgage.HighValueAlertAmount = amount
+	}
+	config.Mortgage.HighValueAlertGroupID = strings.TrimSpace(getEnv("HIGH_VALUE_ALERT_LINE_GROUP_ID", ""))
+
+	config.Maintenance.Enabled, _ = strconv.ParseBool(getEnv("MAINTENANCE_MODE", "false"))
+	config.Maintenance.RetryAfterSecs = getEnvInt("MAINTENANCE_RETRY_AFTER_SECONDS", 300)
+
+	defaultLogFormat := "text"
+	if appMode == "prod" {
+		defaultLogFormat = "json"
+	}
+	config.Log.Format = strings.ToLower(strings.TrimSpace(getEnv("LOG_FORMAT", defaultLogFormat)))
+	config.Log.Level = strings.ToLower(strings.TrimSpace(getEnv("LOG_LEVEL", "info")))
+
+	config.SMTP = SMTPConfig{
+		Host:     strings.TrimSpace(getEnv("SMTP_HOST", "")),
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", ""),
+	}
+
+	config.MoneyRounding = strings.ToLower(strings.TrimSpace(getEnv("MONEY_ROUNDING", "half_up")))
+	if config.MoneyRounding != "half_up" && config.MoneyRounding != "half_even" {
+		log.Printf("⚠️ Invalid MONEY_ROUNDING '%s', using 'half_up'", config.MoneyRounding)
+		config.MoneyRounding = "half_up"
 	}
 
 	// Set global config
@@ -98,14 +302,30 @@ func loadJWTConfig(mode string) JWTConfig {
 		prefix = "PROD_"
 	}
 
-	accessMins, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_MINUTES", "15"))
-	refreshDays, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_DAYS", "7"))
-
 	return JWTConfig{
-		Secret:           getEnv(prefix+"JWT_SECRET", "default_secret"),
-		RefreshSecret:    getEnv(prefix+"JWT_REFRESH_SECRET", "default_refresh_secret"),
-		AccessTokenMins:  accessMins,
-		RefreshTokenDays: refreshDays,
+		Secret:               getEnv(prefix+"JWT_SECRET", "default_secret"),
+		RefreshSecret:        getEnv(prefix+"JWT_REFRESH_SECRET", "default_refresh_secret"),
+		AccessTokenMins:      getEnvInt("ACCESS_TOKEN_MINUTES", 15),
+		RefreshTokenDays:     getEnvInt("REFRESH_TOKEN_DAYS", 7),
+		LineAccessTokenMins:  getEnvInt("ACCESS_TOKEN_EXPIRY", 1440),
+		LineRefreshTokenDays: getEnvInt("REFRESH_TOKEN_EXPIRY", 7),
+
+		ImpersonationTokenMins: getEnvInt("IMPERSONATION_TOKEN_MINUTES", 15),
+
+		// e.g. ADMIN_ACCESS_TOKEN_MINUTES=10, OFFICER_REFRESH_TOKEN_DAYS=1
+		RoleExpiry: map[string]RoleTokenExpiry{
+			"ADMIN":   loadRoleTokenExpiry("ADMIN"),
+			"OFFICER": loadRoleTokenExpiry("OFFICER"),
+			"USER":    loadRoleTokenExpiry("USER"),
+		},
+	}
+}
+
+// loadRoleTokenExpiry reads <ROLE>_ACCESS_TOKEN_MINUTES and <ROLE>_REFRESH_TOKEN_DAYS
+func loadRoleTokenExpiry(role string) RoleTokenExpiry {
+	return RoleTokenExpiry{
+		AccessTokenMins:  getEnvInt(role+"_ACCESS_TOKEN_MINUTES", 0),
+		RefreshTokenDays: getEnvInt(role+"_REFRESH_TOKEN_DAYS", 0),
 	}
 }
 
@@ -125,6 +345,94 @@ func loadCookieConfig(mode string) CookieConfig {
 	}
 }
 
+// loadRateLimitConfig loads write rate limit config
+func loadRateLimitConfig() RateLimitConfig {
+	writeMax, _ := strconv.Atoi(getEnv("WRITE_RATE_LIMIT_MAX", "30"))
+	writeWindow, _ := strconv.Atoi(getEnv("WRITE_RATE_LIMIT_WINDOW_SECONDS", "60"))
+
+	if writeMax <= 0 {
+		writeMax = 30
+	}
+	if writeWindow <= 0 {
+		writeWindow = 60
+	}
+
+	return RateLimitConfig{
+		WriteMax:        writeMax,
+		WriteWindowSecs: writeWindow,
+	}
+}
+
+// loadCompressConfig loads response compression config
+func loadCompressConfig() CompressConfig {
+	level := strings.ToLower(strings.TrimSpace(getEnv("COMPRESS_LEVEL", "speed")))
+	switch level {
+	case "off", "speed", "default", "best":
+	default:
+		log.Printf("⚠️ Invalid COMPRESS_LEVEL '%s', using 'speed'", level)
+		level = "speed"
+	}
+
+	minBytes, err := strconv.Atoi(getEnv("COMPRESS_MIN_BYTES", "1024"))
+	if err != nil || minBytes < 0 {
+		minBytes = 1024
+	}
+
+	return CompressConfig{
+		Level:    level,
+		MinBytes: minBytes,
+	}
+}
+
+// loadUploadConfig loads file upload config
+func loadUploadConfig() UploadConfig {
+	maxBytes, err := strconv.ParseInt(getEnv("UPLOAD_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil || maxBytes <= 0 {
+		maxBytes = 10 << 20 // 10 MB
+	}
+
+	var allowed []string
+	for _, t := range strings.Split(getEnv("UPLOAD_ALLOWED_TYPES", "application/pdf,image/jpeg,image/png"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			allowed = append(allowed, t)
+		}
+	}
+
+	driver := strings.ToLower(strings.TrimSpace(getEnv("STORAGE_DRIVER", "local")))
+	if driver != "local" && driver != "s3" {
+		log.Printf("⚠️ Invalid STORAGE_DRIVER '%s', using 'local'", driver)
+		driver = "local"
+	}
+	pathStyle, _ := strconv.ParseBool(getEnv("S3_PATH_STYLE", "false"))
+
+	return UploadConfig{
+		Dir:          getEnv("UPLOAD_DIR", "./uploads"),
+		MaxBytes:     maxBytes,
+		AllowedTypes: allowed,
+		Driver:       driver,
+		S3: S3Config{
+			Endpoint:  strings.TrimSpace(getEnv("S3_ENDPOINT", "")),
+			Region:    strings.TrimSpace(getEnv("S3_REGION", "ap-southeast-1")),
+			Bucket:    strings.TrimSpace(getEnv("S3_BUCKET", "")),
+			AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("S3_SECRET_KEY", ""),
+			PathStyle: pathStyle,
+		},
+		URLExpiryMins:   getEnvInt("S3_URL_EXPIRY_MINUTES", 15),
+		Scanner:         strings.ToLower(strings.TrimSpace(getEnv("FILE_SCANNER", "off"))),
+		ScannerAddr:     strings.TrimSpace(getEnv("FILE_SCANNER_ADDR", "")),
+		ScanTimeoutSecs: getEnvInt("FILE_SCANNER_TIMEOUT_SECONDS", 30),
+	}
+}
+
+// getEnvInt gets a positive integer environment variable with default value
+func getEnvInt(key string, defaultValue int) int {
+	if val, err := strconv.Atoi(getEnv(key, "")); err == nil && val > 0 {
+		return val
+	}
+	return defaultValue
+}
+
 // getEnv gets environment variable with default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
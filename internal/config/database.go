@@ -1,6 +1,7 @@
 package config
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -62,6 +63,16 @@ func ConnectDatabase(cfg *Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// OpenMigrationDB opens a separate connection for versioned migrations
+// golang-migrate runs each SQL file as one Exec, which needs multiStatements (off for the app connection)
+func OpenMigrationDB(cfg *Config) (*sql.DB, error) {
+	db, err := sql.Open("mysql", buildDSN(cfg.Database)+"&multiStatements=true")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration connection: %w", err)
+	}
+	return db, nil
+}
+
 // buildDSN returns the database connection string
 func buildDSN(d DatabaseConfig) string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
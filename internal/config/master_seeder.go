@@ -8,33 +8,74 @@ import (
 	"gorm.io/gorm"
 )
 
+// SeedResult reports what a seeding run created
+type SeedResult struct {
+	Created []string `json:"created"` // "<table>:<code>" of each inserted row
+	Skipped int      `json:"skipped"` // rows that already existed
+}
+
 // SeedMasterData seeds initial master data
+// Safe to call repeatedly: rows are inserted only if their code does not exist yet
 func SeedMasterData(db *gorm.DB) error {
-	// Seed Loan Types
-	if err := seedLoanTypes(db); err != nil {
+	result, err := RunMasterSeed(db)
+	if err != nil {
 		return err
 	}
 
+	log.Printf("✅ Master data seeded successfully (%d created, %d already present)", len(result.Created), result.Skipped)
+	return nil
+}
+
+// RunMasterSeed seeds all master tables and reports what was created
+func RunMasterSeed(db *gorm.DB) (*SeedResult, error) {
+	result := &SeedResult{}
+
+	// Seed Loan Types
+	if err := seedLoanTypes(db, result); err != nil {
+		return result, err
+	}
+
 	// Seed Loan Steps
-	if err := seedLoanSteps(db); err != nil {
-		return err
+	if err := seedLoanSteps(db, result); err != nil {
+		return result, err
 	}
 
 	// Seed Loan Docs
-	if err := seedLoanDocs(db); err != nil {
-		return err
+	if err := seedLoanDocs(db, result); err != nil {
+		return result, err
 	}
 
 	// Seed Loan Appts
-	if err := seedLoanAppts(db); err != nil {
-		return err
+	if err := seedLoanAppts(db, result); err != nil {
+		return result, err
 	}
 
-	log.Println("✅ Master data seeded successfully")
+	return result, nil
+}
+
+// seedByCode inserts rows whose code is not in the table yet
+// Soft-deleted rows count as existing so the unique code index is never violated
+func seedByCode[T any](db *gorm.DB, table string, rows []T, code func(*T) string, result *SeedResult) error {
+	for i := range rows {
+		var count int64
+		if err := db.Unscoped().Model(new(T)).Where("code = ?", code(&rows[i])).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			result.Skipped++
+			continue
+		}
+
+		if err := db.Create(&rows[i]).Error; err != nil {
+			return err
+		}
+		result.Created = append(result.Created, table+":"+code(&rows[i]))
+		log.Printf("   Created %s: %s", table, code(&rows[i]))
+	}
 	return nil
 }
 
-func seedLoanTypes(db *gorm.DB) error {
+func seedLoanTypes(db *gorm.DB, result *SeedResult) error {
 	loanTypes := []models.LoanType{
 		{
 			Code:         "NORMAL",
@@ -59,21 +100,10 @@ func seedLoanTypes(db *gorm.DB) error {
 		},
 	}
 
-	for _, lt := range loanTypes {
-		var existing models.LoanType
-		if err := db.Where("code = ?", lt.Code).First(&existing).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&lt).Error; err != nil {
-					return err
-				}
-				log.Printf("   Created loan_type: %s", lt.Name)
-			}
-		}
-	}
-	return nil
+	return seedByCode(db, "loan_type", loanTypes, func(lt *models.LoanType) string { return lt.Code }, result)
 }
 
-func seedLoanSteps(db *gorm.DB) error {
+func seedLoanSteps(db *gorm.DB, result *SeedResult) error {
 	loanSteps := []models.LoanStep{
 		{
 			Code:        "DRAFT",
@@ -111,11 +141,20 @@ func seedLoanSteps(db *gorm.DB) error {
 			IsFinal:     false,
 			IsActive:    true,
 		},
+		{
+			Code:        "PENDING_FINAL",
+			Name:        "รอยืนยันอนุมัติ",
+			Description: "เสนออนุมัติแล้ว รอผู้อนุมัติคนที่สองยืนยัน (อนุมัติสองขั้น)",
+			StepOrder:   5,
+			Color:       "#673AB7",
+			IsFinal:     false,
+			IsActive:    true,
+		},
 		{
 			Code:        "APPROVED",
 			Name:        "อนุมัติแล้ว",
 			Description: "คำขอได้รับการอนุมัติ",
-			StepOrder:   5,
+			StepOrder:   6,
 			Color:       "#4CAF50",
 			IsFinal:     true,
 			IsActive:    true,
@@ -124,7 +163,7 @@ func seedLoanSteps(db *gorm.DB) error {
 			Code:        "REJECTED",
 			Name:        "ปฏิเสธ",
 			Description: "คำขอถูกปฏิเสธ",
-			StepOrder:   6,
+			StepOrder:   7,
 			Color:       "#F44336",
 			IsFinal:     true,
 			IsActive:    true,
@@ -133,28 +172,17 @@ func seedLoanSteps(db *gorm.DB) error {
 			Code:        "CANCELLED",
 			Name:        "ยกเลิก",
 			Description: "คำขอถูกยกเลิก",
-			StepOrder:   7,
+			StepOrder:   8,
 			Color:       "#607D8B",
 			IsFinal:     true,
 			IsActive:    true,
 		},
 	}
 
-	for _, ls := range loanSteps {
-		var existing models.LoanStep
-		if err := db.Where("code = ?", ls.Code).First(&existing).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&ls).Error; err != nil {
-					return err
-				}
-				log.Printf("   Created loan_step: %s", ls.Name)
-			}
-		}
-	}
-	return nil
+	return seedByCode(db, "loan_step", loanSteps, func(ls *models.LoanStep) string { return ls.Code }, result)
 }
 
-func seedLoanDocs(db *gorm.DB) error {
+func seedLoanDocs(db *gorm.DB, result *SeedResult) error {
 	loanDocs := []models.LoanDoc{
 		{
 			Code:        "ID_CARD",
@@ -194,21 +222,10 @@ func seedLoanDocs(db *gorm.DB) error {
 		},
 	}
 
-	for _, ld := range loanDocs {
-		var existing models.LoanDoc
-		if err := db.Where("code = ?", ld.Code).First(&existing).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&ld).Error; err != nil {
-					return err
-				}
-				log.Printf("   Created loan_doc: %s", ld.Name)
-			}
-		}
-	}
-	return nil
+	return seedByCode(db, "loan_doc", loanDocs, func(ld *models.LoanDoc) string { return ld.Code }, result)
 }
 
-func seedLoanAppts(db *gorm.DB) error {
+func seedLoanAppts(db *gorm.DB, result *SeedResult) error {
 	loanAppts := []models.LoanAppt{
 		{
 			Code:            "SUBMIT_DOC",
@@ -240,16 +257,5 @@ func seedLoanAppts(db *gorm.DB) error {
 		},
 	}
 
-	for _, la := range loanAppts {
-		var existing models.LoanAppt
-		if err := db.Where("code = ?", la.Code).First(&existing).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&la).Error; err != nil {
-					return err
-				}
-				log.Printf("   Created loan_appt: %s", la.Name)
-			}
-		}
-	}
-	return nil
+	return seedByCode(db, "loan_appt", loanAppts, func(la *models.LoanAppt) string { return la.Code }, result)
 }
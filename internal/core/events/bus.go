@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"spsc-loaneasy/internal/pkg/logger"
+)
+
+// Handler reacts to one published event
+type Handler func(ctx context.Context, event Event)
+
+// Bus is a lightweight in-process event bus
+//
+// Publish returns right away: each subscriber runs in its own goroutine, so a slow
+// subscriber (LINE, webhooks) never delays the request that published the event.
+// Delivery is at-most-once and in memory only; events are lost if the process stops.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]namedHandler
+}
+
+type namedHandler struct {
+	name string
+	fn   Handler
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]namedHandler)}
+}
+
+// Subscribe registers fn for an event name; subscriber names the handler in logs
+// Subscribe during startup, before events are published
+func (b *Bus) Subscribe(event, subscriber string, fn Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[event] = append(b.handlers[event], namedHandler{name: subscriber, fn: fn})
+}
+
+// Publish dispatches the event to every subscriber in the background
+// Handlers get a fresh background context holding only request_id / user_id for logging,
+// never the request context itself, which fasthttp reuses once the response is sent
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[event.Name()]
+	b.mu.RUnlock()
+
+	log := logger.FromContext(ctx)
+	log.Debug("event published", "event", event.Name(), "subscribers", len(handlers))
+
+	detached := logger.Detach(ctx)
+	for _, h := range handlers {
+		go dispatch(detached, log, h, event)
+	}
+}
+
+// dispatch runs one handler; a panicking subscriber is logged and never takes the server down
+func dispatch(ctx context.Context, log *slog.Logger, h namedHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("event subscriber panicked", "event", event.Name(), "subscriber", h.name, "panic", r)
+		}
+	}()
+	h.fn(ctx, event)
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"spsc-loaneasy/internal/adapters/persistence/models"
+)
+
+// Event names
+const (
+	MortgageCreated       = "mortgage.created"
+	MortgageStatusChanged = "mortgage.status_changed"
+	MortgageApproved      = "mortgage.approved"
+	MortgageRejected      = "mortgage.rejected"
+	AppointmentCreated    = "appointment.created"
+)
+
+// Event is a domain event published on the Bus
+type Event interface {
+	Name() string
+}
+
+// MortgageCreatedEvent - a new loan request was created
+type MortgageCreatedEvent struct {
+	Mortgage   *models.Mortgage
+	MemberName string
+}
+
+func (MortgageCreatedEvent) Name() string { return MortgageCreated }
+
+// MortgageStatusChangedEvent - a case moved to another step (by an officer or auto-advance)
+type MortgageStatusChangedEvent struct {
+	Mortgage   *models.Mortgage
+	FromStepID uint
+	ToStepID   uint
+	StepName   string
+	Auto       bool // true = ระบบเลื่อนขั้นอัตโนมัติ
+}
+
+func (MortgageStatusChangedEvent) Name() string { return MortgageStatusChanged }
+
+// MortgageApprovedEvent - a case was approved
+type MortgageApprovedEvent struct {
+	Mortgage   *models.Mortgage
+	FromStepID uint
+	ToStepID   uint
+}
+
+func (MortgageApprovedEvent) Name() string { return MortgageApproved }
+
+// MortgageRejectedEvent - a case was rejected
+type MortgageRejectedEvent struct {
+	Mortgage   *models.Mortgage
+	FromStepID uint
+	ToStepID   uint
+	Reason     string
+}
+
+func (MortgageRejectedEvent) Name() string { return MortgageRejected }
+
+// AppointmentCreatedEvent - an appointment was set on a case
+type AppointmentCreatedEvent struct {
+	Mortgage *models.Mortgage
+	ApptType string
+	ApptDate string // YYYY-MM-DD
+}
+
+func (AppointmentCreatedEvent) Name() string { return AppointmentCreated }
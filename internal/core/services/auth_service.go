@@ -3,12 +3,15 @@ package services
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"time"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
 	"spsc-loaneasy/internal/config"
 	"spsc-loaneasy/internal/pkg/jwt"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/membno"
 	"spsc-loaneasy/internal/pkg/password"
 
 	"github.com/google/uuid"
@@ -26,6 +29,8 @@ var (
 	ErrTokenExpired       = errors.New("token expired")
 	ErrTokenRevoked       = errors.New("token revoked")
 	ErrUserInactive       = errors.New("user account is inactive")
+	ErrInvalidMembNo      = errors.New("member number must be numeric, up to 5 digits")
+	ErrCannotImpersonate  = errors.New("only member accounts can be impersonated")
 )
 
 // AuthService handles authentication business logic
@@ -34,6 +39,7 @@ type AuthService struct {
 	refreshTokenRepo repositories.RefreshTokenRepository
 	memberRepo       repositories.MemberRepository
 	cfg              *config.Config
+	auditRepo        *repositories.AuditLogRepository
 }
 
 // NewAuthService creates a new auth service
@@ -51,6 +57,72 @@ func NewAuthService(
 	}
 }
 
+// SetAuditLogRepository enables impersonation (every support token is written to audit_logs)
+func (s *AuthService) SetAuditLogRepository(auditRepo *repositories.AuditLogRepository) {
+	s.auditRepo = auditRepo
+}
+
+// ImpersonationResult is a short-lived support token for another user
+type ImpersonationResult struct {
+	AccessToken string               `json:"access_token"`
+	ExpiresAt   time.Time            `json:"expires_at"`
+	User        *models.UserResponse `json:"user"`
+}
+
+// Impersonate issues an access token for userID carrying an impersonated_by claim, so support
+// staff can see what the member sees. Only USER accounts can be impersonated (a staff token
+// could approve under another officer's ID), the token is read-only (see AuthMiddleware) and
+// no refresh token is issued; the audit entry must be written before the token is returned
+func (s *AuthService) Impersonate(ctx context.Context, userID, adminID uint, ip, reason string) (*ImpersonationResult, error) {
+	if s.auditRepo == nil {
+		return nil, errors.New("audit log is not configured")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.ID == adminID || user.Role != "USER" {
+		return nil, ErrCannotImpersonate
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	mins := s.cfg.JWT.ImpersonationTokenMins
+	token, err := jwt.GenerateImpersonationToken(user.ID, user.MembNo, user.Username, user.Role, adminID, s.cfg.JWT.Secret, mins)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(time.Duration(mins) * time.Minute)
+
+	detail := fmt.Sprintf("impersonation token issued, expires %s", expiresAt.Format(time.RFC3339))
+	if reason != "" {
+		detail += ", reason: " + reason
+	}
+	if err := s.auditRepo.Create(ctx, &models.AuditLog{
+		Action:       models.AuditActionImpersonate,
+		ActorID:      adminID,
+		TargetUserID: &user.ID,
+		TargetMembNo: user.MembNo,
+		Detail:       detail,
+		IPAddress:    ip,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Warn("impersonation token issued", "target_user_id", user.ID, "actor_id", adminID)
+
+	return &ImpersonationResult{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		User:        user.ToResponse(),
+	}, nil
+}
+
 // RegisterInput represents registration input
 type RegisterInput struct {
 	MembNo   string `json:"memb_no" validate:"required"`
@@ -81,6 +153,12 @@ type AuthResponse struct {
 // Register registers a new user
 func (s *AuthService) Register(ctx context.Context, input *RegisterInput) (*AuthResponse, error) {
 	// 1. Validate member exists in flommast
+	normalized, ok := membno.Normalize(input.MembNo)
+	if !ok {
+		return nil, ErrInvalidMembNo
+	}
+	input.MembNo = normalized
+
 	member, err := s.memberRepo.GetByMembNo(ctx, input.MembNo)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -143,7 +221,7 @@ func (s *AuthService) Register(ctx context.Context, input *RegisterInput) (*Auth
 	}
 
 	// 8. Store refresh token
-	if err := s.storeRefreshToken(ctx, user.ID, tokens.RefreshToken); err != nil {
+	if err := s.storeRefreshToken(ctx, user, tokens.RefreshToken); err != nil {
 		return nil, err
 	}
 
@@ -152,7 +230,7 @@ func (s *AuthService) Register(ctx context.Context, input *RegisterInput) (*Auth
 	userResponse.FullName = member.FullName
 	userResponse.DeptName = member.DeptName
 
-	log.Printf("✅ User registered: %s (MembNo: %s)", user.Username, user.MembNo)
+	logger.FromContext(ctx).Info("user registered", "user_id", user.ID, "username", user.Username, "memb_no", user.MembNo)
 
 	return &AuthResponse{
 		User:         userResponse,
@@ -192,7 +270,7 @@ func (s *AuthService) Login(ctx context.Context, input *LoginInput) (*AuthRespon
 	}
 
 	// 6. Store refresh token
-	if err := s.storeRefreshToken(ctx, user.ID, tokens.RefreshToken); err != nil {
+	if err := s.storeRefreshToken(ctx, user, tokens.RefreshToken); err != nil {
 		return nil, err
 	}
 
@@ -203,7 +281,7 @@ func (s *AuthService) Login(ctx context.Context, input *LoginInput) (*AuthRespon
 		userResponse.DeptName = member.DeptName
 	}
 
-	log.Printf("✅ User logged in: %s", user.Username)
+	logger.FromContext(ctx).Info("user logged in", "user_id", user.ID, "username", user.Username)
 
 	return &AuthResponse{
 		User:         userResponse,
@@ -268,7 +346,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 	}
 
 	// 10. Store new refresh token
-	if err := s.storeRefreshToken(ctx, user.ID, tokens.RefreshToken); err != nil {
+	if err := s.storeRefreshToken(ctx, user, tokens.RefreshToken); err != nil {
 		return nil, err
 	}
 
@@ -282,7 +360,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 		userResponse.DeptName = member.DeptName
 	}
 
-	log.Printf("✅ Token refreshed for user: %s", user.Username)
+	logger.FromContext(ctx).Info("token refreshed", "user_id", user.ID, "username", user.Username)
 
 	return &AuthResponse{
 		User:         userResponse,
@@ -301,7 +379,7 @@ func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 		return err
 	}
 
-	log.Printf("✅ User logged out")
+	logger.FromContext(ctx).Info("user logged out")
 	return nil
 }
 
@@ -311,7 +389,7 @@ func (s *AuthService) LogoutAll(ctx context.Context, userID uint) error {
 		return err
 	}
 
-	log.Printf("✅ All sessions revoked for user ID: %d", userID)
+	logger.FromContext(ctx).Info("all sessions revoked", "user_id", userID)
 	return nil
 }
 
@@ -327,6 +405,8 @@ func (s *AuthService) GetUserByID(ctx context.Context, userID uint) (*models.Use
 
 // generateTokens generates access and refresh tokens
 func (s *AuthService) generateTokens(user *models.User) (*TokenPair, error) {
+	accessMins, refreshDays := s.cfg.JWT.TokenExpiry(user.Role)
+
 	// Generate access token
 	accessToken, err := jwt.GenerateAccessToken(
 		user.ID,
@@ -334,7 +414,7 @@ func (s *AuthService) generateTokens(user *models.User) (*TokenPair, error) {
 		user.Username,
 		user.Role,
 		s.cfg.JWT.Secret,
-		s.cfg.JWT.AccessTokenMins,
+		accessMins,
 	)
 	if err != nil {
 		return nil, err
@@ -348,7 +428,7 @@ func (s *AuthService) generateTokens(user *models.User) (*TokenPair, error) {
 		user.ID,
 		tokenID,
 		s.cfg.JWT.RefreshSecret,
-		s.cfg.JWT.RefreshTokenDays,
+		refreshDays,
 	)
 	if err != nil {
 		return nil, err
@@ -361,12 +441,13 @@ func (s *AuthService) generateTokens(user *models.User) (*TokenPair, error) {
 }
 
 // storeRefreshToken stores a refresh token in the database
-func (s *AuthService) storeRefreshToken(ctx context.Context, userID uint, refreshToken string) error {
+func (s *AuthService) storeRefreshToken(ctx context.Context, user *models.User, refreshToken string) error {
 	tokenHash := password.HashToken(refreshToken)
-	expiresAt := jwt.GetExpiryTime(s.cfg.JWT.RefreshTokenDays)
+	_, refreshDays := s.cfg.JWT.TokenExpiry(user.Role)
+	expiresAt := jwt.GetExpiryTime(refreshDays)
 
 	token := &models.RefreshToken{
-		UserID:    userID,
+		UserID:    user.ID,
 		TokenHash: tokenHash,
 		ExpiresAt: expiresAt,
 	}
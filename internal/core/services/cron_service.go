@@ -3,22 +3,31 @@ package services
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/pkg/timeutil"
+
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 )
 
 // CronService handles scheduled tasks
 type CronService struct {
-	db          *gorm.DB
-	cron        *cron.Cron
-	lineService *LINEService
+	db             *gorm.DB
+	cron           *cron.Cron
+	lineService    *LINEService
+	webhookService *WebhookService
+	eveningTime    string // เวลาส่งแจ้งเตือนคืนก่อนวันนัด (HH:MM)
+	morningTime    string // เวลาส่งแจ้งเตือนเช้าวันนัด (HH:MM)
 }
 
 // AppointmentReminder represents appointment data for reminder
 type AppointmentReminder struct {
+	MortgageID      uint      `json:"mortgage_id"`
 	MembNo          string    `json:"memb_no"`
 	FullName        string    `json:"full_name"`
 	LineUserID      string    `json:"line_user_id"`
@@ -27,30 +36,53 @@ type AppointmentReminder struct {
 	ApptTime        string    `json:"appt_time"`
 	Location        string    `json:"location"`
 	ApptType        string    `json:"appt_type"`
+	ReminderEnabled bool      `json:"reminder_enabled"`
 }
 
 // NewCronService creates a new cron service
 func NewCronService(db *gorm.DB) *CronService {
 	// Create cron with Bangkok timezone
-	location, _ := time.LoadLocation("Asia/Bangkok")
-	c := cron.New(cron.WithLocation(location))
+	c := cron.New(cron.WithLocation(timeutil.Location()))
 
 	channelID := os.Getenv("LINE_CHANNEL_ID")
 	channelSecret := os.Getenv("LINE_CHANNEL_SECRET")
 	callbackURL := os.Getenv("LINE_CALLBACK_URL")
 
+	eveningTime := os.Getenv("REMINDER_EVENING_TIME")
+	if eveningTime == "" {
+		eveningTime = "18:00"
+	}
+	morningTime := os.Getenv("REMINDER_MORNING_TIME")
+	if morningTime == "" {
+		morningTime = "08:30"
+	}
+
 	return &CronService{
-		db:          db,
-		cron:        c,
-		lineService: NewLINEService(db, channelID, channelSecret, callbackURL, os.Getenv("LIFF_CHANNEL_ID")),
+		db:             db,
+		cron:           c,
+		lineService:    NewLINEService(db, channelID, channelSecret, callbackURL, os.Getenv("LIFF_CHANNEL_ID")),
+		webhookService: NewWebhookService(repositories.NewWebhookRepository(db)),
+		eveningTime:    eveningTime,
+		morningTime:    morningTime,
 	}
 }
 
 // Start starts the cron scheduler
 func (s *CronService) Start() {
-	// Send appointment reminders at 08:30 every day
-	_, err := s.cron.AddFunc("30 8 * * *", func() {
-		log.Println("🔔 Running appointment reminder job...")
+	eveningSpec, err := dailySpec(s.eveningTime)
+	if err != nil {
+		log.Printf("❌ Invalid REMINDER_EVENING_TIME: %v", err)
+		return
+	}
+	morningSpec, err := dailySpec(s.morningTime)
+	if err != nil {
+		log.Printf("❌ Invalid REMINDER_MORNING_TIME: %v", err)
+		return
+	}
+
+	// Evening before: remind tomorrow's appointments
+	_, err = s.cron.AddFunc(eveningSpec, func() {
+		log.Println("🔔 Running evening appointment reminder job...")
 		s.SendAppointmentReminders()
 	})
 	if err != nil {
@@ -58,8 +90,27 @@ func (s *CronService) Start() {
 		return
 	}
 
+	// Same-day morning: remind today's appointments not already reminded
+	_, err = s.cron.AddFunc(morningSpec, func() {
+		log.Println("🔔 Running morning appointment reminder job...")
+		s.SendMorningReminders()
+	})
+	if err != nil {
+		log.Printf("❌ Failed to add cron job: %v", err)
+		return
+	}
+
+	// Retry failed webhook deliveries every minute
+	_, err = s.cron.AddFunc("@every 1m", func() {
+		s.webhookService.RetryPending()
+	})
+	if err != nil {
+		log.Printf("❌ Failed to add cron job: %v", err)
+		return
+	}
+
 	s.cron.Start()
-	log.Println("✅ Cron scheduler started (Appointment reminders at 08:30)")
+	log.Printf("✅ Cron scheduler started (Appointment reminders at %s evening before, %s same day)", s.eveningTime, s.morningTime)
 }
 
 // Stop stops the cron scheduler
@@ -68,55 +119,107 @@ func (s *CronService) Stop() {
 	log.Println("🛑 Cron scheduler stopped")
 }
 
-// SendAppointmentReminders sends LINE reminders for tomorrow's appointments
+// dailySpec converts a 24h "HH:MM" time into a daily cron spec
+func dailySpec(hhmm string) (string, error) {
+	normalized, err := timeutil.NormalizeTimeOfDay(hhmm)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q: %w", hhmm, err)
+	}
+	t, err := time.Parse("15:04", normalized)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()), nil
+}
+
+// SendAppointmentReminders sends LINE reminders for tomorrow's appointments (evening pass)
 func (s *CronService) SendAppointmentReminders() {
-	// Get tomorrow's date
-	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	tomorrow := timeutil.Tomorrow()
+	s.sendReminders(tomorrow, models.ReminderKindEvening)
+}
 
-	log.Printf("📅 Checking appointments for: %s", tomorrow)
+// SendMorningReminders sends LINE reminders for today's appointments (morning pass)
+// Members who already got the evening reminder are skipped
+func (s *CronService) SendMorningReminders() {
+	today := timeutil.Today()
+	s.sendReminders(today, models.ReminderKindMorning)
+}
+
+// sendReminders sends reminders for appointments on targetDate
+func (s *CronService) sendReminders(targetDate, kind string) {
+	log := slog.With("job", "appointment_reminder", "kind", kind, "appt_date", targetDate)
+	log.Info("checking appointments")
 
-	// Query appointments for tomorrow from mortgages table where:
-	// 1. User has linked LINE account
-	// 2. Has appointment date set
+	// Query appointments for the target date from mortgages table where:
+	// 1. Appointment is still PENDING (latest APPT_* transaction is not complete/cancel)
+	// 2. Not already reminded by this pass (or by any pass, for the morning pass)
+	// Members without a linked LINE account are returned too so they can be logged as skipped
 	var appointments []AppointmentReminder
 
 	query := `
 		SELECT 
-			u.memb_no,
-			COALESCE(f.full_name, u.username) as full_name,
-			u.line_user_id,
-			u.line_display_name,
+			m.id as mortgage_id,
+			m.memb_no,
+			COALESCE(f.full_name, u.username, m.memb_no) as full_name,
+			COALESCE(u.line_user_id, '') as line_user_id,
+			COALESCE(u.line_display_name, '') as line_display_name,
 			m.appt_date,
 			m.appt_time,
 			m.appt_location as location,
-			COALESCE(la.name, 'นัดหมาย') as appt_type
+			COALESCE(la.name, 'นัดหมาย') as appt_type,
+			COALESCE(np.reminder, 1) as reminder_enabled
 		FROM mortgages m
-		JOIN users u ON m.memb_no = u.memb_no
-		LEFT JOIN flommast f ON u.memb_no = f.mast_memb_no
+		LEFT JOIN users u ON m.memb_no = u.memb_no AND u.deleted_at IS NULL
+		LEFT JOIN notification_preferences np ON np.user_id = u.id
+		LEFT JOIN flommast f ON m.memb_no = f.mast_memb_no
 		LEFT JOIN loan_appts la ON m.current_appt_id = la.id
 		WHERE DATE(m.appt_date) = ?
 		AND m.deleted_at IS NULL
-		AND u.line_user_id IS NOT NULL
-		AND u.line_user_id != ''
+		AND COALESCE((
+			SELECT t.transaction_type FROM transactions t
+			WHERE t.mortgage_id = m.id AND t.transaction_type IN (?, ?, ?)
+			ORDER BY t.id DESC LIMIT 1
+		), ?) = ?
 	`
+	args := []interface{}{
+		targetDate,
+		models.TxTypeApptCreate, models.TxTypeApptComplete, models.TxTypeApptCancel,
+		models.TxTypeApptCreate, models.TxTypeApptCreate,
+	}
 
-	result := s.db.Raw(query, tomorrow).Scan(&appointments)
+	if kind == models.ReminderKindMorning {
+		query += `
+		AND NOT EXISTS (
+			SELECT 1 FROM reminder_logs rl
+			WHERE rl.mortgage_id = m.id AND rl.appt_date = DATE(m.appt_date)
+		)
+	`
+	} else {
+		query += `
+		AND NOT EXISTS (
+			SELECT 1 FROM reminder_logs rl
+			WHERE rl.mortgage_id = m.id AND rl.appt_date = DATE(m.appt_date) AND rl.kind = ?
+		)
+	`
+		args = append(args, kind)
+	}
+
+	result := s.db.Raw(query, args...).Scan(&appointments)
 	if result.Error != nil {
-		log.Printf("❌ Failed to query appointments: %v", result.Error)
+		log.Error("failed to query appointments", "error", result.Error)
 		return
 	}
 
-	log.Printf("📋 Found %d appointments with LINE linked", len(appointments))
+	log.Info("pending appointments found", "count", len(appointments))
 
 	if len(appointments) == 0 {
-		log.Println("✅ No appointments to remind")
 		return
 	}
 
 	// Get Messaging API Channel Access Token
 	channelAccessToken := os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
 	if channelAccessToken == "" {
-		log.Println("❌ LINE_CHANNEL_ACCESS_TOKEN not set")
+		log.Error("LINE_CHANNEL_ACCESS_TOKEN not set")
 		return
 	}
 
@@ -125,11 +228,44 @@ func (s *CronService) SendAppointmentReminders() {
 		webURL = "https://loanspsc.com"
 	}
 
+	dayText := "วันพรุ่งนี้"
+	if kind == models.ReminderKindMorning {
+		dayText = "วันนี้"
+	}
+
 	// Send reminders
 	successCount := 0
 	failCount := 0
+	skipCount := 0
 
 	for _, appt := range appointments {
+		log := log.With("mortgage_id", appt.MortgageID, "memb_no", appt.MembNo)
+
+		if appt.LineUserID == "" {
+			log.Info("reminder skipped: no LINE account linked")
+			skipCount++
+			continue
+		}
+
+		if !appt.ReminderEnabled {
+			log.Info("reminder skipped: turned off by member")
+			skipCount++
+			continue
+		}
+
+		// Claim the reminder first - if the job runs twice, the unique index stops the second send
+		claimed, err := s.claimReminder(appt, kind)
+		if err != nil {
+			log.Error("failed to claim reminder", "error", err)
+			failCount++
+			continue
+		}
+		if !claimed {
+			log.Info("reminder skipped: already reminded")
+			skipCount++
+			continue
+		}
+
 		// Format date in Thai
 		apptDateStr := appt.ApptDate.Format("02/01/2006")
 		apptTimeStr := appt.ApptTime
@@ -147,15 +283,16 @@ func (s *CronService) SendAppointmentReminders() {
 		)
 
 		// Send flex message
-		err := s.lineService.SendFlexMessage(appt.LineUserID, flexContent, channelAccessToken)
+		err = s.lineService.SendFlexMessage(appt.LineUserID, flexContent, channelAccessToken)
 		if err != nil {
-			log.Printf("❌ Failed to send to %s (%s): %v", appt.MembNo, appt.LineDisplayName, err)
+			log.Warn("failed to send reminder flex message, falling back to text", "error", err)
 			failCount++
 
 			// Fallback: send simple text message
 			simpleMsg := fmt.Sprintf(
-				"📅 แจ้งเตือนนัดหมาย\n\nสวัสดีคุณ %s\nคุณมีนัดหมายกับสหกรณ์ในวันพรุ่งนี้\n\n📆 วันที่: %s\n⏰ เวลา: %s\n\n🔗 เข้าดูรายละเอียดได้ที่: %s",
+				"📅 แจ้งเตือนนัดหมาย\n\nสวัสดีคุณ %s\nคุณมีนัดหมายกับสหกรณ์ใน%s\n\n📆 วันที่: %s\n⏰ เวลา: %s\n\n🔗 เข้าดูรายละเอียดได้ที่: %s",
 				appt.FullName,
+				dayText,
 				apptDateStr,
 				apptTimeStr,
 				webURL,
@@ -163,19 +300,46 @@ func (s *CronService) SendAppointmentReminders() {
 
 			errSimple := s.lineService.SendPushMessage(appt.LineUserID, simpleMsg, channelAccessToken)
 			if errSimple != nil {
-				log.Printf("❌ Failed to send simple message: %v", errSimple)
-			} else {
-				log.Printf("✅ Sent simple message to %s", appt.MembNo)
-				successCount++
-				failCount--
+				log.Error("failed to send reminder", "error", errSimple)
+				if err := s.releaseReminder(appt, kind); err != nil {
+					log.Error("failed to release reminder, it will not be retried", "error", err)
+				}
+				continue
 			}
+			log.Info("reminder sent", "format", "text")
+			successCount++
+			failCount--
 		} else {
-			log.Printf("✅ Sent reminder to %s (%s)", appt.MembNo, appt.LineDisplayName)
+			log.Info("reminder sent", "format", "flex")
 			successCount++
 		}
 	}
 
-	log.Printf("📊 Reminder summary: %d success, %d failed", successCount, failCount)
+	log.Info("reminder run finished", "sent", successCount, "failed", failCount, "skipped", skipCount)
+}
+
+// claimReminder records a reminder before sending so it is only sent once
+// Returns false if the reminder was already recorded; any other failure is returned as an error
+func (s *CronService) claimReminder(appt AppointmentReminder, kind string) (bool, error) {
+	entry := &models.ReminderLog{
+		MortgageID: appt.MortgageID,
+		ApptDate:   appt.ApptDate,
+		Kind:       kind,
+		LineUserID: appt.LineUserID,
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		if repositories.IsDuplicateKey(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseReminder removes a claimed reminder after a failed send so it can be retried
+func (s *CronService) releaseReminder(appt AppointmentReminder, kind string) error {
+	return s.db.Where("mortgage_id = ? AND appt_date = ? AND kind = ?", appt.MortgageID, appt.ApptDate.Format("2006-01-02"), kind).
+		Delete(&models.ReminderLog{}).Error
 }
 
 // SendTestReminder sends a test reminder to a specific LINE user (for testing)
@@ -190,7 +354,7 @@ func (s *CronService) SendTestReminder(lineUserID, memberName string) error {
 		webURL = "https://loanspsc.com"
 	}
 
-	tomorrow := time.Now().AddDate(0, 0, 1).Format("02/01/2006")
+	tomorrow := timeutil.Now().AddDate(0, 0, 1).Format("02/01/2006")
 
 	flexContent := s.lineService.CreateAppointmentReminder(
 		memberName,
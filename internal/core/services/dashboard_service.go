@@ -2,19 +2,28 @@ package services
 
 import (
 	"context"
+	"math"
+	"sort"
 	"time"
 
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/pkg/money"
+	"spsc-loaneasy/internal/pkg/timeutil"
+
 	"gorm.io/gorm"
 )
 
 // DashboardService handles dashboard operations
 type DashboardService struct {
-	db *gorm.DB
+	db              *gorm.DB
+	loanDocRepo     *repositories.LoanDocRepository
+	transactionRepo *repositories.TransactionRepository
 }
 
 // NewDashboardService creates a new dashboard service
-func NewDashboardService(db *gorm.DB) *DashboardService {
-	return &DashboardService{db: db}
+func NewDashboardService(db *gorm.DB, loanDocRepo *repositories.LoanDocRepository, transactionRepo *repositories.TransactionRepository) *DashboardService {
+	return &DashboardService{db: db, loanDocRepo: loanDocRepo, transactionRepo: transactionRepo}
 }
 
 // ============================================================
@@ -86,13 +95,15 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context) (*AdminDashboa
 		Where("deleted_at IS NULL").
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&data.TotalAmount)
+	data.TotalAmount = money.Round(data.TotalAmount)
 
-	// Approved amount
+	// Approved amount (วงเงินที่อนุมัติจริง ถ้าอนุมัติบางส่วน)
 	s.db.WithContext(ctx).Table("mortgages").
 		Joins("JOIN loan_steps ON mortgages.current_step_id = loan_steps.id").
 		Where("loan_steps.code = ? AND mortgages.deleted_at IS NULL", "APPROVED").
-		Select("COALESCE(SUM(mortgages.amount), 0)").
+		Select("COALESCE(SUM(COALESCE(mortgages.approved_amount, mortgages.amount)), 0)").
 		Scan(&data.ApprovedAmount)
+	data.ApprovedAmount = money.Round(data.ApprovedAmount)
 
 	// Mortgage counts by status
 	s.db.WithContext(ctx).Table("mortgages").
@@ -111,7 +122,7 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context) (*AdminDashboa
 		Count(&data.RejectedMortgages)
 
 	// This month statistics
-	startOfMonth := time.Now().AddDate(0, 0, -time.Now().Day()+1).Truncate(24 * time.Hour)
+	startOfMonth := timeutil.StartOfMonth(time.Now())
 	s.db.WithContext(ctx).Table("mortgages").
 		Where("created_at >= ? AND deleted_at IS NULL", startOfMonth).
 		Count(&data.MortgagesThisMonth)
@@ -120,6 +131,7 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context) (*AdminDashboa
 		Where("created_at >= ? AND deleted_at IS NULL", startOfMonth).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&data.AmountThisMonth)
+	data.AmountThisMonth = money.Round(data.AmountThisMonth)
 
 	// Recent mortgages
 	var recentMortgages []struct {
@@ -192,6 +204,276 @@ func (s *DashboardService) GetAdminDashboard(ctx context.Context) (*AdminDashboa
 	return data, nil
 }
 
+// ============================================================
+// Officer Performance
+// ============================================================
+
+// OfficerPerformance represents one officer's activity within a period
+type OfficerPerformance struct {
+	OfficerID      uint    `json:"officer_id"`
+	Username       string  `json:"username"`
+	FullName       string  `json:"full_name"`
+	CasesAssigned  int64   `json:"cases_assigned"`
+	CasesHandled   int64   `json:"cases_handled"`
+	Actions        int64   `json:"actions"`
+	Approved       int64   `json:"approved"`
+	Rejected       int64   `json:"rejected"`
+	AvgDecisionHrs float64 `json:"avg_decision_hours"`
+}
+
+// OfficerPerformanceData represents officer performance over a period
+type OfficerPerformanceData struct {
+	From     string               `json:"from"`
+	To       string               `json:"to"`
+	Officers []OfficerPerformance `json:"officers"`
+}
+
+// GetOfficerPerformance returns activity metrics per officer for [from, to)
+// คำนวณจาก transactions ที่เจ้าหน้าที่ทำในช่วงเวลา รวมเจ้าหน้าที่ที่ไม่มีงานในช่วงนั้นด้วย (ค่าเป็น 0)
+func (s *DashboardService) GetOfficerPerformance(ctx context.Context, from, to time.Time) (*OfficerPerformanceData, error) {
+	data := &OfficerPerformanceData{
+		From:     timeutil.FormatDate(from),
+		To:       timeutil.FormatDate(to.AddDate(0, 0, -1)),
+		Officers: []OfficerPerformance{},
+	}
+
+	// All active officers
+	var officers []struct {
+		ID       uint
+		Username string
+		FullName string
+	}
+	if err := s.db.WithContext(ctx).Table("users").
+		Select("id, username, COALESCE(full_name, '') as full_name").
+		Where("role = ? AND deleted_at IS NULL", "OFFICER").
+		Order("username ASC").
+		Scan(&officers).Error; err != nil {
+		return nil, err
+	}
+
+	// Transaction counts and time-to-decision (mortgage created -> APPROVE/REJECT)
+	var activity []struct {
+		PerformedBy    uint
+		CasesHandled   int64
+		Actions        int64
+		Approved       int64
+		Rejected       int64
+		AvgDecisionSec *float64
+	}
+	if err := s.db.WithContext(ctx).Table("transactions").
+		Select(`
+			transactions.performed_by,
+			COUNT(DISTINCT transactions.mortgage_id) as cases_handled,
+			COUNT(*) as actions,
+			SUM(CASE WHEN transactions.transaction_type = ? THEN 1 ELSE 0 END) as approved,
+			SUM(CASE WHEN transactions.transaction_type = ? THEN 1 ELSE 0 END) as rejected,
+			AVG(CASE WHEN transactions.transaction_type IN (?, ?)
+				THEN TIMESTAMPDIFF(SECOND, mortgages.created_at, transactions.created_at) END) as avg_decision_sec
+		`, models.TxTypeApprove, models.TxTypeReject, models.TxTypeApprove, models.TxTypeReject).
+		Joins("JOIN mortgages ON transactions.mortgage_id = mortgages.id").
+		Joins("JOIN users ON transactions.performed_by = users.id").
+		Where("users.role = ? AND transactions.created_at >= ? AND transactions.created_at < ?", "OFFICER", from, to).
+		Group("transactions.performed_by").
+		Scan(&activity).Error; err != nil {
+		return nil, err
+	}
+
+	// New cases assigned in the period
+	var assigned []struct {
+		OfficerID uint
+		Total     int64
+	}
+	if err := s.db.WithContext(ctx).Table("mortgages").
+		Select("officer_id, COUNT(*) as total").
+		Where("officer_id IS NOT NULL AND created_at >= ? AND created_at < ? AND deleted_at IS NULL", from, to).
+		Group("officer_id").
+		Scan(&assigned).Error; err != nil {
+		return nil, err
+	}
+
+	assignedBy := make(map[uint]int64, len(assigned))
+	for _, a := range assigned {
+		assignedBy[a.OfficerID] = a.Total
+	}
+
+	activityBy := make(map[uint]int, len(activity))
+	for i, a := range activity {
+		activityBy[a.PerformedBy] = i
+	}
+
+	for _, o := range officers {
+		perf := OfficerPerformance{
+			OfficerID:     o.ID,
+			Username:      o.Username,
+			FullName:      o.FullName,
+			CasesAssigned: assignedBy[o.ID],
+		}
+		if i, ok := activityBy[o.ID]; ok {
+			a := activity[i]
+			perf.CasesHandled = a.CasesHandled
+			perf.Actions = a.Actions
+			perf.Approved = a.Approved
+			perf.Rejected = a.Rejected
+			if a.AvgDecisionSec != nil {
+				perf.AvgDecisionHrs = math.Round(*a.AvgDecisionSec/3600*100) / 100
+			}
+		}
+		data.Officers = append(data.Officers, perf)
+	}
+
+	return data, nil
+}
+
+// ============================================================
+// Pipeline
+// ============================================================
+
+// PipelineStep represents one workflow step and the cases currently in it
+type PipelineStep struct {
+	StepID      uint    `json:"step_id"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	StepOrder   int     `json:"step_order"`
+	Color       string  `json:"color"`
+	IsFinal     bool    `json:"is_final"`
+	CaseCount   int64   `json:"case_count"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+// PipelineData represents the full workflow with live case counts
+type PipelineData struct {
+	Steps      []PipelineStep `json:"steps"`
+	TotalCases int64          `json:"total_cases"`
+}
+
+// GetPipeline returns every active step in order with the number of cases sitting there
+// Steps without cases are included (count 0) so the whole workflow is shown
+func (s *DashboardService) GetPipeline(ctx context.Context) (*PipelineData, error) {
+	var steps []models.LoanStep
+	if err := s.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("step_order ASC").
+		Find(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		CurrentStepID uint
+		CaseCount     int64
+		TotalAmount   float64
+	}
+	if err := s.db.WithContext(ctx).Table("mortgages").
+		Select("current_step_id, COUNT(*) as case_count, COALESCE(SUM(amount), 0) as total_amount").
+		Where("deleted_at IS NULL").
+		Group("current_step_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	countBy := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		countBy[c.CurrentStepID] = i
+	}
+
+	data := &PipelineData{Steps: make([]PipelineStep, 0, len(steps))}
+	for _, st := range steps {
+		ps := PipelineStep{
+			StepID:    st.ID,
+			Code:      st.Code,
+			Name:      st.Name,
+			StepOrder: st.StepOrder,
+			Color:     st.Color,
+			IsFinal:   st.IsFinal,
+		}
+		if i, ok := countBy[st.ID]; ok {
+			ps.CaseCount = counts[i].CaseCount
+			ps.TotalAmount = money.Round(counts[i].TotalAmount)
+		}
+		data.TotalCases += ps.CaseCount
+		data.Steps = append(data.Steps, ps)
+	}
+
+	return data, nil
+}
+
+// ============================================================
+// Officer Workload
+// ============================================================
+
+// OfficerWorkload represents one officer's open (non-final) cases
+type OfficerWorkload struct {
+	OfficerID  uint    `json:"officer_id"`
+	Username   string  `json:"username"`
+	OpenCases  int64   `json:"open_cases"`
+	OpenAmount float64 `json:"open_amount"`
+}
+
+// GetOfficerWorkload returns every active officer with their open case count, least loaded first
+// Officers without open cases are included (count 0)
+func (s *DashboardService) GetOfficerWorkload(ctx context.Context) ([]OfficerWorkload, error) {
+	var officers []models.User
+	if err := s.db.WithContext(ctx).
+		Where("role = ? AND is_active = ?", "OFFICER", true).
+		Order("id ASC").
+		Find(&officers).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		OfficerID  uint
+		OpenCases  int64
+		OpenAmount float64
+	}
+	if err := s.db.WithContext(ctx).Table("mortgages").
+		Select("mortgages.officer_id, COUNT(*) as open_cases, COALESCE(SUM(mortgages.amount), 0) as open_amount").
+		Joins("JOIN loan_steps ON loan_steps.id = mortgages.current_step_id").
+		Where("mortgages.deleted_at IS NULL AND mortgages.officer_id IS NOT NULL AND loan_steps.is_final = ?", false).
+		Group("mortgages.officer_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	countBy := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		countBy[c.OfficerID] = i
+	}
+
+	workload := make([]OfficerWorkload, 0, len(officers))
+	for _, o := range officers {
+		w := OfficerWorkload{OfficerID: o.ID, Username: o.Username}
+		if i, ok := countBy[o.ID]; ok {
+			w.OpenCases = counts[i].OpenCases
+			w.OpenAmount = money.Round(counts[i].OpenAmount)
+		}
+		workload = append(workload, w)
+	}
+
+	sort.SliceStable(workload, func(i, j int) bool {
+		if workload[i].OpenCases != workload[j].OpenCases {
+			return workload[i].OpenCases < workload[j].OpenCases
+		}
+		return workload[i].OpenAmount < workload[j].OpenAmount
+	})
+
+	return workload, nil
+}
+
+// SuggestOfficer picks the officer with the fewest open cases (ties: lower open amount, then lower ID)
+// Returns nil when there are no officers
+func SuggestOfficer(workload []OfficerWorkload) *OfficerWorkload {
+	var best *OfficerWorkload
+	for i := range workload {
+		w := &workload[i]
+		if best == nil ||
+			w.OpenCases < best.OpenCases ||
+			(w.OpenCases == best.OpenCases && w.OpenAmount < best.OpenAmount) ||
+			(w.OpenCases == best.OpenCases && w.OpenAmount == best.OpenAmount && w.OfficerID < best.OfficerID) {
+			best = w
+		}
+	}
+	return best
+}
+
 // ============================================================
 // Officer Dashboard
 // ============================================================
@@ -240,6 +522,109 @@ type TransactionInfo struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// OutstandingDocsMember is a member with open cases still missing required documents
+type OutstandingDocsMember struct {
+	MembNo   string                `json:"memb_no"`
+	FullName string                `json:"full_name"`
+	Cases    []*OutstandingDocCase `json:"cases"`
+}
+
+// OutstandingDocCase is one open mortgage and its unchecked required documents
+type OutstandingDocCase struct {
+	MortgageID  uint              `json:"mortgage_id"`
+	OfficerID   *uint             `json:"officer_id"`
+	LoanType    string            `json:"loan_type"`
+	StepName    string            `json:"step_name"`
+	Amount      float64           `json:"amount"`
+	CreatedAt   time.Time         `json:"created_at"`
+	MissingDocs []*models.LoanDoc `json:"missing_docs"`
+}
+
+// GetOutstandingDocs lists open (non-final) cases that still miss required documents, grouped by member
+// officerID limits it to one officer's caseload (nil = all cases)
+func (s *DashboardService) GetOutstandingDocs(ctx context.Context, officerID *uint) ([]*OutstandingDocsMember, error) {
+	db := s.db.WithContext(ctx)
+
+	required, err := s.loanDocRepo.ListRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []struct {
+		ID        uint
+		OfficerID *uint
+		MembNo    string
+		FullName  string
+		LoanType  string
+		StepName  string
+		Amount    float64
+		CreatedAt time.Time
+	}
+	query := db.Table("mortgages").
+		Select(`
+			mortgages.id,
+			mortgages.officer_id,
+			mortgages.memb_no,
+			COALESCE(f.full_name, mortgages.memb_no) as full_name,
+			COALESCE(loan_types.name, '') as loan_type,
+			loan_steps.name as step_name,
+			mortgages.amount,
+			mortgages.created_at
+		`).
+		Joins("JOIN loan_steps ON loan_steps.id = mortgages.current_step_id").
+		Joins("LEFT JOIN loan_types ON loan_types.id = mortgages.loan_type_id").
+		Joins("LEFT JOIN flommast f ON mortgages.memb_no = f.mast_memb_no").
+		Where("mortgages.deleted_at IS NULL AND loan_steps.is_final = ?", false)
+	if officerID != nil {
+		query = query.Where("mortgages.officer_id = ?", *officerID)
+	}
+	if err := query.Order("mortgages.memb_no ASC, mortgages.id ASC").Scan(&cases).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]*OutstandingDocsMember, 0)
+	if len(required) == 0 || len(cases) == 0 {
+		return members, nil
+	}
+
+	ids := make([]uint, len(cases))
+	for i, c := range cases {
+		ids[i] = c.ID
+	}
+	submissions, err := s.transactionRepo.GetDocSubmissions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range cases {
+		missing := make([]*models.LoanDoc, 0)
+		for _, doc := range required {
+			if _, ok := submissions[c.ID][doc.ID]; !ok {
+				missing = append(missing, doc)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		if n := len(members); n == 0 || members[n-1].MembNo != c.MembNo {
+			members = append(members, &OutstandingDocsMember{MembNo: c.MembNo, FullName: c.FullName})
+		}
+		member := members[len(members)-1]
+		member.Cases = append(member.Cases, &OutstandingDocCase{
+			MortgageID:  c.ID,
+			OfficerID:   c.OfficerID,
+			LoanType:    c.LoanType,
+			StepName:    c.StepName,
+			Amount:      c.Amount,
+			CreatedAt:   c.CreatedAt,
+			MissingDocs: missing,
+		})
+	}
+
+	return members, nil
+}
+
 // GetOfficerDashboard returns officer dashboard data
 func (s *DashboardService) GetOfficerDashboard(ctx context.Context, officerID uint) (*OfficerDashboardData, error) {
 	data := &OfficerDashboardData{}
@@ -268,9 +653,10 @@ func (s *DashboardService) GetOfficerDashboard(ctx context.Context, officerID ui
 		Where("officer_id = ? AND deleted_at IS NULL", officerID).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&data.TotalAmountHandled)
+	data.TotalAmountHandled = money.Round(data.TotalAmountHandled)
 
 	// Today's appointments - ใช้ mortgages.appt_date แทน loan_appt_currents
-	today := time.Now().Format("2006-01-02")
+	today := timeutil.Today()
 	var todayAppts []struct {
 		ID         uint
 		MortgageID uint
@@ -339,7 +725,7 @@ func (s *DashboardService) GetOfficerDashboard(ctx context.Context, officerID ui
 	}
 
 	// This week appointments - ใช้ mortgages.appt_date แทน loan_appt_currents
-	startOfWeek := time.Now().AddDate(0, 0, -int(time.Now().Weekday()))
+	startOfWeek := timeutil.StartOfWeek(time.Now())
 	endOfWeek := startOfWeek.AddDate(0, 0, 7)
 	var weekAppts []struct {
 		ID         uint
@@ -362,7 +748,7 @@ func (s *DashboardService) GetOfficerDashboard(ctx context.Context, officerID ui
 		`).
 		Joins("LEFT JOIN loan_appts ON mortgages.current_appt_id = loan_appts.id").
 		Where("mortgages.officer_id = ? AND mortgages.appt_date >= ? AND mortgages.appt_date < ? AND mortgages.deleted_at IS NULL",
-			officerID, startOfWeek.Format("2006-01-02"), endOfWeek.Format("2006-01-02")).
+			officerID, timeutil.FormatDate(startOfWeek), timeutil.FormatDate(endOfWeek)).
 		Order("mortgages.appt_date ASC, mortgages.appt_time ASC").
 		Scan(&weekAppts)
 
@@ -474,8 +860,9 @@ func (s *DashboardService) GetUserDashboard(ctx context.Context, membNo string)
 	s.db.WithContext(ctx).Table("mortgages").
 		Joins("JOIN loan_steps ON mortgages.current_step_id = loan_steps.id").
 		Where("mortgages.memb_no = ? AND loan_steps.code = ? AND mortgages.deleted_at IS NULL", membNo, "APPROVED").
-		Select("COALESCE(SUM(mortgages.amount), 0)").
+		Select("COALESCE(SUM(COALESCE(mortgages.approved_amount, mortgages.amount)), 0)").
 		Scan(&data.TotalBorrowed)
+	data.TotalBorrowed = money.Round(data.TotalBorrowed)
 
 	// My mortgages
 	var mortgages []struct {
@@ -535,7 +922,7 @@ func (s *DashboardService) GetUserDashboard(ctx context.Context, membNo string)
 		`).
 		Joins("LEFT JOIN loan_appts ON mortgages.current_appt_id = loan_appts.id").
 		Where("mortgages.memb_no = ? AND mortgages.appt_date >= ? AND mortgages.deleted_at IS NULL",
-			membNo, time.Now().Format("2006-01-02")).
+			membNo, timeutil.Today()).
 		Order("mortgages.appt_date ASC, mortgages.appt_time ASC").
 		Limit(5).
 		Scan(&upcomingAppts)
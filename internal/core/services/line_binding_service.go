@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLINENotBound     = errors.New("user has no LINE binding")
+	ErrLINENotDuplicate = errors.New("LINE binding is not shared with another user")
+)
+
+// LINEBindingService reports and resolves LINE ids bound to more than one user
+type LINEBindingService struct {
+	db *gorm.DB
+}
+
+// NewLINEBindingService creates a new LINE binding service
+func NewLINEBindingService(db *gorm.DB) *LINEBindingService {
+	return &LINEBindingService{db: db}
+}
+
+// LINEBoundUser is one user row holding a shared LINE id
+type LINEBoundUser struct {
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	MembNo          string     `json:"memb_no"`
+	FullName        string     `json:"full_name"`
+	IsActive        bool       `json:"is_active"`
+	LineDisplayName string     `json:"line_display_name"`
+	LineLinkedAt    *time.Time `json:"line_linked_at"`
+	LastLogin       *time.Time `json:"last_login"`
+}
+
+// LINEDuplicate groups the users sharing one LINE id (most recently linked first)
+type LINEDuplicate struct {
+	LineUserID string           `json:"line_user_id"`
+	Users      []*LINEBoundUser `json:"users"`
+}
+
+// ListDuplicates finds LINE ids bound to more than one non-deleted user
+func (s *LINEBindingService) ListDuplicates(ctx context.Context) ([]*LINEDuplicate, error) {
+	var rows []struct {
+		LINEBoundUser
+		LineUserID string
+	}
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT u.id, u.username, u.memb_no, COALESCE(u.full_name, '') AS full_name, u.is_active,
+			COALESCE(u.line_display_name, '') AS line_display_name, u.line_linked_at, u.last_login, u.line_user_id
+		FROM users u
+		JOIN (
+			SELECT line_user_id FROM users
+			WHERE deleted_at IS NULL AND line_user_id IS NOT NULL AND line_user_id <> ''
+			GROUP BY line_user_id HAVING COUNT(*) > 1
+		) d ON d.line_user_id = u.line_user_id
+		WHERE u.deleted_at IS NULL
+		ORDER BY u.line_user_id, u.line_linked_at DESC, u.id DESC`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]*LINEDuplicate, 0)
+	for i := range rows {
+		row := &rows[i]
+		if n := len(duplicates); n == 0 || duplicates[n-1].LineUserID != row.LineUserID {
+			duplicates = append(duplicates, &LINEDuplicate{LineUserID: row.LineUserID})
+		}
+		last := duplicates[len(duplicates)-1]
+		last.Users = append(last.Users, &row.LINEBoundUser)
+	}
+	return duplicates, nil
+}
+
+// UnbindDuplicate clears the LINE binding of a stale user row
+// Only rows whose LINE id is also bound to another user can be unbound here
+func (s *LINEBindingService) UnbindDuplicate(ctx context.Context, userID, adminID uint, ip string) error {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFoundSvc
+		}
+		return err
+	}
+	if !user.IsLineLinked() {
+		return ErrLINENotBound
+	}
+	lineUserID := *user.LineUserID
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var others int64
+		if err := tx.Model(&models.User{}).
+			Where("line_user_id = ? AND id <> ?", lineUserID, user.ID).
+			Count(&others).Error; err != nil {
+			return err
+		}
+		if others == 0 {
+			return ErrLINENotDuplicate
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"line_user_id":      nil,
+				"line_display_name": nil,
+				"line_picture_url":  nil,
+				"line_linked_at":    nil,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AuditLog{
+			Action:       models.AuditActionLINEUnbind,
+			ActorID:      adminID,
+			TargetUserID: &user.ID,
+			TargetMembNo: user.MembNo,
+			Detail:       fmt.Sprintf("unbound duplicate line_user_id=%s (shared with %d other users)", lineUserID, others),
+			IPAddress:    ip,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("duplicate LINE binding removed", "target_user_id", user.ID, "actor_id", adminID)
+	return nil
+}
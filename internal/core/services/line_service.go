@@ -280,6 +280,16 @@ func (s *LINEService) GetUserByLINEID(lineUserID string) (uint, error) {
 	return userID, nil
 }
 
+// GetLINEUserIDByMembNo gets the linked LINE user id of a member ("" if not linked)
+func (s *LINEService) GetLINEUserIDByMembNo(membNo string) (string, error) {
+	var lineUserID string
+	result := s.db.Raw(`SELECT COALESCE(line_user_id, '') FROM users WHERE memb_no = ? AND deleted_at IS NULL LIMIT 1`, membNo).Scan(&lineUserID)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return lineUserID, nil
+}
+
 // SendPushMessage sends push message to LINE user
 func (s *LINEService) SendPushMessage(lineUserID, message string, channelAccessToken string) error {
 	payload := map[string]interface{}{
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidMasterImport is matched by *MasterImportError via errors.Is
+var ErrInvalidMasterImport = errors.New("invalid master data import")
+
+// MasterImportError lists what is wrong with an import bundle
+type MasterImportError struct {
+	Problems []string
+}
+
+func (e *MasterImportError) Error() string {
+	return "invalid master data import: " + strings.Join(e.Problems, "; ")
+}
+
+// Is lets errors.Is(err, ErrInvalidMasterImport) match
+func (e *MasterImportError) Is(target error) bool {
+	return target == ErrInvalidMasterImport
+}
+
+// MasterBundle holds all four master data sets (export of GET /master/all, input of POST /master/import)
+type MasterBundle struct {
+	LoanTypes []*models.LoanType `json:"loan_types"`
+	LoanSteps []*models.LoanStep `json:"loan_steps"`
+	LoanDocs  []*models.LoanDoc  `json:"loan_docs"`
+	LoanAppts []*models.LoanAppt `json:"loan_appts"`
+}
+
+// MasterImportResult reports what an import changed ("<table>:<code>")
+type MasterImportResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+}
+
+// MasterService handles bulk master data operations for setup wizards
+type MasterService struct {
+	db *gorm.DB
+}
+
+// NewMasterService creates a new master service
+func NewMasterService(db *gorm.DB) *MasterService {
+	return &MasterService{db: db}
+}
+
+// GetAll returns every master row, including inactive ones
+func (s *MasterService) GetAll(ctx context.Context) (*MasterBundle, error) {
+	bundle := &MasterBundle{}
+	db := s.db.WithContext(ctx)
+
+	if err := db.Order("id ASC").Find(&bundle.LoanTypes).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("step_order ASC").Find(&bundle.LoanSteps).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("id ASC").Find(&bundle.LoanDocs).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("id ASC").Find(&bundle.LoanAppts).Error; err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Import upserts the bundle by code in one transaction
+// Rows not in the bundle are left untouched; soft-deleted rows with a matching code are restored.
+// The import is rolled back if the resulting steps lack an entry step, APPROVED or REJECTED
+func (s *MasterService) Import(ctx context.Context, bundle *MasterBundle) (*MasterImportResult, error) {
+	if problems := validateMasterBundle(bundle); len(problems) > 0 {
+		return nil, &MasterImportError{Problems: problems}
+	}
+
+	result := &MasterImportResult{Created: []string{}, Updated: []string{}}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range bundle.LoanTypes {
+			if err := upsertByCode(tx, "loan_types", row, &row.ID, row.Code, result,
+				"name", "description", "interest_rate", "min_amount", "max_amount", "requires_guarantor", "requires_dual_approval", "is_active"); err != nil {
+				return err
+			}
+		}
+		for _, row := range bundle.LoanSteps {
+			if err := upsertByCode(tx, "loan_steps", row, &row.ID, row.Code, result,
+				"name", "description", "step_order", "color", "is_final", "is_active"); err != nil {
+				return err
+			}
+		}
+		for _, row := range bundle.LoanDocs {
+			if err := upsertByCode(tx, "loan_docs", row, &row.ID, row.Code, result,
+				"name", "description", "is_required", "is_active"); err != nil {
+				return err
+			}
+		}
+		for _, row := range bundle.LoanAppts {
+			if err := upsertByCode(tx, "loan_appts", row, &row.ID, row.Code, result,
+				"name", "description", "default_location", "is_active"); err != nil {
+				return err
+			}
+		}
+
+		if problems := checkRequiredSteps(tx); len(problems) > 0 {
+			return &MasterImportError{Problems: problems}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// upsertByCode creates or updates one master row matched by code
+// Columns are written with Select so false/0 values are saved (GORM skips zero values otherwise)
+func upsertByCode(tx *gorm.DB, table string, row interface{}, id *uint, code string, result *MasterImportResult, columns ...string) error {
+	var existing struct{ ID uint }
+	err := tx.Unscoped().Table(table).Select("id").Where("code = ?", code).Take(&existing).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		*id = 0
+		if err := tx.Create(row).Error; err != nil {
+			return fmt.Errorf("create %s:%s: %w", table, code, err)
+		}
+		result.Created = append(result.Created, table+":"+code)
+	case err != nil:
+		return err
+	default:
+		*id = existing.ID
+		result.Updated = append(result.Updated, table+":"+code)
+	}
+
+	columns = append(columns, "deleted_at", "updated_at")
+	if err := tx.Unscoped().Model(row).Select(columns).Updates(row).Error; err != nil {
+		return fmt.Errorf("update %s:%s: %w", table, code, err)
+	}
+	return nil
+}
+
+// validateMasterBundle checks every row has a code and name and no code repeats within a set
+func validateMasterBundle(bundle *MasterBundle) []string {
+	var problems []string
+
+	check := func(table string, codes, names []string) {
+		seen := map[string]bool{}
+		for i, code := range codes {
+			if strings.TrimSpace(code) == "" {
+				problems = append(problems, fmt.Sprintf("%s[%d]: code is required", table, i))
+				continue
+			}
+			if strings.TrimSpace(names[i]) == "" {
+				problems = append(problems, fmt.Sprintf("%s:%s: name is required", table, code))
+			}
+			if seen[code] {
+				problems = append(problems, fmt.Sprintf("%s:%s: duplicate code", table, code))
+			}
+			seen[code] = true
+		}
+	}
+
+	var codes, names []string
+	for _, r := range bundle.LoanTypes {
+		codes, names = append(codes, r.Code), append(names, r.Name)
+		if r.InterestRate < 0 {
+			problems = append(problems, fmt.Sprintf("loan_types:%s: interest_rate must not be negative", r.Code))
+		}
+		if r.MinAmount < 0 || r.MaxAmount < 0 || (r.MaxAmount > 0 && r.MinAmount > r.MaxAmount) {
+			problems = append(problems, fmt.Sprintf("loan_types:%s: invalid min_amount/max_amount", r.Code))
+		}
+	}
+	check("loan_types", codes, names)
+
+	codes, names = nil, nil
+	for _, r := range bundle.LoanSteps {
+		codes, names = append(codes, r.Code), append(names, r.Name)
+	}
+	check("loan_steps", codes, names)
+
+	codes, names = nil, nil
+	for _, r := range bundle.LoanDocs {
+		codes, names = append(codes, r.Code), append(names, r.Name)
+	}
+	check("loan_docs", codes, names)
+
+	codes, names = nil, nil
+	for _, r := range bundle.LoanAppts {
+		codes, names = append(codes, r.Code), append(names, r.Name)
+	}
+	check("loan_appts", codes, names)
+
+	return problems
+}
+
+// checkRequiredSteps verifies the workflow can still run: an active non-final entry step
+// (lowest step_order, see GetFirstStep) plus active APPROVED and REJECTED steps
+func checkRequiredSteps(tx *gorm.DB) []string {
+	var problems []string
+
+	var first models.LoanStep
+	if err := tx.Where("is_active = ?", true).Order("step_order ASC").First(&first).Error; err != nil {
+		problems = append(problems, "loan_steps: no active first step")
+	} else if first.IsFinal {
+		problems = append(problems, fmt.Sprintf("loan_steps:%s: first step must not be final", first.Code))
+	}
+
+	for _, code := range []string{"APPROVED", "REJECTED"} {
+		var count int64
+		tx.Model(&models.LoanStep{}).Where("code = ? AND is_active = ?", code, true).Count(&count)
+		if count == 0 {
+			problems = append(problems, "loan_steps: active step "+code+" is required")
+		}
+	}
+
+	return problems
+}
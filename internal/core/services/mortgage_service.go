@@ -3,11 +3,26 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
-
+	"spsc-loaneasy/internal/core/events"
+	"spsc-loaneasy/internal/pkg/i18n"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/membno"
+	"spsc-loaneasy/internal/pkg/money"
+	"spsc-loaneasy/internal/pkg/storage"
+	"spsc-loaneasy/internal/pkg/timeutil"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -19,15 +34,96 @@ var (
 	ErrLoanApptNotFound       = errors.New("loan appt not found")
 	ErrMemberNotFoundMortgage = errors.New("member not found")
 	ErrOfficerNotFound        = errors.New("officer not found")
+	ErrAlreadyClaimed         = errors.New("mortgage already has an officer")
 	ErrNotAuthorized          = errors.New("not authorized")
 	ErrInvalidStep            = errors.New("invalid step transition")
 	ErrAlreadyApproved        = errors.New("mortgage already approved")
 	ErrApptNotFound           = errors.New("appointment not found")
+	ErrApptAlreadyCompleted   = errors.New("appointment already completed")
+	ErrVersionConflict        = errors.New("mortgage was modified by another user")
+	ErrInvalidDate            = errors.New("invalid date format, use YYYY-MM-DD")
+	ErrInvalidApptTime        = errors.New("invalid appointment time, use 24h HH:MM")
+	ErrInvalidDateRange       = errors.New("date range start is after its end")
+	ErrInvalidTxType          = errors.New("unknown transaction type")
+	ErrLINENotConfigured      = errors.New("LINE messaging is not configured")
+	ErrStorageNotConfigured   = errors.New("file storage is not configured")
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrInvalidContractNo      = errors.New("contract number format is invalid")
+	ErrContractNoUsed         = errors.New("contract number already used")
+	ErrDocsOutstanding        = errors.New("required documents are not submitted")
+	ErrAmountOutOfRange       = errors.New("amount is outside the loan type limits")
+	ErrGuarantorRequired      = errors.New("this loan type requires a guarantor")
+	ErrGuarantorNotFound      = errors.New("guarantor member not found")
+	ErrGuarantorIsBorrower    = errors.New("guarantor must be a different member")
+	ErrNotFinalStep           = errors.New("only cases in a final step can be cloned")
+	ErrInvalidApprovedAmount  = errors.New("approved amount must be positive and not more than the requested amount")
+	ErrExceedsApprovalLimit   = errors.New("amount exceeds your approval limit")
+	ErrAlreadyRecommended     = errors.New("mortgage is already waiting for final approval")
+	ErrNotPendingFinal        = errors.New("mortgage is not waiting for final approval")
+	ErrSelfConfirm            = errors.New("approval must be confirmed by a different user")
 )
 
+// pendingFinalStepCode is the step of a case recommended for approval, waiting for a second approver
+const pendingFinalStepCode = "PENDING_FINAL"
+
+// ApprovalLimitError carries the approver's limit when an approval is over it
+// errors.Is(err, ErrExceedsApprovalLimit) matches it
+type ApprovalLimitError struct {
+	Limit  float64
+	Amount float64
+}
+
+func (e *ApprovalLimitError) Error() string {
+	return fmt.Sprintf("amount %.2f exceeds your approval limit of %.2f", e.Amount, e.Limit)
+}
+
+func (e *ApprovalLimitError) Is(target error) bool {
+	return target == ErrExceedsApprovalLimit
+}
+
+// AmountLimitError names the loan type limit a requested amount broke
+// errors.Is(err, ErrAmountOutOfRange) matches it
+type AmountLimitError struct {
+	LoanType  string
+	MinAmount float64
+	MaxAmount float64
+	Amount    float64
+}
+
+func (e *AmountLimitError) Error() string {
+	if e.MaxAmount > 0 && e.Amount > e.MaxAmount {
+		return fmt.Sprintf("amount exceeds the maximum of %.2f for loan type %s", e.MaxAmount, e.LoanType)
+	}
+	return fmt.Sprintf("amount is below the minimum of %.2f for loan type %s", e.MinAmount, e.LoanType)
+}
+
+func (e *AmountLimitError) Is(target error) bool {
+	return target == ErrAmountOutOfRange
+}
+
+// MissingDocsError lists the required documents still missing at approval
+// errors.Is(err, ErrDocsOutstanding) matches it
+type MissingDocsError struct {
+	Docs []*models.LoanDoc
+}
+
+func (e *MissingDocsError) Error() string {
+	names := make([]string, len(e.Docs))
+	for i, d := range e.Docs {
+		names[i] = d.Name
+	}
+	return ErrDocsOutstanding.Error() + ": " + strings.Join(names, ", ")
+}
+
+func (e *MissingDocsError) Is(target error) bool {
+	return target == ErrDocsOutstanding
+}
+
 type MortgageService struct {
 	mortgageRepo    *repositories.MortgageRepository
 	transactionRepo *repositories.TransactionRepository
+	noteRepo        *repositories.MortgageNoteRepository
+	attachmentRepo  *repositories.ApptAttachmentRepository
 	loanTypeRepo    *repositories.LoanTypeRepository
 	loanStepRepo    *repositories.LoanStepRepository
 	loanDocRepo     *repositories.LoanDocRepository
@@ -35,11 +131,28 @@ type MortgageService struct {
 	memberRepo      repositories.MemberRepository
 	userRepo        repositories.UserRepository
 	notifyService   *NotificationService
+	lineService     *LINEService
+	eventBus        *events.Bus
+	fileStorage     storage.Storage
+	fileURLExpiry   time.Duration
+	contractNoRe    *regexp.Regexp
+	requireDocs     bool
+	autoAdvance     *autoAdvanceRule
+	officerLimit    float64
+	dualThreshold   float64
+}
+
+// autoAdvanceRule moves a case from one step to the next once all required docs are checked
+type autoAdvanceRule struct {
+	FromStep string
+	ToStep   string
 }
 
 func NewMortgageService(
 	mortgageRepo *repositories.MortgageRepository,
 	transactionRepo *repositories.TransactionRepository,
+	noteRepo *repositories.MortgageNoteRepository,
+	attachmentRepo *repositories.ApptAttachmentRepository,
 	loanTypeRepo *repositories.LoanTypeRepository,
 	loanStepRepo *repositories.LoanStepRepository,
 	loanDocRepo *repositories.LoanDocRepository,
@@ -51,6 +164,8 @@ func NewMortgageService(
 	return &MortgageService{
 		mortgageRepo:    mortgageRepo,
 		transactionRepo: transactionRepo,
+		noteRepo:        noteRepo,
+		attachmentRepo:  attachmentRepo,
 		loanTypeRepo:    loanTypeRepo,
 		loanStepRepo:    loanStepRepo,
 		loanDocRepo:     loanDocRepo,
@@ -69,9 +184,27 @@ type CreateMortgageInput struct {
 	Purpose         string  `json:"purpose,omitempty"`
 	GuarantorMembNo string  `json:"guarantor_memb_no,omitempty"`
 	Remark          string  `json:"remark,omitempty"`
+
+	// OfficerID is the responsible officer (defaults to the creator)
+	OfficerID uint `json:"officer_id,omitempty"`
+
+	// Unassigned puts the case in the intake pool instead of assigning an officer
+	// (for member self-initiated requests; officers pick it up with Claim)
+	Unassigned bool `json:"unassigned,omitempty"`
+
+	// ClonedFromID links a resubmission to the original case (set by Clone only)
+	ClonedFromID *uint `json:"-"`
 }
 
-func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput, officerID uint, ipAddress string) (*models.Mortgage, error) {
+// Create creates a mortgage. creatorID is the authenticated user and is stored as UserID,
+// which never changes afterwards; OfficerID is the responsible officer and can be reassigned
+func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput, creatorID uint, ipAddress string) (*models.Mortgage, error) {
+	membNo, ok := membno.Normalize(input.MembNo)
+	if !ok {
+		return nil, ErrInvalidMembNo
+	}
+	input.MembNo = membNo
+
 	member, err := s.memberRepo.GetByMembNo(ctx, input.MembNo)
 	if err != nil || member == nil {
 		return nil, ErrMemberNotFoundMortgage
@@ -82,6 +215,34 @@ func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput
 		return nil, ErrLoanTypeNotFound
 	}
 
+	if err := checkAmountLimits(loanType, input.Amount); err != nil {
+		return nil, err
+	}
+
+	input.GuarantorMembNo = strings.TrimSpace(input.GuarantorMembNo)
+	if input.GuarantorMembNo != "" {
+		guarantorMembNo, ok := membno.Normalize(input.GuarantorMembNo)
+		if !ok {
+			return nil, ErrInvalidMembNo
+		}
+		input.GuarantorMembNo = guarantorMembNo
+	}
+	if err := s.checkGuarantor(ctx, loanType, input.MembNo, input.GuarantorMembNo); err != nil {
+		return nil, err
+	}
+
+	var officerID *uint
+	if !input.Unassigned {
+		officerID = &creatorID
+		if input.OfficerID != 0 && input.OfficerID != creatorID {
+			officer, err := s.userRepo.GetByID(ctx, input.OfficerID)
+			if err != nil || officer == nil || (officer.Role != "OFFICER" && officer.Role != "ADMIN") {
+				return nil, ErrOfficerNotFound
+			}
+			officerID = &officer.ID
+		}
+	}
+
 	firstStep, err := s.loanStepRepo.GetFirstStep(ctx)
 	if err != nil {
 		return nil, ErrLoanStepNotFound
@@ -90,7 +251,7 @@ func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput
 	mortgage := &models.Mortgage{
 		MembNo:        input.MembNo,
 		OfficerID:     officerID,
-		UserID:        officerID,
+		UserID:        creatorID,
 		Amount:        input.Amount,
 		Collateral:    input.Collateral,
 		Purpose:       input.Purpose,
@@ -98,6 +259,7 @@ func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput
 		InterestRate:  loanType.InterestRate,
 		CurrentStepID: firstStep.ID,
 		Remark:        input.Remark,
+		ClonedFromID:  input.ClonedFromID,
 	}
 
 	if input.GuarantorMembNo != "" {
@@ -108,25 +270,77 @@ func (s *MortgageService) Create(ctx context.Context, input *CreateMortgageInput
 		return nil, err
 	}
 
+	description := "สร้างคำขอสินเชื่อใหม่"
+	if input.ClonedFromID != nil {
+		description = fmt.Sprintf("สร้างคำขอสินเชื่อใหม่ (ยื่นใหม่จากคำขอ #%d)", *input.ClonedFromID)
+	}
+
 	tx := &models.Transaction{
 		MortgageID:      mortgage.ID,
 		TransactionType: models.TxTypeCreate,
 		ToStepID:        &firstStep.ID,
 		ToTypeID:        &loanType.ID,
 		Amount:          &input.Amount,
-		Description:     "สร้างคำขอสินเชื่อใหม่",
-		PerformedBy:     officerID,
+		Description:     description,
+		PerformedBy:     creatorID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	if s.notifyService != nil {
-		s.notifyService.NotifyNewMortgage(mortgage, member.FullName)
-	}
+	s.publish(ctx, events.MortgageCreatedEvent{Mortgage: snapshot(mortgage), MemberName: member.FullName})
 
 	return mortgage, nil
 }
 
+// Clone creates a new case at the first step from a case that reached a final step
+// (e.g. a rejected application resubmitted with fixes). The document checklist starts
+// empty because submitted documents are tracked per case via DOC_CHECK transactions
+func (s *MortgageService) Clone(ctx context.Context, sourceID, creatorID uint, ipAddress string) (*models.Mortgage, error) {
+	source, err := s.mortgageRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if source.CurrentStep == nil || !source.CurrentStep.IsFinal {
+		return nil, ErrNotFinalStep
+	}
+
+	input := &CreateMortgageInput{
+		MembNo:       source.MembNo,
+		LoanTypeID:   source.LoanTypeID,
+		Amount:       source.Amount,
+		Collateral:   source.Collateral,
+		Purpose:      source.Purpose,
+		ClonedFromID: &source.ID,
+	}
+	if source.GuarantorMembNo != nil {
+		input.GuarantorMembNo = *source.GuarantorMembNo
+	}
+
+	return s.Create(ctx, input, creatorID, ipAddress)
+}
+
+// checkGuarantor enforces the loan type's guarantor rule and, when a guarantor is given,
+// that it is an existing member other than the borrower
+func (s *MortgageService) checkGuarantor(ctx context.Context, loanType *models.LoanType, membNo, guarantorMembNo string) error {
+	if guarantorMembNo == "" {
+		if loanType.RequiresGuarantor {
+			return ErrGuarantorRequired
+		}
+		return nil
+	}
+
+	if guarantorMembNo == membNo {
+		return ErrGuarantorIsBorrower
+	}
+
+	guarantor, err := s.memberRepo.GetByMembNo(ctx, guarantorMembNo)
+	if err != nil || guarantor == nil {
+		return ErrGuarantorNotFound
+	}
+	return nil
+}
+
 func (s *MortgageService) GetByID(ctx context.Context, id uint) (*models.Mortgage, error) {
 	mortgage, err := s.mortgageRepo.GetByID(ctx, id)
 	if err != nil {
@@ -138,8 +352,158 @@ func (s *MortgageService) GetByID(ctx context.Context, id uint) (*models.Mortgag
 	return mortgage, nil
 }
 
-func (s *MortgageService) GetByMembNo(ctx context.Context, membNo string) ([]*models.Mortgage, error) {
-	return s.mortgageRepo.GetByMembNo(ctx, membNo)
+// GetByMembNo gets a page of a member's mortgages (newest first) and the total count
+func (s *MortgageService) GetByMembNo(ctx context.Context, membNo string, offset, limit int) ([]*models.Mortgage, int64, error) {
+	return s.mortgageRepo.GetByMembNo(ctx, membNo, offset, limit)
+}
+
+// ActivityItem is one entry of a member's activity feed
+type ActivityItem struct {
+	ID          uint      `json:"id"`
+	MortgageID  uint      `json:"mortgage_id"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// activityTypes are the transaction types shown to members (staff-only events such as
+// officer changes, reminders, attachments and trash are left out)
+var activityTypes = []string{
+	models.TxTypeCreate,
+	models.TxTypeUpdate,
+	models.TxTypeStatusChange,
+	models.TxTypeAutoStep,
+	models.TxTypeTypeChange,
+	models.TxTypeDocCheck,
+	models.TxTypeDocUncheck,
+	models.TxTypeApptCreate,
+	models.TxTypeApptComplete,
+	models.TxTypeApptCancel,
+	models.TxTypeApprove,
+	models.TxTypeReject,
+}
+
+// GetActivity returns the member's activity feed across all their mortgages, newest first
+// Descriptions are generated in lang (th/en) rather than taken from the staff-written transaction text
+func (s *MortgageService) GetActivity(ctx context.Context, membNo, lang string, offset, limit int) ([]*ActivityItem, int64, error) {
+	txs, total, err := s.transactionRepo.ListByMembNo(ctx, membNo, activityTypes, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	docNames := map[uint]string{}
+	apptNames := map[uint]string{}
+	if docs, err := s.loanDocRepo.ListAll(ctx); err == nil {
+		for _, d := range docs {
+			docNames[d.ID] = d.Name
+		}
+	}
+	if appts, err := s.loanApptRepo.ListAll(ctx); err == nil {
+		for _, a := range appts {
+			apptNames[a.ID] = a.Name
+		}
+	}
+
+	items := make([]*ActivityItem, len(txs))
+	for i, tx := range txs {
+		key := "activity." + tx.TransactionType
+		var args []interface{}
+
+		switch tx.TransactionType {
+		case models.TxTypeStatusChange, models.TxTypeAutoStep:
+			key = "activity." + models.TxTypeStatusChange
+			stepName := ""
+			if tx.ToStep != nil {
+				stepName = tx.ToStep.Name
+			}
+			args = []interface{}{tx.MortgageID, stepName}
+		case models.TxTypeDocCheck, models.TxTypeDocUncheck:
+			args = []interface{}{tx.MortgageID, nameOf(docNames, tx.ToDocID)}
+		case models.TxTypeApptCreate, models.TxTypeApptComplete, models.TxTypeApptCancel:
+			apptID := tx.ToApptID
+			if apptID == nil {
+				apptID = tx.FromApptID
+			}
+			args = []interface{}{tx.MortgageID, nameOf(apptNames, apptID)}
+		default:
+			args = []interface{}{tx.MortgageID}
+		}
+
+		items[i] = &ActivityItem{
+			ID:          tx.ID,
+			MortgageID:  tx.MortgageID,
+			Type:        tx.TransactionType,
+			Description: i18n.T(lang, key, args...),
+			CreatedAt:   tx.CreatedAt,
+		}
+	}
+
+	return items, total, nil
+}
+
+// nameOf looks up a master row name by optional id ("" when unknown)
+func nameOf(names map[uint]string, id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return names[*id]
+}
+
+// GuaranteeDetail lists the mortgages a member guarantees and the amounts at risk
+type GuaranteeDetail struct {
+	MembNo     string                     `json:"memb_no"`
+	MemberName string                     `json:"member_name"`
+	Mortgages  []*models.MortgageResponse `json:"mortgages"`
+	Count      int                        `json:"count"`
+
+	// TotalExposure sums every guaranteed mortgage that was not rejected
+	TotalExposure float64 `json:"total_exposure"`
+	// ApprovedExposure sums only approved mortgages, at their approved amount
+	ApprovedExposure float64 `json:"approved_exposure"`
+}
+
+// GetGuarantees returns the guarantee obligations of a member
+func (s *MortgageService) GetGuarantees(ctx context.Context, membNo string) (*GuaranteeDetail, error) {
+	membNo, ok := membno.Normalize(membNo)
+	if !ok {
+		return nil, ErrInvalidMembNo
+	}
+
+	member, err := s.memberRepo.GetByMembNo(ctx, membNo)
+	if err != nil || member == nil {
+		return nil, ErrMemberNotFoundMortgage
+	}
+
+	mortgages, err := s.mortgageRepo.ListByGuarantor(ctx, membNo)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &GuaranteeDetail{
+		MembNo:     membNo,
+		MemberName: member.FullName,
+		Mortgages:  make([]*models.MortgageResponse, len(mortgages)),
+		Count:      len(mortgages),
+	}
+	for i, m := range mortgages {
+		detail.Mortgages[i] = m.ToResponse()
+
+		code := ""
+		if m.CurrentStep != nil {
+			code = m.CurrentStep.Code
+		}
+		if code == "REJECTED" {
+			continue
+		}
+		detail.TotalExposure += m.Amount
+		if code == "APPROVED" {
+			detail.ApprovedExposure += m.FinalAmount()
+		}
+	}
+	detail.TotalExposure = money.Round(detail.TotalExposure)
+	detail.ApprovedExposure = money.Round(detail.ApprovedExposure)
+
+	return detail, nil
 }
 
 type ListInput struct {
@@ -147,6 +511,10 @@ type ListInput struct {
 	Limit     int
 	OfficerID *uint
 	StepID    *uint
+
+	// Approval date window (YYYY-MM-DD, both inclusive, business timezone)
+	ApprovedFrom string
+	ApprovedTo   string
 }
 
 type ListOutput struct {
@@ -173,7 +541,15 @@ func (s *MortgageService) List(ctx context.Context, input *ListInput) (*ListOutp
 	var total int64
 	var err error
 
-	if input.OfficerID != nil {
+	if input.ApprovedFrom != "" || input.ApprovedTo != "" {
+		filter := repositories.MortgageListFilter{OfficerID: input.OfficerID, StepID: input.StepID}
+		if filter.ApprovedFrom, filter.ApprovedTo, err = parseDateRange(input.ApprovedFrom, input.ApprovedTo); err != nil {
+			return nil, err
+		}
+		mortgages, total, err = s.mortgageRepo.ListFiltered(ctx, filter, offset, input.Limit)
+	} else if input.OfficerID != nil && input.StepID != nil {
+		mortgages, total, err = s.mortgageRepo.ListByOfficerAndStep(ctx, *input.OfficerID, *input.StepID, offset, input.Limit)
+	} else if input.OfficerID != nil {
 		mortgages, total, err = s.mortgageRepo.ListByOfficer(ctx, *input.OfficerID, offset, input.Limit)
 	} else if input.StepID != nil {
 		mortgages, total, err = s.mortgageRepo.ListByStep(ctx, *input.StepID, offset, input.Limit)
@@ -199,9 +575,79 @@ func (s *MortgageService) List(ctx context.Context, input *ListInput) (*ListOutp
 	}, nil
 }
 
+// parseDateRange turns inclusive YYYY-MM-DD bounds into a [from, to) time window
+// in the business timezone; an empty bound is left open (nil)
+func parseDateRange(from, to string) (*time.Time, *time.Time, error) {
+	var start, end *time.Time
+	if from != "" {
+		t, err := time.ParseInLocation(timeutil.DateLayout, from, timeutil.Location())
+		if err != nil {
+			return nil, nil, ErrInvalidDate
+		}
+		start = &t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(timeutil.DateLayout, to, timeutil.Location())
+		if err != nil {
+			return nil, nil, ErrInvalidDate
+		}
+		t = t.AddDate(0, 0, 1)
+		end = &t
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, ErrInvalidDateRange
+	}
+	return start, end, nil
+}
+
 type ChangeStepInput struct {
-	StepID uint   `json:"step_id" validate:"required"`
-	Remark string `json:"remark,omitempty"`
+	StepID  uint   `json:"step_id" validate:"required"`
+	Remark  string `json:"remark,omitempty"`
+	Version uint   `json:"version,omitempty"`
+}
+
+// StepChangePreview shows what ChangeStep would do, nothing is saved or sent
+type StepChangePreview struct {
+	MortgageID    uint                  `json:"mortgage_id"`
+	FromStep      *models.LoanStep      `json:"from_step"`
+	ToStep        *models.LoanStep      `json:"to_step"`
+	Notifications []NotificationPreview `json:"notifications"`
+	WebhookEvent  string                `json:"webhook_event"`
+}
+
+// PreviewChangeStep validates a step change and returns its effects without persisting anything
+func (s *MortgageService) PreviewChangeStep(ctx context.Context, mortgageID uint, input *ChangeStepInput) (*StepChangePreview, error) {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
+	newStep, err := s.loanStepRepo.GetByID(ctx, input.StepID)
+	if err != nil {
+		return nil, ErrLoanStepNotFound
+	}
+
+	// same rule as ChangeStep: PENDING_FINAL is only reached through Approve
+	if newStep.Code == pendingFinalStepCode {
+		return nil, ErrInvalidStep
+	}
+
+	preview := &StepChangePreview{
+		MortgageID:    mortgage.ID,
+		FromStep:      mortgage.CurrentStep,
+		ToStep:        newStep,
+		Notifications: []NotificationPreview{},
+		WebhookEvent:  models.WebhookEventMortgageStatusChanged,
+	}
+	if s.notifyService != nil {
+		preview.Notifications = s.notifyService.PreviewStatusChange(ctx, mortgage, newStep.Name)
+	}
+
+	return preview, nil
 }
 
 func (s *MortgageService) ChangeStep(ctx context.Context, mortgageID uint, input *ChangeStepInput, userID uint, ipAddress string) (*models.Mortgage, error) {
@@ -210,14 +656,26 @@ func (s *MortgageService) ChangeStep(ctx context.Context, mortgageID uint, input
 		return nil, ErrMortgageNotFound
 	}
 
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
 	newStep, err := s.loanStepRepo.GetByID(ctx, input.StepID)
 	if err != nil {
 		return nil, ErrLoanStepNotFound
 	}
 
+	// PENDING_FINAL is only reached through Approve; sending a case back drops the recommendation
+	if newStep.Code == pendingFinalStepCode {
+		return nil, ErrInvalidStep
+	}
+	if mortgage.RecommendedBy != nil && mortgage.ApprovedAt == nil {
+		clearRecommendation(mortgage)
+	}
+
 	oldStepID := mortgage.CurrentStepID
 	mortgage.CurrentStepID = newStep.ID
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
 		return nil, err
 	}
 
@@ -230,11 +688,14 @@ func (s *MortgageService) ChangeStep(ctx context.Context, mortgageID uint, input
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	if s.notifyService != nil {
-		s.notifyService.NotifyStatusChange(mortgage, newStep.Name)
-	}
+	s.publish(ctx, events.MortgageStatusChangedEvent{
+		Mortgage:   snapshot(mortgage),
+		FromStepID: oldStepID,
+		ToStepID:   newStep.ID,
+		StepName:   newStep.Name,
+	})
 
 	return mortgage, nil
 }
@@ -242,6 +703,13 @@ func (s *MortgageService) ChangeStep(ctx context.Context, mortgageID uint, input
 type ApproveInput struct {
 	ContractNo string `json:"contract_no" validate:"required"`
 	Remark     string `json:"remark,omitempty"`
+	Version    uint   `json:"version,omitempty"`
+
+	// OverrideDocs approves even if required documents are still missing
+	OverrideDocs bool `json:"override_docs,omitempty"`
+
+	// ApprovedAmount approves less than requested (nil = full amount)
+	ApprovedAmount *float64 `json:"approved_amount,omitempty"`
 }
 
 func (s *MortgageService) Approve(ctx context.Context, mortgageID uint, input *ApproveInput, approverID uint, ipAddress string) (*models.Mortgage, error) {
@@ -250,9 +718,65 @@ func (s *MortgageService) Approve(ctx context.Context, mortgageID uint, input *A
 		return nil, ErrMortgageNotFound
 	}
 
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
 	if mortgage.ApprovedAt != nil {
 		return nil, ErrAlreadyApproved
 	}
+	if mortgage.RecommendedBy != nil {
+		// already recommended: the second approver uses ConfirmApproval
+		return nil, ErrAlreadyRecommended
+	}
+
+	input.ContractNo = strings.TrimSpace(input.ContractNo)
+	if s.contractNoRe != nil && !s.contractNoRe.MatchString(input.ContractNo) {
+		return nil, ErrInvalidContractNo
+	}
+
+	if input.ApprovedAmount != nil && (*input.ApprovedAmount <= 0 || *input.ApprovedAmount > mortgage.Amount) {
+		return nil, ErrInvalidApprovedAmount
+	}
+
+	approver, err := s.userRepo.GetByID(ctx, approverID)
+	if err != nil {
+		return nil, ErrNotAuthorized
+	}
+	approveAmount := mortgage.Amount
+	if input.ApprovedAmount != nil {
+		approveAmount = *input.ApprovedAmount
+	}
+
+	// Two-step approval: this user only recommends, so the limit is checked on the confirming user
+	dual := s.needsDualApproval(mortgage, approveAmount)
+	if limit, limited := s.approvalLimit(approver); !dual && limited && approveAmount > limit {
+		return nil, &ApprovalLimitError{Limit: limit, Amount: approveAmount}
+	}
+
+	var missing []*models.LoanDoc
+	if s.requireDocs {
+		missing, err = s.OutstandingDocs(ctx, mortgageID)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 && !input.OverrideDocs {
+			return nil, &MissingDocsError{Docs: missing}
+		}
+	}
+
+	// เช็คก่อน update เพื่อไม่ให้ชน unique index แล้วกลายเป็น 500
+	used, err := s.mortgageRepo.ExistsByContractNo(ctx, input.ContractNo, mortgageID)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, ErrContractNoUsed
+	}
+
+	if dual {
+		return s.recommend(ctx, mortgage, input, approverID, ipAddress, missing)
+	}
 
 	approvedStep, err := s.loanStepRepo.GetByCode(ctx, "APPROVED")
 	if err != nil {
@@ -267,8 +791,12 @@ func (s *MortgageService) Approve(ctx context.Context, mortgageID uint, input *A
 	mortgage.ApprovedAt = &now
 	mortgage.CurrentStepID = approvedStep.ID
 	mortgage.Remark = input.Remark
+	mortgage.ApprovedAmount = nil
+	if input.ApprovedAmount != nil && *input.ApprovedAmount < mortgage.Amount {
+		mortgage.ApprovedAmount = input.ApprovedAmount
+	}
 
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
 		return nil, err
 	}
 
@@ -277,113 +805,419 @@ func (s *MortgageService) Approve(ctx context.Context, mortgageID uint, input *A
 		TransactionType: models.TxTypeApprove,
 		FromStepID:      &oldStepID,
 		ToStepID:        &approvedStep.ID,
-		Description:     "อนุมัติสินเชื่อ: " + input.Remark,
+		Description:     approveDescription(input.Remark, mortgage, missing),
 		PerformedBy:     approverID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	if s.notifyService != nil {
-		s.notifyService.NotifyApproved(mortgage)
-	}
+	s.publish(ctx, events.MortgageApprovedEvent{Mortgage: snapshot(mortgage), FromStepID: oldStepID, ToStepID: approvedStep.ID})
 
 	return mortgage, nil
 }
 
-type RejectInput struct {
-	Remark string `json:"remark" validate:"required"`
-}
-
-func (s *MortgageService) Reject(ctx context.Context, mortgageID uint, input *RejectInput, userID uint, ipAddress string) (*models.Mortgage, error) {
-	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
-	if err != nil {
-		return nil, ErrMortgageNotFound
+// approveDescription builds the APPROVE transaction text, noting a partial amount and docs skipped by an override
+func approveDescription(remark string, mortgage *models.Mortgage, missing []*models.LoanDoc) string {
+	desc := "อนุมัติสินเชื่อ: " + remark
+	if mortgage.ApprovedAmount != nil {
+		desc += fmt.Sprintf(" (อนุมัติวงเงิน %.2f บาท จากที่ขอ %.2f บาท)", *mortgage.ApprovedAmount, mortgage.Amount)
+	}
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, d := range missing {
+			names[i] = d.Name
+		}
+		desc += " (อนุมัติโดยยังขาดเอกสาร: " + strings.Join(names, ", ") + ")"
 	}
+	return desc
+}
 
-	rejectedStep, err := s.loanStepRepo.GetByCode(ctx, "REJECTED")
+// recommend is the first half of a two-step approval: the contract number and amount are
+// reserved and the case waits in PENDING_FINAL for a different user to confirm
+func (s *MortgageService) recommend(ctx context.Context, mortgage *models.Mortgage, input *ApproveInput, userID uint, ipAddress string, missing []*models.LoanDoc) (*models.Mortgage, error) {
+	pendingStep, err := s.loanStepRepo.GetByCode(ctx, pendingFinalStepCode)
 	if err != nil {
 		return nil, ErrLoanStepNotFound
 	}
 
 	oldStepID := mortgage.CurrentStepID
-	mortgage.CurrentStepID = rejectedStep.ID
+	now := time.Now()
+
+	mortgage.ContractNo = &input.ContractNo
+	mortgage.RecommendedBy = &userID
+	mortgage.RecommendedAt = &now
+	mortgage.CurrentStepID = pendingStep.ID
 	mortgage.Remark = input.Remark
+	mortgage.ApprovedAmount = nil
+	if input.ApprovedAmount != nil && *input.ApprovedAmount < mortgage.Amount {
+		mortgage.ApprovedAmount = input.ApprovedAmount
+	}
 
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
 		return nil, err
 	}
 
+	desc := approveDescription(input.Remark, mortgage, missing)
 	tx := &models.Transaction{
-		MortgageID:      mortgageID,
-		TransactionType: models.TxTypeReject,
+		MortgageID:      mortgage.ID,
+		TransactionType: models.TxTypeRecommend,
 		FromStepID:      &oldStepID,
-		ToStepID:        &rejectedStep.ID,
-		Description:     "ปฏิเสธสินเชื่อ: " + input.Remark,
+		ToStepID:        &pendingStep.ID,
+		Description:     "เสนออนุมัติสินเชื่อ (รอผู้อนุมัติคนที่สองยืนยัน): " + strings.TrimPrefix(desc, "อนุมัติสินเชื่อ: "),
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	if s.notifyService != nil {
-		s.notifyService.NotifyRejected(mortgage, input.Remark)
-	}
+	s.publish(ctx, events.MortgageStatusChangedEvent{
+		Mortgage:   snapshot(mortgage),
+		FromStepID: oldStepID,
+		ToStepID:   pendingStep.ID,
+		StepName:   pendingStep.Name,
+	})
 
 	return mortgage, nil
 }
 
-func (s *MortgageService) GetHistory(ctx context.Context, mortgageID uint) ([]*models.Transaction, error) {
-	_, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+type ConfirmApprovalInput struct {
+	Remark  string `json:"remark,omitempty"`
+	Version uint   `json:"version,omitempty"`
+}
+
+// ConfirmApproval is the second half of a two-step approval
+// The confirming user must differ from the one who recommended and is held to their approval limit
+func (s *MortgageService) ConfirmApproval(ctx context.Context, mortgageID uint, input *ConfirmApprovalInput, userID uint, ipAddress string) (*models.Mortgage, error) {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
 	if err != nil {
 		return nil, ErrMortgageNotFound
 	}
-	return s.transactionRepo.GetByMortgageID(ctx, mortgageID)
-}
 
-type UpdateDocInput struct {
-	DocID       uint   `json:"doc_id" validate:"required"`
-	IsSubmitted bool   `json:"is_submitted"`
-	Remark      string `json:"remark,omitempty"`
-}
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
 
-func (s *MortgageService) UpdateDoc(ctx context.Context, mortgageID uint, input *UpdateDocInput, userID uint, ipAddress string) error {
-	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if mortgage.ApprovedAt != nil {
+		return nil, ErrAlreadyApproved
+	}
+	if mortgage.RecommendedBy == nil {
+		return nil, ErrNotPendingFinal
+	}
+	if *mortgage.RecommendedBy == userID {
+		return nil, ErrSelfConfirm
+	}
+
+	confirmer, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return ErrMortgageNotFound
+		return nil, ErrNotAuthorized
+	}
+	if limit, limited := s.approvalLimit(confirmer); limited && mortgage.FinalAmount() > limit {
+		return nil, &ApprovalLimitError{Limit: limit, Amount: mortgage.FinalAmount()}
 	}
 
-	_, err = s.loanDocRepo.GetByID(ctx, input.DocID)
+	approvedStep, err := s.loanStepRepo.GetByCode(ctx, "APPROVED")
 	if err != nil {
-		return ErrLoanDocNotFound
+		return nil, ErrLoanStepNotFound
 	}
 
-	mortgage.CurrentDocID = &input.DocID
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
-		return err
+	oldStepID := mortgage.CurrentStepID
+	now := time.Now()
+
+	mortgage.ApprovedBy = &userID
+	mortgage.ApprovedAt = &now
+	mortgage.CurrentStepID = approvedStep.ID
+	if input.Remark != "" {
+		mortgage.Remark = input.Remark
+	}
+
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
+		return nil, err
 	}
 
+	desc := fmt.Sprintf("ยืนยันอนุมัติสินเชื่อ (เสนอโดยผู้ใช้ #%d)", *mortgage.RecommendedBy)
+	if input.Remark != "" {
+		desc += ": " + input.Remark
+	}
 	tx := &models.Transaction{
 		MortgageID:      mortgageID,
-		TransactionType: models.TxTypeDocCheck,
-		ToDocID:         &input.DocID,
-		Description:     input.Remark,
+		TransactionType: models.TxTypeApprove,
+		FromStepID:      &oldStepID,
+		ToStepID:        &approvedStep.ID,
+		Description:     desc,
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	return nil
+	s.publish(ctx, events.MortgageApprovedEvent{Mortgage: snapshot(mortgage), FromStepID: oldStepID, ToStepID: approvedStep.ID})
+
+	return mortgage, nil
 }
 
-func (s *MortgageService) GetDocs(ctx context.Context, mortgageID uint) ([]*models.LoanDoc, error) {
-	return s.loanDocRepo.List(ctx)
+// needsDualApproval reports whether a case must be approved in two steps
+func (s *MortgageService) needsDualApproval(mortgage *models.Mortgage, amount float64) bool {
+	if mortgage.LoanType != nil && mortgage.LoanType.RequiresDualApproval {
+		return true
+	}
+	return s.dualThreshold > 0 && amount >= s.dualThreshold
 }
 
-type CreateApptInput struct {
-	LoanApptID uint   `json:"loan_appt_id" validate:"required"`
-	ApptDate   string `json:"appt_date" validate:"required"`
-	ApptTime   string `json:"appt_time,omitempty"`
-	Location   string `json:"location,omitempty"`
-	Remark     string `json:"remark,omitempty"`
+// clearRecommendation drops a pending two-step recommendation (the reserved contract number is released)
+func clearRecommendation(mortgage *models.Mortgage) {
+	mortgage.RecommendedBy = nil
+	mortgage.RecommendedAt = nil
+	mortgage.ContractNo = nil
+	mortgage.ApprovedAmount = nil
+}
+
+type RejectInput struct {
+	Remark  string `json:"remark" validate:"required"`
+	Version uint   `json:"version,omitempty"`
+}
+
+func (s *MortgageService) Reject(ctx context.Context, mortgageID uint, input *RejectInput, userID uint, ipAddress string) (*models.Mortgage, error) {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
+	rejectedStep, err := s.loanStepRepo.GetByCode(ctx, "REJECTED")
+	if err != nil {
+		return nil, ErrLoanStepNotFound
+	}
+
+	if mortgage.RecommendedBy != nil && mortgage.ApprovedAt == nil {
+		clearRecommendation(mortgage)
+	}
+
+	oldStepID := mortgage.CurrentStepID
+	mortgage.CurrentStepID = rejectedStep.ID
+	mortgage.Remark = input.Remark
+
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
+		return nil, err
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeReject,
+		FromStepID:      &oldStepID,
+		ToStepID:        &rejectedStep.ID,
+		Description:     "ปฏิเสธสินเชื่อ: " + input.Remark,
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	s.publish(ctx, events.MortgageRejectedEvent{
+		Mortgage:   snapshot(mortgage),
+		FromStepID: oldStepID,
+		ToStepID:   rejectedStep.ID,
+		Reason:     input.Remark,
+	})
+
+	return mortgage, nil
+}
+
+// GetHistory returns a mortgage's transactions, newest first; types limits them to those transaction types
+func (s *MortgageService) GetHistory(ctx context.Context, mortgageID uint, types []string) ([]*models.Transaction, error) {
+	for _, t := range types {
+		if !slices.Contains(models.TxTypes, t) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTxType, t)
+		}
+	}
+
+	_, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+	return s.transactionRepo.GetByMortgageID(ctx, mortgageID, types...)
+}
+
+type AddNoteInput struct {
+	Body       string
+	IsInternal *bool // nil = internal
+}
+
+// AddNote adds a note to a mortgage (not part of the transaction audit trail)
+func (s *MortgageService) AddNote(ctx context.Context, mortgageID uint, input *AddNoteInput, authorID uint) (*models.MortgageNote, error) {
+	if _, err := s.mortgageRepo.GetByID(ctx, mortgageID); err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	isInternal := true
+	if input.IsInternal != nil {
+		isInternal = *input.IsInternal
+	}
+
+	note := &models.MortgageNote{
+		MortgageID: mortgageID,
+		AuthorID:   authorID,
+		Body:       strings.TrimSpace(input.Body),
+		IsInternal: isInternal,
+	}
+	if err := s.noteRepo.Create(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// ListNotes lists the notes of a mortgage, newest first
+func (s *MortgageService) ListNotes(ctx context.Context, mortgageID uint, includeInternal bool) ([]*models.MortgageNote, error) {
+	if _, err := s.mortgageRepo.GetByID(ctx, mortgageID); err != nil {
+		return nil, ErrMortgageNotFound
+	}
+	return s.noteRepo.ListByMortgageID(ctx, mortgageID, includeInternal)
+}
+
+type UpdateDocInput struct {
+	DocID       uint   `json:"doc_id" validate:"required"`
+	IsSubmitted bool   `json:"is_submitted"`
+	Remark      string `json:"remark,omitempty"`
+	Version     uint   `json:"version,omitempty"`
+}
+
+func (s *MortgageService) UpdateDoc(ctx context.Context, mortgageID uint, input *UpdateDocInput, userID uint, ipAddress string) error {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return ErrMortgageNotFound
+	}
+
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return err
+	}
+
+	_, err = s.loanDocRepo.GetByID(ctx, input.DocID)
+	if err != nil {
+		return ErrLoanDocNotFound
+	}
+
+	mortgage.CurrentDocID = &input.DocID
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
+		return err
+	}
+
+	txType := models.TxTypeDocCheck
+	if !input.IsSubmitted {
+		txType = models.TxTypeDocUncheck
+	}
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: txType,
+		ToDocID:         &input.DocID,
+		Description:     input.Remark,
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	if input.IsSubmitted {
+		s.autoAdvanceOnDocs(ctx, mortgage, input.DocID, userID, ipAddress)
+	}
+
+	return nil
+}
+
+// autoAdvanceOnDocs applies the auto-advance rule after a document is marked submitted
+// Only fires when the checked doc is required and it was the last one missing.
+// Failures are logged, the doc check itself has already succeeded
+func (s *MortgageService) autoAdvanceOnDocs(ctx context.Context, mortgage *models.Mortgage, docID uint, userID uint, ipAddress string) {
+	rule := s.autoAdvance
+	if rule == nil || mortgage.CurrentStep == nil || mortgage.CurrentStep.Code != rule.FromStep {
+		return
+	}
+
+	doc, err := s.loanDocRepo.GetByID(ctx, docID)
+	if err != nil || !doc.IsRequired {
+		return
+	}
+
+	missing, err := s.OutstandingDocs(ctx, mortgage.ID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("auto-advance check failed", "mortgage_id", mortgage.ID, "error", err)
+		return
+	}
+	if len(missing) > 0 {
+		return
+	}
+
+	nextStep, err := s.loanStepRepo.GetByCode(ctx, rule.ToStep)
+	if err != nil {
+		logger.FromContext(ctx).Warn("auto-advance step not found", "mortgage_id", mortgage.ID, "step_code", rule.ToStep, "error", err)
+		return
+	}
+
+	oldStepID := mortgage.CurrentStepID
+	mortgage.CurrentStepID = nextStep.ID
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
+		logger.FromContext(ctx).Warn("auto-advance failed", "mortgage_id", mortgage.ID, "error", err)
+		return
+	}
+	mortgage.CurrentStep = nextStep
+
+	// PerformedBy คือผู้ที่ตรวจเอกสารชิ้นสุดท้าย แต่ประเภท AUTO_STEP บอกว่าระบบเป็นผู้เลื่อนขั้น
+	tx := &models.Transaction{
+		MortgageID:      mortgage.ID,
+		TransactionType: models.TxTypeAutoStep,
+		FromStepID:      &oldStepID,
+		ToStepID:        &nextStep.ID,
+		Description:     "ระบบเลื่อนขั้นอัตโนมัติ: เอกสารบังคับครบแล้ว",
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	s.publish(ctx, events.MortgageStatusChangedEvent{
+		Mortgage:   snapshot(mortgage),
+		FromStepID: oldStepID,
+		ToStepID:   nextStep.ID,
+		StepName:   nextStep.Name,
+		Auto:       true,
+	})
+
+	logger.FromContext(ctx).Info("mortgage auto-advanced", "mortgage_id", mortgage.ID, "from_step", rule.FromStep, "to_step", rule.ToStep)
+}
+
+func (s *MortgageService) GetDocs(ctx context.Context, mortgageID uint) ([]*models.LoanDoc, error) {
+	return s.loanDocRepo.List(ctx)
+}
+
+// OutstandingDocs lists required documents that are not currently marked submitted for a mortgage
+func (s *MortgageService) OutstandingDocs(ctx context.Context, mortgageID uint) ([]*models.LoanDoc, error) {
+	if _, err := s.mortgageRepo.GetByID(ctx, mortgageID); err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	required, err := s.loanDocRepo.ListRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.transactionRepo.GetDocSubmissions(ctx, []uint{mortgageID})
+	if err != nil {
+		return nil, err
+	}
+	submitted := submissions[mortgageID]
+
+	missing := make([]*models.LoanDoc, 0)
+	for _, doc := range required {
+		if _, ok := submitted[doc.ID]; !ok {
+			missing = append(missing, doc)
+		}
+	}
+	return missing, nil
+}
+
+type CreateApptInput struct {
+	LoanApptID uint   `json:"loan_appt_id" validate:"required"`
+	ApptDate   string `json:"appt_date" validate:"required"`
+	ApptTime   string `json:"appt_time,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Remark     string `json:"remark,omitempty"`
+	Version    uint   `json:"version,omitempty"`
 }
 
 func (s *MortgageService) CreateAppt(ctx context.Context, mortgageID uint, input *CreateApptInput, userID uint, ipAddress string) (*models.Mortgage, error) {
@@ -392,6 +1226,10 @@ func (s *MortgageService) CreateAppt(ctx context.Context, mortgageID uint, input
 		return nil, ErrMortgageNotFound
 	}
 
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
 	loanAppt, err := s.loanApptRepo.GetByID(ctx, input.LoanApptID)
 	if err != nil {
 		return nil, ErrLoanApptNotFound
@@ -399,7 +1237,17 @@ func (s *MortgageService) CreateAppt(ctx context.Context, mortgageID uint, input
 
 	apptDate, err := time.Parse("2006-01-02", input.ApptDate)
 	if err != nil {
-		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		return nil, ErrInvalidDate
+	}
+
+	// appt_time is optional; when given it is stored as HH:MM so ORDER BY appt_time works
+	input.ApptTime = strings.TrimSpace(input.ApptTime)
+	if input.ApptTime != "" {
+		apptTime, err := timeutil.NormalizeTimeOfDay(input.ApptTime)
+		if err != nil {
+			return nil, ErrInvalidApptTime
+		}
+		input.ApptTime = apptTime
 	}
 
 	location := input.Location
@@ -413,7 +1261,7 @@ func (s *MortgageService) CreateAppt(ctx context.Context, mortgageID uint, input
 	mortgage.ApptLocation = location
 	// ลบ ApptStatus ออกแล้ว - ระบบนี้แค่ติดตามเฉยๆ
 
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
 		return nil, err
 	}
 
@@ -425,11 +1273,9 @@ func (s *MortgageService) CreateAppt(ctx context.Context, mortgageID uint, input
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
-	if s.notifyService != nil {
-		s.notifyService.NotifyNewAppointment(mortgage, loanAppt.Name, input.ApptDate)
-	}
+	s.publish(ctx, events.AppointmentCreatedEvent{Mortgage: snapshot(mortgage), ApptType: loanAppt.Name, ApptDate: input.ApptDate})
 
 	return mortgage, nil
 }
@@ -455,18 +1301,467 @@ func (s *MortgageService) CompleteAppt(ctx context.Context, mortgageID uint, app
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
 	return nil
 }
 
+// ApptCompleteItem identifies one appointment of a batch completion
+type ApptCompleteItem struct {
+	MortgageID uint `json:"mortgage_id" validate:"required"`
+	ApptID     uint `json:"appt_id" validate:"required"`
+}
+
+// ApptCompleteResult is the outcome of one batch item (Err is nil on success)
+type ApptCompleteResult struct {
+	MortgageID uint
+	ApptID     uint
+	Err        error
+}
+
+// CompleteApptBatch completes several appointments, each item on its own so one failure
+// does not undo the others. Officers may only complete appointments of cases assigned to them
+func (s *MortgageService) CompleteApptBatch(ctx context.Context, items []ApptCompleteItem, userID uint, isAdmin bool, ipAddress string) ([]*ApptCompleteResult, error) {
+	mortgageIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		mortgageIDs = append(mortgageIDs, item.MortgageID)
+	}
+	statuses, err := s.apptStatuses(ctx, mortgageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ApptCompleteResult, 0, len(items))
+	for _, item := range items {
+		result := &ApptCompleteResult{MortgageID: item.MortgageID, ApptID: item.ApptID}
+		results = append(results, result)
+
+		mortgage, err := s.mortgageRepo.GetByID(ctx, item.MortgageID)
+		if err != nil {
+			result.Err = ErrMortgageNotFound
+			continue
+		}
+		if mortgage.CurrentApptID == nil || *mortgage.CurrentApptID != item.ApptID {
+			result.Err = ErrApptNotFound
+			continue
+		}
+		if !isAdmin && !mortgage.IsAssignedTo(userID) {
+			result.Err = ErrNotAuthorized
+			continue
+		}
+		if statuses[item.MortgageID] == models.ApptStatusCompleted {
+			result.Err = ErrApptAlreadyCompleted
+			continue
+		}
+
+		tx := &models.Transaction{
+			MortgageID:      item.MortgageID,
+			TransactionType: models.TxTypeApptComplete,
+			ToApptID:        &item.ApptID,
+			Description:     "นัดหมายเสร็จสิ้น",
+			PerformedBy:     userID,
+			IPAddress:       ipAddress,
+		}
+		if err := s.transactionRepo.Create(ctx, tx); err != nil {
+			result.Err = err
+			continue
+		}
+		// the same mortgage listed twice is only completed once
+		statuses[item.MortgageID] = models.ApptStatusCompleted
+	}
+
+	logger.FromContext(ctx).Info("appointments batch completed", "items", len(items), "performed_by", userID)
+	return results, nil
+}
+
+// ApptFileInput is an already validated file to attach to an appointment
+type ApptFileInput struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+// AddApptFiles stores files as evidence on the mortgage's current appointment
+// and records an APPT_ATTACH transaction listing the file names
+// The batch is all or nothing: if one file fails, the files already stored are removed again
+func (s *MortgageService) AddApptFiles(ctx context.Context, mortgageID uint, apptID uint, files []*ApptFileInput, userID uint, ipAddress string) ([]*models.ApptAttachment, error) {
+	if s.fileStorage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if mortgage.CurrentApptID == nil || *mortgage.CurrentApptID != apptID {
+		return nil, ErrApptNotFound
+	}
+
+	attachments := make([]*models.ApptAttachment, 0, len(files))
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		name := filepath.Base(strings.ReplaceAll(f.FileName, "\\", "/"))
+		key := fmt.Sprintf("appts/%d/%d/%s%s", mortgageID, apptID, uuid.NewString(), strings.ToLower(filepath.Ext(name)))
+
+		if err := s.fileStorage.Put(ctx, key, f.Content, f.ContentType); err != nil {
+			s.removeApptFiles(ctx, attachments)
+			return nil, err
+		}
+
+		attachment := &models.ApptAttachment{
+			MortgageID:  mortgageID,
+			ApptID:      apptID,
+			ApptDate:    mortgage.ApptDate,
+			FileName:    name,
+			StorageKey:  key,
+			ContentType: f.ContentType,
+			Size:        f.Size,
+			UploadedBy:  userID,
+		}
+		if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+			s.removeApptFiles(ctx, append(attachments, &models.ApptAttachment{StorageKey: key}))
+			return nil, err
+		}
+
+		attachments = append(attachments, attachment)
+		names = append(names, name)
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeApptAttach,
+		ToApptID:        &apptID,
+		Description:     "แนบไฟล์: " + strings.Join(names, ", "),
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	return attachments, nil
+}
+
+// removeApptFiles rolls back files stored by a failed AddApptFiles batch
+// A record without an ID only has its stored file removed; cleanup still runs if the request was cancelled
+func (s *MortgageService) removeApptFiles(ctx context.Context, attachments []*models.ApptAttachment) {
+	ctx = context.WithoutCancel(ctx)
+	log := logger.FromContext(ctx)
+	for _, a := range attachments {
+		if err := s.fileStorage.Delete(ctx, a.StorageKey); err != nil {
+			log.Error("failed to remove stored file after failed upload", "storage_key", a.StorageKey, "error", err)
+		}
+		if a.ID == 0 {
+			continue
+		}
+		if err := s.attachmentRepo.Delete(ctx, a.ID); err != nil {
+			log.Error("failed to remove attachment after failed upload", "attachment_id", a.ID, "error", err)
+		}
+	}
+}
+
+// ListApptFiles lists the files attached to a mortgage appointment
+func (s *MortgageService) ListApptFiles(ctx context.Context, mortgageID uint, apptID uint) ([]*models.ApptAttachment, error) {
+	if _, err := s.mortgageRepo.GetByID(ctx, mortgageID); err != nil {
+		return nil, ErrMortgageNotFound
+	}
+	return s.attachmentRepo.ListByAppt(ctx, mortgageID, apptID)
+}
+
+// ApptFileDownload is an attached file ready to send: either a signed URL or the content
+type ApptFileDownload struct {
+	Attachment *models.ApptAttachment
+	URL        string        // time-limited link when the storage supports it (S3)
+	Content    io.ReadCloser // set when URL is empty; caller must close it
+}
+
+// OpenApptFile prepares an attached file for download
+func (s *MortgageService) OpenApptFile(ctx context.Context, mortgageID uint, apptID uint, fileID uint) (*ApptFileDownload, error) {
+	if s.fileStorage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, fileID)
+	if err != nil || attachment.MortgageID != mortgageID || attachment.ApptID != apptID {
+		return nil, ErrAttachmentNotFound
+	}
+
+	link, err := s.fileStorage.URL(ctx, attachment.StorageKey, attachment.FileName, s.fileURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if link != "" {
+		return &ApptFileDownload{Attachment: attachment, URL: link}, nil
+	}
+
+	content, err := s.fileStorage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+	return &ApptFileDownload{Attachment: attachment, Content: content}, nil
+}
+
+// SetFileStorage sets the storage used for uploaded files
+// urlExpiry is how long signed download links stay valid (ignored by local storage)
+func (s *MortgageService) SetFileStorage(fileStorage storage.Storage, urlExpiry time.Duration) {
+	s.fileStorage = fileStorage
+	s.fileURLExpiry = urlExpiry
+}
+
 func (s *MortgageService) GetAppts(ctx context.Context, mortgageID uint) (*models.Mortgage, error) {
 	return s.mortgageRepo.GetByID(ctx, mortgageID)
 }
 
+type ListApptsByDateInput struct {
+	Date      string
+	OfficerID *uint
+}
+
+// AppointmentListItem represents an appointment in the per-date list
+type AppointmentListItem struct {
+	MortgageID  uint   `json:"mortgage_id"`
+	MembNo      string `json:"memb_no"`
+	MemberName  string `json:"member_name"`
+	OfficerID   *uint  `json:"officer_id"`
+	OfficerName string `json:"officer_name"`
+	ApptID      *uint  `json:"appt_id"`
+	ApptType    string `json:"appt_type"`
+	ApptDate    string `json:"appt_date"`
+	ApptTime    string `json:"appt_time"`
+	Location    string `json:"location"`
+	Status      string `json:"status"`
+}
+
+// ListApptsByDate lists appointments across all mortgages for a date
+func (s *MortgageService) ListApptsByDate(ctx context.Context, input *ListApptsByDateInput) ([]*AppointmentListItem, error) {
+	if _, err := time.Parse("2006-01-02", input.Date); err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	mortgages, err := s.mortgageRepo.ListByApptDate(ctx, input.Date, input.OfficerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(mortgages))
+	for i, m := range mortgages {
+		ids[i] = m.ID
+	}
+
+	statuses, err := s.apptStatuses(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	items := make([]*AppointmentListItem, 0, len(mortgages))
+	for _, m := range mortgages {
+		name, ok := names[m.MembNo]
+		if !ok {
+			if member, err := s.memberRepo.GetByMembNo(ctx, m.MembNo); err == nil && member != nil {
+				name = member.FullName
+			}
+			names[m.MembNo] = name
+		}
+
+		item := &AppointmentListItem{
+			MortgageID: m.ID,
+			MembNo:     m.MembNo,
+			MemberName: name,
+			OfficerID:  m.OfficerID,
+			ApptID:     m.CurrentApptID,
+			ApptType:   "นัดหมาย",
+			ApptDate:   input.Date,
+			ApptTime:   m.ApptTime,
+			Location:   m.ApptLocation,
+			Status:     statuses[m.ID],
+		}
+		if m.Officer != nil {
+			item.OfficerName = m.Officer.Username
+		}
+		if m.CurrentAppt != nil {
+			item.ApptType = m.CurrentAppt.Name
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// apptStatuses derives the current appointment status of each mortgage
+// from its latest APPT_CREATE / APPT_COMPLETE / APPT_CANCEL transaction
+func (s *MortgageService) apptStatuses(ctx context.Context, mortgageIDs []uint) (map[uint]string, error) {
+	latest, err := s.transactionRepo.GetLatestByTypes(ctx, mortgageIDs, []string{
+		models.TxTypeApptCreate,
+		models.TxTypeApptComplete,
+		models.TxTypeApptCancel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[uint]string, len(mortgageIDs))
+	for _, id := range mortgageIDs {
+		statuses[id] = models.ApptStatusPending
+		if tx, ok := latest[id]; ok {
+			switch tx.TransactionType {
+			case models.TxTypeApptComplete:
+				statuses[id] = models.ApptStatusCompleted
+			case models.TxTypeApptCancel:
+				statuses[id] = models.ApptStatusCancelled
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// SetContractNoFormat sets the pattern contract numbers must match on approve
+func (s *MortgageService) SetContractNoFormat(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	s.contractNoRe = re
+	return nil
+}
+
+// SetRequireDocsOnApprove turns on blocking approval while required documents are missing
+func (s *MortgageService) SetRequireDocsOnApprove(require bool) {
+	s.requireDocs = require
+}
+
+// SetAutoAdvanceOnDocs enables moving cases from fromStep to toStep once all required docs
+// are checked. Leave either code empty to turn it off
+func (s *MortgageService) SetAutoAdvanceOnDocs(fromStep, toStep string) {
+	if fromStep == "" || toStep == "" {
+		s.autoAdvance = nil
+		return
+	}
+	s.autoAdvance = &autoAdvanceRule{FromStep: fromStep, ToStep: toStep}
+}
+
+// SetOfficerApprovalLimit sets the default approval limit for officers (0 = no limit)
+func (s *MortgageService) SetOfficerApprovalLimit(limit float64) {
+	s.officerLimit = limit
+}
+
+// SetDualApprovalThreshold sends cases of this amount or more through two-step approval (0 = off)
+func (s *MortgageService) SetDualApprovalThreshold(threshold float64) {
+	s.dualThreshold = threshold
+}
+
+// approvalLimit returns the largest amount a user may approve and whether a limit applies
+// ADMIN is never limited; a personal limit overrides the officer default
+func (s *MortgageService) approvalLimit(user *models.User) (float64, bool) {
+	if user.Role == "ADMIN" {
+		return 0, false
+	}
+	limit := s.officerLimit
+	if user.ApprovalLimit != nil {
+		limit = *user.ApprovalLimit
+	}
+	return limit, limit > 0
+}
+
+// SetLINEService sets the LINE service used for direct member reminders
+func (s *MortgageService) SetLINEService(lineService *LINEService) {
+	s.lineService = lineService
+}
+
+// SetEventBus sets the bus that mortgage domain events are published on
+// (LINE notifications and outbound webhooks subscribe to it)
+func (s *MortgageService) SetEventBus(bus *events.Bus) {
+	s.eventBus = bus
+}
+
+// publish sends a domain event to the bus; subscribers run in the background
+func (s *MortgageService) publish(ctx context.Context, event events.Event) {
+	s.eventBus.Publish(ctx, event)
+}
+
+// snapshot copies the mortgage so background subscribers never race with later writes
+func snapshot(mortgage *models.Mortgage) *models.Mortgage {
+	m := *mortgage
+	return &m
+}
+
+// ApptReminderResult represents the result of a manual appointment reminder
+type ApptReminderResult struct {
+	Sent   bool   `json:"sent"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SendApptReminder sends the appointment reminder flex to the member's LINE right away
+func (s *MortgageService) SendApptReminder(ctx context.Context, mortgageID uint, apptID uint, userID uint, ipAddress string) (*ApptReminderResult, error) {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if mortgage.CurrentApptID == nil || *mortgage.CurrentApptID != apptID || mortgage.ApptDate == nil {
+		return nil, ErrApptNotFound
+	}
+
+	channelAccessToken := os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
+	if s.lineService == nil || channelAccessToken == "" {
+		return nil, ErrLINENotConfigured
+	}
+
+	lineUserID, err := s.lineService.GetLINEUserIDByMembNo(mortgage.MembNo)
+	if err != nil {
+		return nil, err
+	}
+	if lineUserID == "" {
+		return &ApptReminderResult{Sent: false, Reason: "member has no LINE account linked"}, nil
+	}
+	if s.notifyService != nil && !s.notifyService.MemberAllows(ctx, mortgage.MembNo, models.NotifyKindReminder) {
+		return &ApptReminderResult{Sent: false, Reason: "member turned off reminder notifications"}, nil
+	}
+
+	memberName := mortgage.MembNo
+	if member, err := s.memberRepo.GetByMembNo(ctx, mortgage.MembNo); err == nil && member != nil {
+		memberName = member.FullName
+	}
+
+	apptTime := mortgage.ApptTime
+	if apptTime == "" {
+		apptTime = "กรุณาตรวจสอบในระบบ"
+	}
+
+	webURL := os.Getenv("WEB_APP_URL")
+	if webURL == "" {
+		webURL = "https://loanspsc.com"
+	}
+
+	flexContent := s.lineService.CreateAppointmentReminder(
+		memberName,
+		mortgage.ApptDate.Format("02/01/2006"),
+		apptTime,
+		mortgage.ApptLocation,
+		webURL,
+	)
+
+	if err := s.lineService.SendFlexMessage(lineUserID, flexContent, channelAccessToken); err != nil {
+		return &ApptReminderResult{Sent: false, Reason: "failed to send LINE message: " + err.Error()}, nil
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeApptRemind,
+		ToApptID:        &apptID,
+		Description:     "ส่งแจ้งเตือนนัดหมายทาง LINE",
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	return &ApptReminderResult{Sent: true}, nil
+}
+
 type ChangeOfficerInput struct {
 	OfficerID uint   `json:"officer_id" validate:"required"`
 	Remark    string `json:"remark,omitempty"`
+	Version   uint   `json:"version,omitempty"`
 }
 
 func (s *MortgageService) ChangeOfficer(ctx context.Context, mortgageID uint, input *ChangeOfficerInput, userID uint, ipAddress string) (*models.Mortgage, error) {
@@ -475,6 +1770,10 @@ func (s *MortgageService) ChangeOfficer(ctx context.Context, mortgageID uint, in
 		return nil, ErrMortgageNotFound
 	}
 
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return nil, err
+	}
+
 	officer, err := s.userRepo.GetByID(ctx, input.OfficerID)
 	if err != nil || officer == nil {
 		return nil, ErrOfficerNotFound
@@ -484,8 +1783,8 @@ func (s *MortgageService) ChangeOfficer(ctx context.Context, mortgageID uint, in
 		return nil, errors.New("user is not an officer")
 	}
 
-	mortgage.OfficerID = input.OfficerID
-	if err := s.mortgageRepo.Update(ctx, mortgage); err != nil {
+	mortgage.OfficerID = &officer.ID
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
 		return nil, err
 	}
 
@@ -496,7 +1795,236 @@ func (s *MortgageService) ChangeOfficer(ctx context.Context, mortgageID uint, in
 		PerformedBy:     userID,
 		IPAddress:       ipAddress,
 	}
-	s.transactionRepo.Create(ctx, tx)
+	s.recordTransaction(ctx, tx)
 
 	return mortgage, nil
 }
+
+// ListUnassigned lists the intake pool (cases without an officer), oldest first
+func (s *MortgageService) ListUnassigned(ctx context.Context, page, limit int) (*ListOutput, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	mortgages, total, err := s.mortgageRepo.ListUnassigned(ctx, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return &ListOutput{
+		Mortgages:  mortgages,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Claim assigns an unassigned case to the officer picking it up
+// Two officers claiming at once are resolved by the version check (the loser gets ErrVersionConflict)
+func (s *MortgageService) Claim(ctx context.Context, mortgageID uint, officerID uint, ipAddress string) (*models.Mortgage, error) {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if !mortgage.IsUnassigned() {
+		return nil, ErrAlreadyClaimed
+	}
+
+	mortgage.OfficerID = &officerID
+	if err := s.updateMortgage(ctx, mortgage); err != nil {
+		return nil, err
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeOfficerChange,
+		Description:     "รับเคสจากคิวรอมอบหมาย",
+		PerformedBy:     officerID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	return s.mortgageRepo.GetByID(ctx, mortgageID)
+}
+
+type DeleteInput struct {
+	Remark  string `json:"remark,omitempty"`
+	Version uint   `json:"version,omitempty"`
+}
+
+// Delete soft deletes a mortgage (ย้ายไปถังขยะ กู้คืนได้ด้วย Restore)
+func (s *MortgageService) Delete(ctx context.Context, mortgageID uint, input *DeleteInput, userID uint, ipAddress string) error {
+	mortgage, err := s.mortgageRepo.GetByID(ctx, mortgageID)
+	if err != nil {
+		return ErrMortgageNotFound
+	}
+
+	if err := checkVersion(mortgage, input.Version); err != nil {
+		return err
+	}
+
+	if err := s.mortgageRepo.Delete(ctx, mortgageID); err != nil {
+		return err
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeDelete,
+		Description:     input.Remark,
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	return nil
+}
+
+// Restore brings a soft-deleted mortgage back
+// Notes, attachments and transactions are never deleted, so they reappear with the mortgage
+func (s *MortgageService) Restore(ctx context.Context, mortgageID uint, userID uint, ipAddress string) (*models.Mortgage, error) {
+	if _, err := s.mortgageRepo.GetTrashedByID(ctx, mortgageID); err != nil {
+		return nil, ErrMortgageNotFound
+	}
+
+	if err := s.mortgageRepo.Restore(ctx, mortgageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMortgageNotFound
+		}
+		return nil, err
+	}
+
+	tx := &models.Transaction{
+		MortgageID:      mortgageID,
+		TransactionType: models.TxTypeRestore,
+		Description:     "กู้คืนจากถังขยะ",
+		PerformedBy:     userID,
+		IPAddress:       ipAddress,
+	}
+	s.recordTransaction(ctx, tx)
+
+	// Reload with relations (officer / step / appt) as a normal read
+	return s.mortgageRepo.GetByID(ctx, mortgageID)
+}
+
+// TrashItem is a soft-deleted mortgage with its deletion time
+type TrashItem struct {
+	Mortgage  *models.MortgageResponse `json:"mortgage"`
+	DeletedAt time.Time                `json:"deleted_at"`
+}
+
+type TrashOutput struct {
+	Items      []*TrashItem `json:"items"`
+	Total      int64        `json:"total"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// ListTrash lists soft-deleted mortgages
+func (s *MortgageService) ListTrash(ctx context.Context, page, limit int) (*TrashOutput, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	mortgages, total, err := s.mortgageRepo.ListTrashed(ctx, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*TrashItem, len(mortgages))
+	for i, m := range mortgages {
+		items[i] = &TrashItem{
+			Mortgage:  m.ToResponse(),
+			DeletedAt: m.DeletedAt.Time,
+		}
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return &TrashOutput{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// checkAmountLimits checks an amount against the loan type's min/max (0 = no limit)
+func checkAmountLimits(loanType *models.LoanType, amount float64) error {
+	if (loanType.MaxAmount > 0 && amount > loanType.MaxAmount) ||
+		(loanType.MinAmount > 0 && amount < loanType.MinAmount) {
+		return &AmountLimitError{
+			LoanType:  loanType.Name,
+			MinAmount: loanType.MinAmount,
+			MaxAmount: loanType.MaxAmount,
+			Amount:    amount,
+		}
+	}
+	return nil
+}
+
+// checkVersion rejects updates based on a stale read (version 0 skips the check)
+func checkVersion(mortgage *models.Mortgage, version uint) error {
+	if version != 0 && version != mortgage.Version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// recordTransaction saves a history entry and logs the write with structured fields
+// A failed history insert does not fail the request (the mortgage is already saved)
+func (s *MortgageService) recordTransaction(ctx context.Context, tx *models.Transaction) {
+	log := logger.FromContext(ctx).With(
+		"mortgage_id", tx.MortgageID,
+		"transaction_type", tx.TransactionType,
+		"performed_by", tx.PerformedBy,
+	)
+	if tx.FromStepID != nil {
+		log = log.With("from_step_id", *tx.FromStepID)
+	}
+	if tx.ToStepID != nil {
+		log = log.With("to_step_id", *tx.ToStepID)
+	}
+
+	if err := s.transactionRepo.Create(ctx, tx); err != nil {
+		log.Error("failed to record mortgage transaction", "error", err)
+		return
+	}
+	log.Info("mortgage updated")
+}
+
+// updateMortgage saves a mortgage and maps repository version conflicts
+func (s *MortgageService) updateMortgage(ctx context.Context, mortgage *models.Mortgage) error {
+	err := s.mortgageRepo.Update(ctx, mortgage)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return ErrVersionConflict
+	}
+	if errors.Is(err, repositories.ErrDuplicateKey) {
+		// ชนกันระหว่างเช็คกับ update (อนุมัติพร้อมกันด้วยเลขเดียวกัน)
+		return ErrContractNoUsed
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestMortgageService runs MortgageService on an in-memory SQLite database
+func newTestMortgageService(t *testing.T) (*MortgageService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	// Columns owned by the versioned SQL migrations (gorm:"-:migration")
+	userSchema := db.Model(&models.User{}).Statement
+	if err := userSchema.Parse(&models.User{}); err != nil {
+		t.Fatalf("parse user schema: %v", err)
+	}
+	for _, field := range userSchema.Schema.Fields {
+		if field.IgnoreMigration && field.DBName != "" {
+			if err := db.Exec("ALTER TABLE users ADD COLUMN " + field.DBName + " " + db.Dialector.DataTypeOf(field)).Error; err != nil {
+				t.Fatalf("add users.%s: %v", field.DBName, err)
+			}
+		}
+	}
+
+	svc := NewMortgageService(
+		repositories.NewMortgageRepository(db),
+		repositories.NewTransactionRepository(db),
+		repositories.NewMortgageNoteRepository(db),
+		repositories.NewApptAttachmentRepository(db),
+		repositories.NewLoanTypeRepository(db),
+		repositories.NewLoanStepRepository(db),
+		repositories.NewLoanDocRepository(db),
+		repositories.NewLoanApptRepository(db),
+		nil,
+		repositories.NewUserRepository(db),
+		nil,
+	)
+	return svc, db
+}
+
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+func TestDualApprovalRecommendThenConfirm(t *testing.T) {
+	svc, db := newTestMortgageService(t)
+	ctx := context.Background()
+
+	recommender := &models.User{MembNo: "900001", Username: "officer1", Email: "officer1@example.com", Role: "OFFICER", IsActive: true}
+	confirmer := &models.User{MembNo: "900002", Username: "officer2", Email: "officer2@example.com", Role: "OFFICER", IsActive: true}
+	mustCreate(t, db, recommender)
+	mustCreate(t, db, confirmer)
+
+	loanType := &models.LoanType{Code: "HOME", Name: "Home", IsActive: true, RequiresDualApproval: true}
+	mustCreate(t, db, loanType)
+	submitted := &models.LoanStep{Code: "SUBMITTED", Name: "Submitted", StepOrder: 1, IsActive: true}
+	pending := &models.LoanStep{Code: pendingFinalStepCode, Name: "Pending final", StepOrder: 5, IsActive: true}
+	approved := &models.LoanStep{Code: "APPROVED", Name: "Approved", StepOrder: 6, IsActive: true, IsFinal: true}
+	mustCreate(t, db, submitted)
+	mustCreate(t, db, pending)
+	mustCreate(t, db, approved)
+
+	mortgage := &models.Mortgage{
+		MembNo:        "000001",
+		OfficerID:     &recommender.ID,
+		UserID:        recommender.ID,
+		Amount:        500000,
+		LoanTypeID:    loanType.ID,
+		CurrentStepID: submitted.ID,
+	}
+	mustCreate(t, db, mortgage)
+
+	recommended, err := svc.Approve(ctx, mortgage.ID, &ApproveInput{ContractNo: "C-0001"}, recommender.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if recommended.CurrentStepID != pending.ID || recommended.ApprovedAt != nil {
+		t.Fatalf("Approve on a dual-approval loan type must only recommend, got step %d", recommended.CurrentStepID)
+	}
+
+	var stored models.Mortgage
+	if err := db.First(&stored, mortgage.ID).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if stored.RecommendedBy == nil || *stored.RecommendedBy != recommender.ID || stored.RecommendedAt == nil {
+		t.Fatalf("recommendation not saved: recommended_by=%v recommended_at=%v", stored.RecommendedBy, stored.RecommendedAt)
+	}
+
+	if _, err := svc.ConfirmApproval(ctx, mortgage.ID, &ConfirmApprovalInput{}, recommender.ID, "127.0.0.1"); err != ErrSelfConfirm {
+		t.Fatalf("ConfirmApproval by the recommender = %v, want ErrSelfConfirm", err)
+	}
+
+	confirmed, err := svc.ConfirmApproval(ctx, mortgage.ID, &ConfirmApprovalInput{}, confirmer.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ConfirmApproval: %v", err)
+	}
+	if confirmed.CurrentStepID != approved.ID || confirmed.ApprovedBy == nil || *confirmed.ApprovedBy != confirmer.ID {
+		t.Fatalf("case not approved by the confirmer: step %d approved_by %v", confirmed.CurrentStepID, confirmed.ApprovedBy)
+	}
+}
@@ -2,18 +2,48 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/core/events"
+	"spsc-loaneasy/internal/pkg/email"
+	"spsc-loaneasy/internal/pkg/i18n"
+	"spsc-loaneasy/internal/pkg/logger"
+	"spsc-loaneasy/internal/pkg/money"
 )
 
 // NotificationService handles LINE notifications
+// Staff alerts go to the LINE Notify group; member messages are pushed via LINE Messaging
+// and respect each member's notification preferences
 type NotificationService struct {
 	lineNotifyToken string
 	enabled         bool
+
+	lineService *LINEService
+	prefRepo    *repositories.NotificationPreferenceRepository
+
+	// Email fallback for members without a LINE binding (nil = off)
+	emailSender email.Sender
+	userRepo    repositories.UserRepository
+
+	// High-value alert to a staff LINE group (0 / empty = off)
+	highValueAmount  float64
+	highValueGroupID string
+}
+
+// emailSubjects lists the member messages also sent by email, with their subject i18n key
+var emailSubjects = map[string]string{
+	"notify.approved":         "email.subject.approved",
+	"notify.approved_partial": "email.subject.approved",
+	"notify.rejected":         "email.subject.rejected",
+	"notify.new_appt":         "email.subject.appt",
+	"notify.appt_reminder":    "email.subject.appt",
 }
 
 // NewNotificationService creates a new notification service
@@ -30,6 +60,97 @@ func (s *NotificationService) IsEnabled() bool {
 	return s.enabled
 }
 
+// SetLINEService sets the LINE service used to push messages to members
+func (s *NotificationService) SetLINEService(lineService *LINEService) {
+	s.lineService = lineService
+}
+
+// SetPreferenceRepository sets the repository used to look up member notification preferences
+func (s *NotificationService) SetPreferenceRepository(prefRepo *repositories.NotificationPreferenceRepository) {
+	s.prefRepo = prefRepo
+}
+
+// SetEmailSender enables email for members who have no LINE account linked
+func (s *NotificationService) SetEmailSender(sender email.Sender, userRepo repositories.UserRepository) {
+	s.emailSender = sender
+	s.userRepo = userRepo
+}
+
+// SetHighValueAlert pushes new mortgages of amount or more to a LINE group
+func (s *NotificationService) SetHighValueAlert(amount float64, groupID string) {
+	s.highValueAmount = amount
+	s.highValueGroupID = groupID
+}
+
+// MemberAllows reports whether a member wants notifications of the given kind
+// Defaults to true when preferences are unavailable (ค่าเดิมคือส่งทุกประเภท)
+func (s *NotificationService) MemberAllows(ctx context.Context, membNo, kind string) bool {
+	return s.memberPreference(ctx, membNo).Allows(kind)
+}
+
+// memberPreference loads a member's preference, falling back to the all-on default
+func (s *NotificationService) memberPreference(ctx context.Context, membNo string) *models.NotificationPreference {
+	if s.prefRepo == nil {
+		return models.DefaultNotificationPreference(0)
+	}
+
+	pref, err := s.prefRepo.GetByMembNo(ctx, membNo)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to load notification preference", "memb_no", membNo, "error", err)
+		return models.DefaultNotificationPreference(0)
+	}
+	return pref
+}
+
+// notifyMember sends a localized text message (i18n key + args) to the member's linked LINE account
+// Members without LINE get the same text by email when the message has an email subject
+func (s *NotificationService) notifyMember(membNo, kind, key string, args ...interface{}) {
+	lineReady := s.lineService != nil && os.Getenv("LINE_CHANNEL_ACCESS_TOKEN") != ""
+	_, emailable := emailSubjects[key]
+	emailReady := s.emailSender != nil && emailable
+	if !lineReady && !emailReady {
+		return
+	}
+
+	pref := s.memberPreference(context.Background(), membNo)
+	if !pref.Allows(kind) {
+		slog.Info("member notification skipped: turned off by member", "memb_no", membNo, "kind", kind)
+		return
+	}
+	message := i18n.T(pref.Language, key, args...)
+
+	if lineReady {
+		lineUserID, err := s.lineService.GetLINEUserIDByMembNo(membNo)
+		if err == nil && lineUserID != "" {
+			if err := s.lineService.SendPushMessage(lineUserID, message, os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")); err != nil {
+				slog.Error("failed to send member notification", "memb_no", membNo, "kind", kind, "error", err)
+				return
+			}
+			slog.Info("member notification sent", "memb_no", membNo, "kind", kind, "language", i18n.Normalize(pref.Language))
+			return
+		}
+	}
+
+	if emailReady {
+		s.emailMember(membNo, kind, i18n.T(pref.Language, emailSubjects[key]), message)
+	}
+}
+
+// emailMember sends a member message by email when the member's account has a deliverable address
+func (s *NotificationService) emailMember(membNo, kind, subject, message string) {
+	ctx := context.Background()
+	user, err := s.userRepo.GetByMembNo(ctx, membNo)
+	if err != nil || !user.IsActive || !email.IsDeliverable(user.Email) {
+		return
+	}
+
+	if err := s.emailSender.Send(ctx, user.Email, subject, message); err != nil {
+		slog.Error("failed to send member email", "memb_no", membNo, "kind", kind, "error", err)
+		return
+	}
+	slog.Info("member email sent", "memb_no", membNo, "kind", kind)
+}
+
 // sendLineNotify sends a message via LINE Notify
 func (s *NotificationService) sendLineNotify(message string) error {
 	if !s.enabled {
@@ -78,9 +199,47 @@ func (s *NotificationService) NotifyNewMortgage(mortgage *models.Mortgage, membe
 	s.sendLineNotify(message)
 }
 
+// NotifyHighValueMortgage alerts the staff LINE group about a new case at or above the high-value amount
+// Skipped when the alert or LINE Messaging is not configured
+func (s *NotificationService) NotifyHighValueMortgage(mortgage *models.Mortgage, memberName string) {
+	if s.highValueAmount <= 0 || s.highValueGroupID == "" || mortgage.Amount < s.highValueAmount {
+		return
+	}
+	channelAccessToken := os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
+	if s.lineService == nil || channelAccessToken == "" {
+		return
+	}
+
+	message := fmt.Sprintf(`💎 คำขอสินเชื่อวงเงินสูง
+
+📋 รหัส: #%d
+👤 สมาชิก: %s (%s)
+💰 จำนวนเงิน: %s บาท
+📝 วัตถุประสงค์: %s`,
+		mortgage.ID,
+		memberName,
+		mortgage.MembNo,
+		money.Format(mortgage.Amount),
+		mortgage.Purpose,
+	)
+
+	if err := s.lineService.SendPushMessage(s.highValueGroupID, message, channelAccessToken); err != nil {
+		slog.Error("failed to send high-value alert", "mortgage_id", mortgage.ID, "error", err)
+		return
+	}
+	slog.Info("high-value alert sent", "mortgage_id", mortgage.ID, "amount", mortgage.Amount)
+}
+
 // NotifyStatusChange sends notification for status change
 func (s *NotificationService) NotifyStatusChange(mortgage *models.Mortgage, newStepName string) {
-	message := fmt.Sprintf(`
+	s.sendLineNotify(statusChangeStaffMessage(mortgage, newStepName))
+
+	s.notifyMember(mortgage.MembNo, models.NotifyKindStatusChange, "notify.status_change", mortgage.ID, newStepName)
+}
+
+// statusChangeStaffMessage builds the staff (LINE Notify) text for a status change
+func statusChangeStaffMessage(mortgage *models.Mortgage, newStepName string) string {
+	return fmt.Sprintf(`
 🔄 เปลี่ยนสถานะ
 
 📋 รหัส: #%d
@@ -90,8 +249,46 @@ func (s *NotificationService) NotifyStatusChange(mortgage *models.Mortgage, newS
 		mortgage.MembNo,
 		newStepName,
 	)
+}
 
-	s.sendLineNotify(message)
+// NotificationPreview describes a message that would be sent, without sending it
+type NotificationPreview struct {
+	Channel  string `json:"channel"` // line_notify (staff group) / line_push (member)
+	Message  string `json:"message"`
+	WillSend bool   `json:"will_send"`
+	Reason   string `json:"reason,omitempty"` // why it would not be sent
+}
+
+// PreviewStatusChange returns the notifications NotifyStatusChange would send
+func (s *NotificationService) PreviewStatusChange(ctx context.Context, mortgage *models.Mortgage, newStepName string) []NotificationPreview {
+	staff := NotificationPreview{Channel: "line_notify", Message: statusChangeStaffMessage(mortgage, newStepName), WillSend: s.enabled}
+	if !s.enabled {
+		staff.Reason = "LINE Notify is not configured"
+	}
+
+	pref := s.memberPreference(ctx, mortgage.MembNo)
+	member := NotificationPreview{
+		Channel: "line_push",
+		Message: i18n.T(pref.Language, "notify.status_change", mortgage.ID, newStepName),
+	}
+	member.WillSend, member.Reason = s.memberDeliverable(ctx, mortgage.MembNo, models.NotifyKindStatusChange)
+
+	return []NotificationPreview{staff, member}
+}
+
+// memberDeliverable reports whether notifyMember would actually push a message
+func (s *NotificationService) memberDeliverable(ctx context.Context, membNo, kind string) (bool, string) {
+	if s.lineService == nil || os.Getenv("LINE_CHANNEL_ACCESS_TOKEN") == "" {
+		return false, "LINE messaging is not configured"
+	}
+	if !s.MemberAllows(ctx, membNo, kind) {
+		return false, "member turned off this notification"
+	}
+	lineUserID, err := s.lineService.GetLINEUserIDByMembNo(membNo)
+	if err != nil || lineUserID == "" {
+		return false, "member has no LINE account linked"
+	}
+	return true, ""
 }
 
 // NotifyApproved sends notification for approved mortgage
@@ -106,15 +303,33 @@ func (s *NotificationService) NotifyApproved(mortgage *models.Mortgage) {
 
 📋 เลขสัญญา: %s
 👤 สมาชิก: %s
-💰 จำนวนเงิน: %.2f บาท
+💰 จำนวนเงิน: %s บาท
 
 กรุณานัดหมายรับเงิน`,
 		contractNo,
 		mortgage.MembNo,
-		mortgage.Amount,
+		money.Format(mortgage.FinalAmount()),
 	)
 
+	if mortgage.ApprovedAmount != nil {
+		message += fmt.Sprintf("\n(อนุมัติบางส่วน จากที่ขอ %s บาท)", money.Format(mortgage.Amount))
+		s.sendLineNotify(message)
+		s.notifyMember(mortgage.MembNo, models.NotifyKindStatusChange, "notify.approved_partial",
+			mortgage.ID,
+			contractNo,
+			money.Format(*mortgage.ApprovedAmount),
+			money.Format(mortgage.Amount),
+		)
+		return
+	}
+
 	s.sendLineNotify(message)
+
+	s.notifyMember(mortgage.MembNo, models.NotifyKindStatusChange, "notify.approved",
+		mortgage.ID,
+		contractNo,
+		money.Format(mortgage.Amount),
+	)
 }
 
 // NotifyRejected sends notification for rejected mortgage
@@ -131,6 +346,11 @@ func (s *NotificationService) NotifyRejected(mortgage *models.Mortgage, reason s
 	)
 
 	s.sendLineNotify(message)
+
+	s.notifyMember(mortgage.MembNo, models.NotifyKindStatusChange, "notify.rejected",
+		mortgage.ID,
+		reason,
+	)
 }
 
 // NotifyNewAppointment sends notification for new appointment
@@ -149,6 +369,11 @@ func (s *NotificationService) NotifyNewAppointment(mortgage *models.Mortgage, ap
 	)
 
 	s.sendLineNotify(message)
+
+	s.notifyMember(mortgage.MembNo, models.NotifyKindAppointment, "notify.new_appt",
+		apptType,
+		apptDate,
+	)
 }
 
 // NotifyUpcomingAppointment sends notification for upcoming appointment
@@ -169,6 +394,12 @@ func (s *NotificationService) NotifyUpcomingAppointment(mortgage *models.Mortgag
 	)
 
 	s.sendLineNotify(message)
+
+	s.notifyMember(mortgage.MembNo, models.NotifyKindReminder, "notify.appt_reminder",
+		apptType,
+		apptDate,
+		location,
+	)
 }
 
 // NotifyDocumentComplete sends notification when all documents are submitted
@@ -186,3 +417,30 @@ func (s *NotificationService) NotifyDocumentComplete(mortgage *models.Mortgage)
 
 	s.sendLineNotify(message)
 }
+
+// Subscribe registers the LINE notifier for mortgage events on the bus
+func (s *NotificationService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.MortgageCreated, "line-notify", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageCreatedEvent)
+		s.NotifyNewMortgage(ev.Mortgage, ev.MemberName)
+	})
+	bus.Subscribe(events.MortgageCreated, "line-high-value", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageCreatedEvent)
+		s.NotifyHighValueMortgage(ev.Mortgage, ev.MemberName)
+	})
+	bus.Subscribe(events.MortgageStatusChanged, "line-notify", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageStatusChangedEvent)
+		s.NotifyStatusChange(ev.Mortgage, ev.StepName)
+	})
+	bus.Subscribe(events.MortgageApproved, "line-notify", func(_ context.Context, e events.Event) {
+		s.NotifyApproved(e.(events.MortgageApprovedEvent).Mortgage)
+	})
+	bus.Subscribe(events.MortgageRejected, "line-notify", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageRejectedEvent)
+		s.NotifyRejected(ev.Mortgage, ev.Reason)
+	})
+	bus.Subscribe(events.AppointmentCreated, "line-notify", func(_ context.Context, e events.Event) {
+		ev := e.(events.AppointmentCreatedEvent)
+		s.NotifyNewAppointment(ev.Mortgage, ev.ApptType, ev.ApptDate)
+	})
+}
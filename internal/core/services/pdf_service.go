@@ -0,0 +1,222 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/config"
+	"spsc-loaneasy/internal/pkg/money"
+	"spsc-loaneasy/internal/pkg/timeutil"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ErrPDFUnavailable is returned when the Thai font is missing (core fonts would print Thai as mojibake)
+var ErrPDFUnavailable = errors.New("PDF rendering is unavailable, Thai font not found")
+
+// PDFService renders printable documents
+type PDFService struct {
+	memberRepo      repositories.MemberRepository
+	transactionRepo *repositories.TransactionRepository
+	loanApptRepo    *repositories.LoanApptRepository
+	cfg             config.PDFConfig
+	hasThaiFont     bool
+}
+
+// NewPDFService creates a new PDF service
+func NewPDFService(
+	memberRepo repositories.MemberRepository,
+	transactionRepo *repositories.TransactionRepository,
+	loanApptRepo *repositories.LoanApptRepository,
+	cfg *config.Config,
+) *PDFService {
+	hasThaiFont := fileExists(cfg.PDF.FontPath)
+	if !hasThaiFont {
+		slog.Warn("PDF font not found (set PDF_FONT_PATH), PDF downloads are disabled", "font_path", cfg.PDF.FontPath)
+	}
+
+	return &PDFService{
+		memberRepo:      memberRepo,
+		transactionRepo: transactionRepo,
+		loanApptRepo:    loanApptRepo,
+		cfg:             cfg.PDF,
+		hasThaiFont:     hasThaiFont,
+	}
+}
+
+// MortgageSummary renders a one-page summary of a mortgage case
+func (s *PDFService) MortgageSummary(ctx context.Context, m *models.Mortgage) ([]byte, error) {
+	if !s.hasThaiFont {
+		return nil, ErrPDFUnavailable
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	font := "thai"
+	pdf.AddUTF8Font(font, "", s.cfg.FontPath)
+	boldPath := s.cfg.BoldFontPath
+	if !fileExists(boldPath) {
+		boldPath = s.cfg.FontPath
+	}
+	pdf.AddUTF8Font(font, "B", boldPath)
+
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont(font, "B", 18)
+	pdf.CellFormat(0, 10, s.cfg.CoopName, "", 1, "C", false, 0, "")
+	pdf.SetFont(font, "", 14)
+	pdf.CellFormat(0, 8, "สรุปข้อมูลสินเชื่อจำนอง", "", 1, "C", false, 0, "")
+	pdf.SetFont(font, "", 10)
+	pdf.CellFormat(0, 6, "พิมพ์เมื่อ "+timeutil.Now().Format("2006-01-02 15:04"), "", 1, "R", false, 0, "")
+	pdf.Ln(4)
+
+	// Member
+	fullName, deptName := "-", "-"
+	if member, err := s.memberRepo.GetByMembNo(ctx, m.MembNo); err == nil {
+		fullName = orDash(member.FullName)
+		deptName = orDash(member.DeptName)
+	}
+
+	contractNo := "-"
+	if m.ContractNo != nil && *m.ContractNo != "" {
+		contractNo = *m.ContractNo
+	}
+	loanType, status, officer := "-", "-", "-"
+	if m.LoanType != nil {
+		loanType = m.LoanType.Name
+	}
+	if m.CurrentStep != nil {
+		status = m.CurrentStep.Name
+	}
+	if m.Officer != nil {
+		officer = m.Officer.Username
+	}
+	approvedAt := "-"
+	if m.ApprovedAt != nil {
+		approvedAt = m.ApprovedAt.In(timeutil.Location()).Format("2006-01-02")
+	}
+
+	s.section(pdf, font, "ข้อมูลสินเชื่อ")
+	s.row(pdf, font, "เลขที่สัญญา", contractNo)
+	s.row(pdf, font, "เลขสมาชิก", m.MembNo)
+	s.row(pdf, font, "ชื่อ-สกุล", fullName)
+	s.row(pdf, font, "หน่วยงาน", deptName)
+	s.row(pdf, font, "ประเภทเงินกู้", loanType)
+	s.row(pdf, font, "วงเงิน", money.Format(m.Amount)+" บาท")
+	s.row(pdf, font, "อัตราดอกเบี้ย", strconv.FormatFloat(m.InterestRate, 'f', 2, 64)+" %")
+	s.row(pdf, font, "สถานะ", status)
+	s.row(pdf, font, "เจ้าหน้าที่", officer)
+	s.row(pdf, font, "วันที่ยื่น", m.CreatedAt.In(timeutil.Location()).Format("2006-01-02"))
+	s.row(pdf, font, "วันที่อนุมัติ", approvedAt)
+	if m.Collateral != "" {
+		s.row(pdf, font, "หลักประกัน", m.Collateral)
+	}
+	pdf.Ln(4)
+
+	// Appointments
+	s.section(pdf, font, "นัดหมาย")
+	if m.ApptDate != nil {
+		apptName := "-"
+		if m.CurrentAppt != nil {
+			apptName = m.CurrentAppt.Name
+		}
+		s.row(pdf, font, "นัดหมายปัจจุบัน", apptName)
+		s.row(pdf, font, "วันที่ / เวลา", strings.TrimSpace(m.ApptDate.Format("2006-01-02")+" "+m.ApptTime))
+		s.row(pdf, font, "สถานที่", orDash(m.ApptLocation))
+	} else {
+		s.row(pdf, font, "นัดหมายปัจจุบัน", "-")
+	}
+
+	if history := s.apptHistory(ctx, m.ID); len(history) > 0 {
+		pdf.Ln(2)
+		pdf.SetFont(font, "B", 11)
+		pdf.CellFormat(0, 7, "ประวัตินัดหมาย", "", 1, "L", false, 0, "")
+		pdf.SetFont(font, "", 10)
+		for _, line := range history {
+			pdf.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// apptHistory lists APPT_* transactions, oldest first
+func (s *PDFService) apptHistory(ctx context.Context, mortgageID uint) []string {
+	txs, err := s.transactionRepo.GetByMortgageID(ctx, mortgageID)
+	if err != nil {
+		return nil
+	}
+
+	names := map[uint]string{}
+	if appts, err := s.loanApptRepo.ListAll(ctx); err == nil {
+		for _, a := range appts {
+			names[a.ID] = a.Name
+		}
+	}
+
+	labels := map[string]string{
+		models.TxTypeApptCreate:   "นัดหมาย",
+		models.TxTypeApptComplete: "เสร็จสิ้น",
+		models.TxTypeApptCancel:   "ยกเลิก",
+	}
+
+	var lines []string
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx := txs[i]
+		label, ok := labels[tx.TransactionType]
+		if !ok || tx.ToApptID == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s: %s",
+			tx.CreatedAt.In(timeutil.Location()).Format("2006-01-02 15:04"), label, orDash(names[*tx.ToApptID])))
+	}
+	return lines
+}
+
+func (s *PDFService) section(pdf *gofpdf.Fpdf, font, title string) {
+	pdf.SetFont(font, "B", 12)
+	pdf.SetFillColor(230, 236, 245)
+	pdf.CellFormat(0, 8, title, "", 1, "L", true, 0, "")
+	pdf.Ln(1)
+}
+
+func (s *PDFService) row(pdf *gofpdf.Fpdf, font, label, value string) {
+	pdf.SetFont(font, "B", 11)
+	pdf.CellFormat(45, 7, label, "", 0, "L", false, 0, "")
+	pdf.SetFont(font, "", 11)
+	pdf.MultiCell(0, 7, value, "", "L", false)
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Privacy service errors
+var (
+	ErrExportMemberNotFound = errors.New("member not found")
+	ErrAlreadyAnonymized    = errors.New("user is already anonymized")
+)
+
+// anonymizedEmailDomain marks the email of an anonymized (tombstone) user row
+const anonymizedEmailDomain = "@anonymized.invalid"
+
+// PrivacyService handles PDPA requests on member personal data
+type PrivacyService struct {
+	db        *gorm.DB
+	auditRepo *repositories.AuditLogRepository
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService(db *gorm.DB, auditRepo *repositories.AuditLogRepository) *PrivacyService {
+	return &PrivacyService{db: db, auditRepo: auditRepo}
+}
+
+// ============================================================
+// Member Data Export
+// ============================================================
+
+// MemberExport is the personal data held about one member (GET /members/:memb_no/export)
+// Data of other members is left out: guarantor numbers on the member's cases
+// and borrower numbers on cases the member guarantees
+type MemberExport struct {
+	MembNo       string                         `json:"memb_no"`
+	ExportedAt   time.Time                      `json:"exported_at"`
+	Member       *models.Flommast               `json:"member,omitempty"`
+	Account      *ExportAccount                 `json:"account,omitempty"`
+	Preference   *models.NotificationPreference `json:"notification_preference,omitempty"`
+	Mortgages    []ExportMortgage               `json:"mortgages"`
+	Appointments []ExportAppointment            `json:"appointments"`
+	Guarantees   []ExportGuarantee              `json:"guarantees"`
+}
+
+// ExportAccount is the member's user account with LINE/device identifiers masked
+type ExportAccount struct {
+	*models.UserResponse
+	Phone           string     `json:"phone,omitempty"`
+	LineUserID      string     `json:"line_user_id,omitempty"`
+	DeviceID        string     `json:"device_id,omitempty"`
+	PhoneVerified   string     `json:"phone_verified,omitempty"`
+	NetworkType     string     `json:"network_type,omitempty"`
+	ActiveSessions  int64      `json:"active_sessions"`
+	LastTokenIssued *time.Time `json:"last_token_issued,omitempty"`
+}
+
+// ExportMortgage is one of the member's own cases
+type ExportMortgage struct {
+	ID             uint                 `json:"id"`
+	ContractNo     *string              `json:"contract_no"`
+	LoanType       string               `json:"loan_type"`
+	Amount         float64              `json:"amount"`
+	ApprovedAmount *float64             `json:"approved_amount"`
+	InterestRate   float64              `json:"interest_rate"`
+	Collateral     string               `json:"collateral"`
+	Purpose        string               `json:"purpose"`
+	HasGuarantor   bool                 `json:"has_guarantor"`
+	Status         string               `json:"status"`
+	ApprovedAt     *time.Time           `json:"approved_at"`
+	CreatedAt      time.Time            `json:"created_at"`
+	History        []ExportHistoryEntry `json:"history"`
+}
+
+// ExportHistoryEntry is one transaction on a member's case
+type ExportHistoryEntry struct {
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Amount      *float64  `json:"amount,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ExportAppointment is an appointment on one of the member's cases (current or past)
+type ExportAppointment struct {
+	MortgageID  uint       `json:"mortgage_id"`
+	Name        string     `json:"name"`
+	Date        *time.Time `json:"date,omitempty"`
+	Time        string     `json:"time,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	Status      string     `json:"status"`
+	Attachments []string   `json:"attachments,omitempty"`
+}
+
+// ExportGuarantee is a case the member guarantees (borrower left out)
+type ExportGuarantee struct {
+	MortgageID uint      `json:"mortgage_id"`
+	Amount     float64   `json:"amount"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportMember compiles everything stored about a member and records the export in the audit log
+// The export is refused if the audit entry cannot be written
+func (s *PrivacyService) ExportMember(ctx context.Context, membNo string, actorID uint, ip string) (*MemberExport, error) {
+	db := s.db.WithContext(ctx)
+	export := &MemberExport{
+		MembNo:       membNo,
+		ExportedAt:   time.Now(),
+		Mortgages:    []ExportMortgage{},
+		Appointments: []ExportAppointment{},
+		Guarantees:   []ExportGuarantee{},
+	}
+
+	var member models.Flommast
+	if err := db.Where("MAST_MEMB_NO = ?", membNo).First(&member).Error; err == nil {
+		export.Member = &member
+	}
+
+	var user models.User
+	err := db.Where("memb_no = ?", membNo).First(&user).Error
+	switch {
+	case err == nil:
+		account, err := s.exportAccount(ctx, &user)
+		if err != nil {
+			return nil, err
+		}
+		export.Account = account
+
+		var pref models.NotificationPreference
+		if err := db.Where("user_id = ?", user.ID).First(&pref).Error; err == nil {
+			export.Preference = &pref
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	if export.Member == nil && export.Account == nil {
+		return nil, ErrExportMemberNotFound
+	}
+
+	if err := s.exportMortgages(ctx, export); err != nil {
+		return nil, err
+	}
+	if err := s.exportGuarantees(ctx, export); err != nil {
+		return nil, err
+	}
+
+	entry := &models.AuditLog{
+		Action:       models.AuditActionMemberExport,
+		ActorID:      actorID,
+		TargetMembNo: membNo,
+		IPAddress:    ip,
+	}
+	if export.Account != nil {
+		entry.TargetUserID = &export.Account.ID
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("member data exported",
+		"memb_no", membNo,
+		"actor_id", actorID,
+		"mortgages", len(export.Mortgages),
+		"audit_id", entry.ID,
+	)
+
+	return export, nil
+}
+
+// exportAccount builds the account section, masking LINE/device identifiers
+func (s *PrivacyService) exportAccount(ctx context.Context, user *models.User) (*ExportAccount, error) {
+	db := s.db.WithContext(ctx)
+
+	// phone ไม่มีใน model (LIFF เขียนตรงด้วย SQL)
+	var extra struct{ Phone *string }
+	if err := db.Table("users").Select("phone").Where("id = ?", user.ID).Scan(&extra).Error; err != nil {
+		return nil, err
+	}
+
+	account := &ExportAccount{
+		UserResponse:  user.ToResponse(),
+		Phone:         maskValue(deref(extra.Phone), 3, 3),
+		LineUserID:    maskValue(deref(user.LineUserID), 4, 4),
+		DeviceID:      maskValue(deref(user.DeviceID), 4, 4),
+		PhoneVerified: maskValue(deref(user.PhoneVerified), 3, 3),
+		NetworkType:   deref(user.NetworkType),
+	}
+
+	if err := db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Count(&account.ActiveSessions).Error; err != nil {
+		return nil, err
+	}
+
+	var last models.RefreshToken
+	if err := db.Where("user_id = ?", user.ID).Order("created_at DESC").First(&last).Error; err == nil {
+		account.LastTokenIssued = &last.CreatedAt
+	}
+
+	return account, nil
+}
+
+// exportMortgages adds the member's cases, their history and appointments
+func (s *PrivacyService) exportMortgages(ctx context.Context, export *MemberExport) error {
+	db := s.db.WithContext(ctx)
+
+	var mortgages []*models.Mortgage
+	if err := db.Preload("LoanType").Preload("CurrentStep").Preload("CurrentAppt").
+		Where("memb_no = ?", export.MembNo).
+		Order("created_at ASC").
+		Find(&mortgages).Error; err != nil {
+		return err
+	}
+
+	for _, m := range mortgages {
+		item := ExportMortgage{
+			ID:             m.ID,
+			ContractNo:     m.ContractNo,
+			Amount:         m.Amount,
+			ApprovedAmount: m.ApprovedAmount,
+			InterestRate:   m.InterestRate,
+			Collateral:     m.Collateral,
+			Purpose:        m.Purpose,
+			HasGuarantor:   m.GuarantorMembNo != nil && *m.GuarantorMembNo != "",
+			ApprovedAt:     m.ApprovedAt,
+			CreatedAt:      m.CreatedAt,
+			History:        []ExportHistoryEntry{},
+		}
+		if m.LoanType != nil {
+			item.LoanType = m.LoanType.Name
+		}
+		if m.CurrentStep != nil {
+			item.Status = m.CurrentStep.Name
+		}
+
+		var txs []*models.Transaction
+		if err := db.Where("mortgage_id = ?", m.ID).Order("created_at ASC").Find(&txs).Error; err != nil {
+			return err
+		}
+
+		apptStatus := models.ApptStatusPending
+		for _, tx := range txs {
+			item.History = append(item.History, ExportHistoryEntry{
+				Type:        tx.TransactionType,
+				Description: tx.Description,
+				Amount:      tx.Amount,
+				CreatedAt:   tx.CreatedAt,
+			})
+			switch tx.TransactionType {
+			case models.TxTypeApptCreate:
+				apptStatus = models.ApptStatusPending
+			case models.TxTypeApptComplete:
+				apptStatus = models.ApptStatusCompleted
+			case models.TxTypeApptCancel:
+				apptStatus = models.ApptStatusCancelled
+			}
+		}
+		export.Mortgages = append(export.Mortgages, item)
+
+		if m.CurrentApptID != nil {
+			appt := ExportAppointment{
+				MortgageID: m.ID,
+				Date:       m.ApptDate,
+				Time:       m.ApptTime,
+				Location:   m.ApptLocation,
+				Status:     apptStatus,
+			}
+			if m.CurrentAppt != nil {
+				appt.Name = m.CurrentAppt.Name
+			}
+
+			var files []*models.ApptAttachment
+			if err := db.Where("mortgage_id = ?", m.ID).Order("created_at ASC").Find(&files).Error; err != nil {
+				return err
+			}
+			for _, f := range files {
+				appt.Attachments = append(appt.Attachments, f.FileName)
+			}
+			export.Appointments = append(export.Appointments, appt)
+		}
+	}
+
+	return nil
+}
+
+// exportGuarantees adds the cases the member guarantees, without the borrower
+func (s *PrivacyService) exportGuarantees(ctx context.Context, export *MemberExport) error {
+	var mortgages []*models.Mortgage
+	if err := s.db.WithContext(ctx).Preload("CurrentStep").
+		Where("guarantor_memb_no = ?", export.MembNo).
+		Order("created_at ASC").
+		Find(&mortgages).Error; err != nil {
+		return err
+	}
+
+	for _, m := range mortgages {
+		item := ExportGuarantee{
+			MortgageID: m.ID,
+			Amount:     m.Amount,
+			CreatedAt:  m.CreatedAt,
+		}
+		if m.CurrentStep != nil {
+			item.Status = m.CurrentStep.Name
+		}
+		export.Guarantees = append(export.Guarantees, item)
+	}
+	return nil
+}
+
+// ============================================================
+// Erasure (Anonymization)
+// ============================================================
+
+// AnonymizeResult reports what an anonymization changed
+type AnonymizeResult struct {
+	UserID        uint   `json:"user_id"`
+	Tombstone     string `json:"tombstone"`
+	RevokedTokens int64  `json:"revoked_tokens"`
+}
+
+// AnonymizeUser scrubs the personal data of a user row for a PDPA erasure request
+//
+// The row is kept as a tombstone (ANON-<id>) so mortgages and transactions that reference
+// the user id still resolve for accounting. Name, phone, LINE and device data are cleared,
+// the account is deactivated and soft-deleted, and all refresh tokens are revoked. Access
+// tokens already issued are refused on their next request because AuthMiddleware checks
+// that the user is still active.
+//
+// Only app-side data is erased: flommast is the read-only member register owned by the
+// co-op system, and mortgages keep their memb_no because it points at that register.
+func (s *PrivacyService) AnonymizeUser(ctx context.Context, id, adminID uint, ip string) (*AnonymizeResult, error) {
+	if id == adminID {
+		return nil, ErrCannotDeleteSelf
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Unscoped().First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFoundSvc
+		}
+		return nil, err
+	}
+	if strings.HasSuffix(user.Email, anonymizedEmailDomain) {
+		return nil, ErrAlreadyAnonymized
+	}
+
+	now := time.Now()
+	tombstone := fmt.Sprintf("ANON-%d", user.ID)
+	result := &AnonymizeResult{UserID: user.ID, Tombstone: tombstone}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// full_name / dept_name / phone ไม่มีใน model (LIFF เขียนตรงด้วย SQL)
+		if err := tx.Table("users").Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"memb_no":           tombstone,
+			"username":          tombstone,
+			"email":             strings.ToLower(tombstone) + anonymizedEmailDomain,
+			"password":          "",
+			"is_active":         false,
+			"full_name":         nil,
+			"dept_name":         nil,
+			"phone":             nil,
+			"line_user_id":      nil,
+			"line_display_name": nil,
+			"line_picture_url":  nil,
+			"line_linked_at":    nil,
+			"device_id":         nil,
+			"phone_verified":    nil,
+			"network_type":      nil,
+			"last_login":        nil,
+			"updated_at":        now,
+			"deleted_at":        gorm.Expr("COALESCE(deleted_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+
+		revoked := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", now)
+		if revoked.Error != nil {
+			return revoked.Error
+		}
+		result.RevokedTokens = revoked.RowsAffected
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+
+		if user.IsLineLinked() {
+			if err := tx.Model(&models.ReminderLog{}).
+				Where("line_user_id = ?", *user.LineUserID).
+				Update("line_user_id", "").Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.AuditLog{
+			Action:       models.AuditActionUserAnonymize,
+			ActorID:      adminID,
+			TargetUserID: &user.ID,
+			TargetMembNo: user.MembNo,
+			Detail:       fmt.Sprintf("tombstone=%s revoked_tokens=%d", tombstone, result.RevokedTokens),
+			IPAddress:    ip,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("user anonymized",
+		"target_user_id", user.ID,
+		"actor_id", adminID,
+		"revoked_tokens", result.RevokedTokens,
+	)
+
+	return result, nil
+}
+
+// maskValue keeps the first head and last tail characters, e.g. U1a2XXXXf9e8
+func maskValue(value string, head, tail int) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= head+tail {
+		return strings.Repeat("X", len(value))
+	}
+	return value[:head] + strings.Repeat("X", len(value)-head-tail) + value[len(value)-tail:]
+}
+
+// deref returns the string a pointer points to, or "" for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"spsc-loaneasy/internal/adapters/persistence/models"
 	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/pkg/i18n"
+	"spsc-loaneasy/internal/pkg/money"
 	"spsc-loaneasy/internal/pkg/password"
 
 	"gorm.io/gorm"
@@ -18,22 +21,28 @@ var (
 	ErrOldPasswordWrong   = errors.New("old password is incorrect")
 	ErrCannotDeleteSelf   = errors.New("cannot delete your own account")
 	ErrCannotChangeOwnRole = errors.New("cannot change your own role")
+	ErrInvalidLanguage    = errors.New("unsupported language")
+	ErrInvalidApprovalLimit = errors.New("approval limit must not be negative")
+	ErrCannotChangeOwnLimit = errors.New("cannot change your own approval limit")
 )
 
 // UserService handles user management business logic
 type UserService struct {
 	userRepo   repositories.UserRepository
 	memberRepo repositories.MemberRepository
+	prefRepo   *repositories.NotificationPreferenceRepository
 }
 
 // NewUserService creates a new user service
 func NewUserService(
 	userRepo repositories.UserRepository,
 	memberRepo repositories.MemberRepository,
+	prefRepo *repositories.NotificationPreferenceRepository,
 ) *UserService {
 	return &UserService{
 		userRepo:   userRepo,
 		memberRepo: memberRepo,
+		prefRepo:   prefRepo,
 	}
 }
 
@@ -58,6 +67,9 @@ type UpdateUserByAdminInput struct {
 	Email    *string `json:"email"`
 	Role     *string `json:"role"`
 	IsActive *bool   `json:"is_active"`
+
+	ApprovalLimit      *float64 `json:"approval_limit"`       // 0 = no limit
+	ResetApprovalLimit bool     `json:"reset_approval_limit"` // back to the role default
 }
 
 // UpdateProfileInput represents update profile input (for self)
@@ -155,6 +167,11 @@ func (s *UserService) UpdateUserByAdmin(ctx context.Context, id uint, adminID ui
 		return nil, ErrCannotChangeOwnRole
 	}
 
+	// Nobody sets their own approval limit (0 would lift it entirely)
+	if id == adminID && (input.ApprovalLimit != nil || input.ResetApprovalLimit) {
+		return nil, ErrCannotChangeOwnLimit
+	}
+
 	// Update fields
 	if input.Email != nil && *input.Email != user.Email {
 		// Check if email already exists
@@ -177,6 +194,17 @@ func (s *UserService) UpdateUserByAdmin(ctx context.Context, id uint, adminID ui
 		user.IsActive = *input.IsActive
 	}
 
+	if input.ApprovalLimit != nil {
+		if *input.ApprovalLimit < 0 {
+			return nil, ErrInvalidApprovalLimit
+		}
+		limit := money.Round(*input.ApprovalLimit)
+		user.ApprovalLimit = &limit
+	}
+	if input.ResetApprovalLimit {
+		user.ApprovalLimit = nil
+	}
+
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
@@ -249,6 +277,51 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID uint, input *Upd
 	return response, nil
 }
 
+// UpdateNotificationPreferenceInput represents notification preference changes
+// Fields left nil keep their current value
+type UpdateNotificationPreferenceInput struct {
+	Reminder     *bool `json:"reminder"`
+	StatusChange *bool `json:"status_change"`
+	Appointment  *bool   `json:"appointment"`
+	Language     *string `json:"language"` // th / en
+}
+
+// GetNotificationPreference gets own LINE notification preference
+func (s *UserService) GetNotificationPreference(ctx context.Context, userID uint) (*models.NotificationPreference, error) {
+	return s.prefRepo.GetByUserID(ctx, userID)
+}
+
+// UpdateNotificationPreference updates own LINE notification preference
+func (s *UserService) UpdateNotificationPreference(ctx context.Context, userID uint, input *UpdateNotificationPreferenceInput) (*models.NotificationPreference, error) {
+	pref, err := s.prefRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Reminder != nil {
+		pref.Reminder = *input.Reminder
+	}
+	if input.StatusChange != nil {
+		pref.StatusChange = *input.StatusChange
+	}
+	if input.Appointment != nil {
+		pref.Appointment = *input.Appointment
+	}
+	if input.Language != nil {
+		lang := strings.ToLower(strings.TrimSpace(*input.Language))
+		if lang != i18n.TH && lang != i18n.EN {
+			return nil, ErrInvalidLanguage
+		}
+		pref.Language = lang
+	}
+
+	if err := s.prefRepo.Upsert(ctx, pref); err != nil {
+		return nil, err
+	}
+
+	return pref, nil
+}
+
 // ChangePassword changes user's password
 func (s *UserService) ChangePassword(ctx context.Context, userID uint, input *ChangePasswordInput) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
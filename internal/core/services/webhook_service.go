@@ -0,0 +1,406 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"spsc-loaneasy/internal/adapters/persistence/models"
+	"spsc-loaneasy/internal/adapters/persistence/repositories"
+	"spsc-loaneasy/internal/core/events"
+)
+
+var (
+	ErrWebhookNotFound   = errors.New("webhook subscription not found")
+	ErrWebhookInvalidURL = errors.New("webhook url must be a valid http(s) url")
+	ErrWebhookNoEvents   = errors.New("at least one event type is required")
+	ErrWebhookBadEvent   = errors.New("unknown event type")
+)
+
+// webhookRetryDelays are the waits between delivery attempts (attempt 1 is immediate)
+var webhookRetryDelays = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+// WebhookEventTypes lists the events a subscription can receive
+var WebhookEventTypes = []string{
+	models.WebhookEventMortgageApproved,
+	models.WebhookEventMortgageRejected,
+	models.WebhookEventMortgageStatusChanged,
+}
+
+// WebhookService handles outbound webhook subscriptions and signed deliveries
+type WebhookService struct {
+	webhookRepo *repositories.WebhookRepository
+	client      *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhookRepo *repositories.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WebhookEnvelope is the JSON body POSTed to subscribers
+type WebhookEnvelope struct {
+	ID        uint        `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// ============================================================
+// Subscriptions
+// ============================================================
+
+type WebhookInput struct {
+	URL         string   `json:"url" validate:"required,url"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1"`
+	Description string   `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	return s.webhookRepo.ListAll(ctx)
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+// Create creates a subscription with a generated signing secret
+func (s *WebhookService) Create(ctx context.Context, input *WebhookInput, userID uint) (*models.WebhookSubscription, error) {
+	eventTypes, err := normalizeWebhookInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:         input.URL,
+		EventTypes:  eventTypes,
+		Secret:      secret,
+		Description: input.Description,
+		IsActive:    true,
+		CreatedBy:   userID,
+	}
+	if input.IsActive != nil {
+		sub.IsActive = *input.IsActive
+	}
+
+	if err := s.webhookRepo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Update updates a subscription (secret is kept)
+func (s *WebhookService) Update(ctx context.Context, id uint, input *WebhookInput) (*models.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	eventTypes, err := normalizeWebhookInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.URL = input.URL
+	sub.EventTypes = eventTypes
+	sub.Description = input.Description
+	if input.IsActive != nil {
+		sub.IsActive = *input.IsActive
+	}
+
+	if err := s.webhookRepo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RotateSecret generates a new signing secret
+func (s *WebhookService) RotateSecret(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = secret
+
+	if err := s.webhookRepo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.webhookRepo.GetByID(ctx, id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return s.webhookRepo.Delete(ctx, id)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, id uint) ([]*models.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetByID(ctx, id); err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, 50)
+}
+
+// ============================================================
+// Delivery
+// ============================================================
+
+// Subscribe forwards mortgage status events from the bus to webhook subscriptions
+func (s *WebhookService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.MortgageStatusChanged, "webhooks", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageStatusChangedEvent)
+		s.publishMortgage(models.WebhookEventMortgageStatusChanged, ev.Mortgage, ev.FromStepID, ev.ToStepID)
+	})
+	bus.Subscribe(events.MortgageApproved, "webhooks", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageApprovedEvent)
+		s.publishMortgage(models.WebhookEventMortgageApproved, ev.Mortgage, ev.FromStepID, ev.ToStepID)
+	})
+	bus.Subscribe(events.MortgageRejected, "webhooks", func(_ context.Context, e events.Event) {
+		ev := e.(events.MortgageRejectedEvent)
+		s.publishMortgage(models.WebhookEventMortgageRejected, ev.Mortgage, ev.FromStepID, ev.ToStepID)
+	})
+}
+
+// publishMortgage publishes the standard mortgage payload
+func (s *WebhookService) publishMortgage(event string, mortgage *models.Mortgage, fromStepID, toStepID uint) {
+	s.Publish(event, map[string]interface{}{
+		"mortgage":     mortgage.ToResponse(),
+		"from_step_id": fromStepID,
+		"to_step_id":   toStepID,
+	})
+}
+
+// Publish enqueues an event for every active subscription and sends it in the background
+func (s *WebhookService) Publish(event string, data interface{}) {
+	ctx := context.Background()
+
+	subs, err := s.webhookRepo.ListActive(ctx)
+	if err != nil {
+		slog.Error("webhook: failed to list subscriptions", "event", event, "error", err)
+		return
+	}
+
+	for _, sub := range subs {
+		if !subscribedTo(sub, event) {
+			continue
+		}
+
+		// First attempt runs right away below; the retry job only picks it up if that attempt never finishes
+		next := time.Now().Add(webhookRetryDelays[0])
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventType:      event,
+			Payload:        "{}",
+			Status:         models.WebhookStatusPending,
+			NextAttemptAt:  &next,
+		}
+		if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			slog.Error("webhook: failed to enqueue delivery", "event", event, "subscription_id", sub.ID, "error", err)
+			continue
+		}
+
+		payload, err := json.Marshal(WebhookEnvelope{
+			ID:        delivery.ID,
+			Event:     event,
+			CreatedAt: delivery.CreatedAt,
+			Data:      data,
+		})
+		if err != nil {
+			slog.Error("webhook: failed to marshal payload", "event", event, "delivery_id", delivery.ID, "error", err)
+			continue
+		}
+		delivery.Payload = string(payload)
+		if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			slog.Error("webhook: failed to save payload", "event", event, "delivery_id", delivery.ID, "error", err)
+			continue
+		}
+
+		go s.attempt(sub, delivery)
+	}
+}
+
+// RetryPending re-sends deliveries whose next attempt is due (called by cron)
+func (s *WebhookService) RetryPending() {
+	ctx := context.Background()
+
+	deliveries, err := s.webhookRepo.ListDueDeliveries(ctx, time.Now(), 100)
+	if err != nil {
+		slog.Error("webhook: failed to list pending deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		sub, err := s.webhookRepo.GetByID(ctx, delivery.SubscriptionID)
+		if err != nil || !sub.IsActive {
+			delivery.Status = models.WebhookStatusFailed
+			delivery.LastError = "subscription removed or inactive"
+			delivery.NextAttemptAt = nil
+			s.webhookRepo.UpdateDelivery(ctx, delivery)
+			continue
+		}
+		s.attempt(sub, delivery)
+	}
+}
+
+// attempt sends one delivery and schedules the next retry on failure
+func (s *WebhookService) attempt(sub *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	ctx := context.Background()
+	log := slog.With("event", delivery.EventType, "delivery_id", delivery.ID, "subscription_id", sub.ID)
+
+	delivery.Attempts++
+	code, err := s.send(sub, delivery)
+	delivery.ResponseCode = code
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookStatusSuccess
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		log.Info("webhook delivered", "url", sub.URL, "attempt", delivery.Attempts)
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts > len(webhookRetryDelays) {
+			delivery.Status = models.WebhookStatusFailed
+			delivery.NextAttemptAt = nil
+			log.Error("webhook delivery failed, giving up", "attempts", delivery.Attempts, "error", err)
+		} else {
+			next := time.Now().Add(webhookRetryDelays[delivery.Attempts-1])
+			delivery.NextAttemptAt = &next
+			log.Warn("webhook delivery failed, will retry", "attempt", delivery.Attempts, "next_attempt_at", next, "error", err)
+		}
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Error("webhook: failed to update delivery", "error", err)
+	}
+}
+
+// send POSTs the payload signed with the subscription secret
+// Receivers verify X-Webhook-Signature = "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+func (s *WebhookService) send(sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LoanEasy-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of timestamp + "." + body
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscribedTo checks whether a subscription wants an event
+func subscribedTo(sub *models.WebhookSubscription, event string) bool {
+	for _, e := range strings.Split(sub.EventTypes, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWebhookInput validates the url and event types, returning them comma-joined
+func normalizeWebhookInput(input *WebhookInput) (string, error) {
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrWebhookInvalidURL
+	}
+
+	events := make([]string, 0, len(input.EventTypes))
+	for _, e := range input.EventTypes {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if e != "*" && !isWebhookEventType(e) {
+			return "", fmt.Errorf("%w: %s", ErrWebhookBadEvent, e)
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return "", ErrWebhookNoEvents
+	}
+	return strings.Join(events, ","), nil
+}
+
+func isWebhookEventType(event string) bool {
+	for _, e := range WebhookEventTypes {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
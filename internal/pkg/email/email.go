@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress is returned for recipients that cannot receive mail
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Sender delivers a plain text email
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPConfig holds SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // empty = no AUTH (e.g. local relay)
+	Password string
+	From     string // e.g. "สหกรณ์ SPSC <no-reply@spsc.or.th>"
+}
+
+// SMTPSender sends mail through an SMTP server (STARTTLS is used when the server offers it)
+type SMTPSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPSender validates the config
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP from address %q: %w", cfg.From, err)
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	return &SMTPSender{cfg: cfg, from: from}, nil
+}
+
+// Send sends a UTF-8 plain text message
+// net/smtp has no context support, so ctx is only checked before connecting
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !IsDeliverable(to) {
+		return ErrInvalidAddress
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, fmt.Sprint(s.cfg.Port))
+	return smtp.SendMail(addr, auth, s.from.Address, []string{to}, s.message(to, subject, body))
+}
+
+// message builds the RFC 5322 message; Thai subject and body are encoded for 7-bit transports
+func (s *SMTPSender) message(to, subject, body string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
+
+// IsDeliverable reports whether an address looks like a real mailbox
+// Rejects empty/malformed values and reserved domains such as anonymized placeholders (*.invalid)
+func IsDeliverable(address string) bool {
+	addr, err := mail.ParseAddress(address)
+	if err != nil || addr.Address != address {
+		return false
+	}
+	domain := strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+	if !strings.Contains(domain, ".") {
+		return false
+	}
+	for _, reserved := range []string{".invalid", ".example", ".test", ".localhost"} {
+		if strings.HasSuffix(domain, reserved) {
+			return false
+		}
+	}
+	return true
+}
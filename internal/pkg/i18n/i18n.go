@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Supported languages
+const (
+	TH = "th"
+	EN = "en"
+
+	// Default is used when no language is requested or the requested one is not supported
+	Default = TH
+)
+
+// Normalize maps a language tag (e.g. "en-US", "TH") to a supported language
+// Unsupported or empty tags return Default
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if _, ok := bundles[lang]; ok {
+		return lang
+	}
+	return Default
+}
+
+// T returns the message for key in lang, formatted with args
+// Falls back to the Default bundle, then to the key itself
+func T(lang, key string, args ...interface{}) string {
+	msg, ok := bundles[Normalize(lang)][key]
+	if !ok {
+		if msg, ok = bundles[Default][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// FromRequest picks the language from the ?lang= query or the Accept-Language header
+func FromRequest(c *fiber.Ctx) string {
+	if lang := c.Query("lang"); lang != "" {
+		return Normalize(lang)
+	}
+	if lang := c.AcceptsLanguages(TH, EN); lang != "" {
+		return Normalize(lang)
+	}
+	return Default
+}
@@ -0,0 +1,134 @@
+package i18n
+
+// bundles holds the messages per language, keyed by message id
+// เพิ่ม key ใหม่ต้องใส่ทั้ง th และ en (ถ้าไม่มี en จะ fallback เป็นภาษาไทย)
+var bundles = map[string]map[string]string{
+	TH: {
+		// Common
+		"common.invalid_body":    "ข้อมูลไม่ถูกต้อง",
+		"common.missing_fields":  "กรุณาระบุข้อมูลให้ครบ",
+		"common.token_failed":    "ไม่สามารถสร้าง Token ได้",
+		"common.member_notfound": "ไม่พบเลขสมาชิกนี้ในระบบ",
+		"common.invalid_memb_no": "เลขสมาชิกต้องเป็นตัวเลขไม่เกิน 5 หลัก",
+		"common.user_notfound":   "ไม่พบผู้ใช้ในระบบ",
+
+		// LIFF
+		"liff.token_required":        "กรุณาระบุ LINE Access Token",
+		"liff.token_invalid":         "LINE Token ไม่ถูกต้อง",
+		"liff.token_invalid_relogin": "LINE Token ไม่ถูกต้อง กรุณา login LINE ใหม่",
+		"liff.device_required":       "กรุณาระบุ Device ID",
+		"liff.otp_required":          "กรุณาระบุรหัส OTP",
+		"liff.phone_mismatch":        "เบอร์โทรไม่ตรงกับข้อมูลสมาชิก",
+		"liff.line_registered":       "LINE นี้ลงทะเบียนแล้ว",
+		"liff.device_taken":          "เครื่องนี้ลงทะเบียนกับบัญชีอื่นแล้ว",
+		"liff.device_taken_contact":  "เครื่องนี้ลงทะเบียนกับบัญชีอื่นแล้ว กรุณาติดต่อสหกรณ์",
+		"liff.device_mismatch":       "เครื่องนี้ไม่ตรงกับที่ลงทะเบียนไว้ กรุณาติดต่อสหกรณ์เพื่อเปลี่ยนเครื่อง",
+		"liff.user_not_registered":   "ไม่พบผู้ใช้ในระบบ กรุณาลงทะเบียน",
+		"liff.wifi_not_allowed":      "กรุณาใช้อินเทอร์เน็ตมือถือ (Cellular) ในการเข้าสู่ระบบ ไม่สามารถใช้ WiFi ได้",
+		"liff.check_ok":              "ตรวจสอบสำเร็จ",
+		"liff.otp_sent":              "ส่ง OTP สำเร็จ",
+		"liff.otp_verified":          "ยืนยัน OTP สำเร็จ",
+		"liff.line_linked":           "ผูก LINE กับบัญชีสำเร็จ",
+		"liff.registered":            "ลงทะเบียนสำเร็จ",
+		"liff.login_ok":              "เข้าสู่ระบบสำเร็จ",
+		"liff.device_changed":        "เปลี่ยนเครื่องสำเร็จ",
+		"liff.device_info":           "ข้อมูลอุปกรณ์",
+		"liff.otp_message":           "รหัส OTP ของคุณคือ: %s (หมดอายุใน 5 นาที) - สหกรณ์ SPSC",
+
+		// Rate limits (limit, window seconds, retry after seconds)
+		"ratelimit.auth":     "คุณพยายามเข้าสู่ระบบเกิน %d ครั้งใน %d วินาที กรุณารอ %d วินาทีแล้วลองใหม่",
+		"ratelimit.strict":   "ส่งคำขอเกิน %d ครั้งใน %d วินาที กรุณารอ %d วินาทีแล้วลองใหม่",
+		"ratelimit.write":    "คุณบันทึกข้อมูลบ่อยเกินไป (ได้ %d ครั้งใน %d วินาที) กรุณารอ %d วินาที",
+		"ratelimit.reminder": "ส่งแจ้งเตือนนัดหมายนี้ไปแล้ว (ได้ %d ครั้งใน %d วินาที) กรุณารอ %d วินาที",
+
+		// Member LINE notifications
+		"notify.status_change":    "🔄 คำขอสินเชื่อ #%d ของคุณเปลี่ยนสถานะเป็น: %s",
+		"notify.approved":         "✅ คำขอสินเชื่อ #%d ของคุณได้รับการอนุมัติแล้ว\n📋 เลขสัญญา: %s\n💰 จำนวนเงิน: %s บาท",
+		"notify.approved_partial": "✅ คำขอสินเชื่อ #%d ของคุณได้รับการอนุมัติแล้ว\n📋 เลขสัญญา: %s\n💰 วงเงินที่อนุมัติ: %s บาท (ขอกู้ %s บาท)",
+		"notify.rejected":         "❌ คำขอสินเชื่อ #%d ของคุณไม่ได้รับการอนุมัติ\n📝 เหตุผล: %s",
+		"notify.new_appt":         "📅 คุณมีนัดหมายใหม่กับสหกรณ์\n📌 ประเภท: %s\n📆 วันที่: %s",
+		"notify.appt_reminder":    "⏰ แจ้งเตือนนัดหมาย\n📌 ประเภท: %s\n📆 วันที่: %s\n📍 สถานที่: %s",
+
+		// Member email subjects (email is sent only to members without LINE)
+		"email.subject.approved": "คำขอสินเชื่อของคุณได้รับการอนุมัติ",
+		"email.subject.rejected": "ผลการพิจารณาคำขอสินเชื่อ",
+		"email.subject.appt":     "นัดหมายกับสหกรณ์",
+
+		// Member activity feed (mortgage id, then name of the step / document / appointment)
+		"activity.CREATE":        "ยื่นคำขอสินเชื่อ #%d",
+		"activity.UPDATE":        "แก้ไขข้อมูลคำขอสินเชื่อ #%d",
+		"activity.STATUS_CHANGE": "คำขอสินเชื่อ #%d เปลี่ยนสถานะเป็น %s",
+		"activity.TYPE_CHANGE":   "เปลี่ยนประเภทสินเชื่อของคำขอ #%d",
+		"activity.DOC_CHECK":     "คำขอสินเชื่อ #%d: ได้รับเอกสาร %s",
+		"activity.DOC_UNCHECK":   "คำขอสินเชื่อ #%d: เอกสาร %s ต้องส่งใหม่",
+		"activity.APPT_CREATE":   "คำขอสินเชื่อ #%d: นัดหมาย %s",
+		"activity.APPT_COMPLETE": "คำขอสินเชื่อ #%d: นัดหมาย %s เสร็จสิ้น",
+		"activity.APPT_CANCEL":   "คำขอสินเชื่อ #%d: ยกเลิกนัดหมาย %s",
+		"activity.APPROVE":       "คำขอสินเชื่อ #%d ได้รับการอนุมัติ",
+		"activity.REJECT":        "คำขอสินเชื่อ #%d ไม่ได้รับการอนุมัติ",
+	},
+	EN: {
+		// Common
+		"common.invalid_body":    "Invalid request data",
+		"common.missing_fields":  "Please fill in all required fields",
+		"common.token_failed":    "Could not create a token",
+		"common.member_notfound": "Member number not found",
+		"common.invalid_memb_no": "Member number must be numeric, up to 5 digits",
+		"common.user_notfound":   "User not found",
+
+		// LIFF
+		"liff.token_required":        "LINE Access Token is required",
+		"liff.token_invalid":         "Invalid LINE token",
+		"liff.token_invalid_relogin": "Invalid LINE token, please log in to LINE again",
+		"liff.device_required":       "Device ID is required",
+		"liff.otp_required":          "OTP code is required",
+		"liff.phone_mismatch":        "Phone number does not match the member record",
+		"liff.line_registered":       "This LINE account is already registered",
+		"liff.device_taken":          "This device is already registered to another account",
+		"liff.device_taken_contact":  "This device is already registered to another account, please contact the cooperative",
+		"liff.device_mismatch":       "This device does not match the registered one, please contact the cooperative to change devices",
+		"liff.user_not_registered":   "User not found, please register",
+		"liff.wifi_not_allowed":      "Please use mobile data (cellular) to log in; WiFi is not allowed",
+		"liff.check_ok":              "Check completed",
+		"liff.otp_sent":              "OTP sent",
+		"liff.otp_verified":          "OTP verified",
+		"liff.line_linked":           "LINE linked to your account",
+		"liff.registered":            "Registration completed",
+		"liff.login_ok":              "Logged in",
+		"liff.device_changed":        "Device changed",
+		"liff.device_info":           "Device information",
+		"liff.otp_message":           "Your OTP code is: %s (expires in 5 minutes) - SPSC Cooperative",
+
+		// Rate limits (limit, window seconds, retry after seconds)
+		"ratelimit.auth":     "Too many login attempts (limit %d per %d seconds). Please wait %d seconds and try again",
+		"ratelimit.strict":   "Too many requests (limit %d per %d seconds). Please wait %d seconds and try again",
+		"ratelimit.write":    "Too many changes (limit %d per %d seconds). Please wait %d seconds",
+		"ratelimit.reminder": "A reminder for this appointment was already sent (limit %d per %d seconds). Please wait %d seconds",
+
+		// Member LINE notifications
+		"notify.status_change":    "🔄 Your loan request #%d is now: %s",
+		"notify.approved":         "✅ Your loan request #%d has been approved\n📋 Contract no.: %s\n💰 Amount: %s THB",
+		"notify.approved_partial": "✅ Your loan request #%d has been approved\n📋 Contract no.: %s\n💰 Approved amount: %s THB (requested %s THB)",
+		"notify.rejected":         "❌ Your loan request #%d was not approved\n📝 Reason: %s",
+		"notify.new_appt":         "📅 You have a new appointment with the cooperative\n📌 Type: %s\n📆 Date: %s",
+		"notify.appt_reminder":    "⏰ Appointment reminder\n📌 Type: %s\n📆 Date: %s\n📍 Location: %s",
+
+		// Member email subjects (email is sent only to members without LINE)
+		"email.subject.approved": "Your loan request has been approved",
+		"email.subject.rejected": "Loan request decision",
+		"email.subject.appt":     "Your appointment with the cooperative",
+
+		// Member activity feed (mortgage id, then name of the step / document / appointment)
+		"activity.CREATE":        "Loan request #%d submitted",
+		"activity.UPDATE":        "Loan request #%d updated",
+		"activity.STATUS_CHANGE": "Loan request #%d moved to %s",
+		"activity.TYPE_CHANGE":   "Loan type of request #%d changed",
+		"activity.DOC_CHECK":     "Loan request #%d: document received - %s",
+		"activity.DOC_UNCHECK":   "Loan request #%d: document needs resubmitting - %s",
+		"activity.APPT_CREATE":   "Loan request #%d: appointment set - %s",
+		"activity.APPT_COMPLETE": "Loan request #%d: appointment completed - %s",
+		"activity.APPT_CANCEL":   "Loan request #%d: appointment cancelled - %s",
+		"activity.APPROVE":       "Loan request #%d approved",
+		"activity.REJECT":        "Loan request #%d was not approved",
+	},
+}
@@ -18,6 +18,9 @@ type Claims struct {
 	MembNo   string `json:"memb_no"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+
+	// ImpersonatedBy is the admin user ID for support tokens issued by GenerateImpersonationToken (0 = normal login)
+	ImpersonatedBy uint `json:"impersonated_by,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -48,6 +51,28 @@ func GenerateAccessToken(userID uint, membNo, username, role, secret string, exp
 	return token.SignedString([]byte(secret))
 }
 
+// GenerateImpersonationToken generates an access token for userID on behalf of an admin
+// There is no matching refresh token; the session ends when this token expires
+func GenerateImpersonationToken(userID uint, membNo, username, role string, adminID uint, secret string, expiryMinutes int) (string, error) {
+	claims := Claims{
+		UserID:         userID,
+		MembNo:         membNo,
+		Username:       username,
+		Role:           role,
+		ImpersonatedBy: adminID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiryMinutes) * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "spsc-loaneasy",
+			Subject:   membNo,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
 // GenerateRefreshToken generates a new refresh token
 func GenerateRefreshToken(userID uint, tokenID, secret string, expiryDays int) (string, error) {
 	claims := RefreshClaims{
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Init sets the process-wide slog logger
+// format: "json" (prod) or "text" (dev, human-readable); level: debug / info / warn / error
+// The standard log package is routed through the same handler, so existing log.Printf calls
+// end up in the same output at INFO level
+func Init(format, level string) {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+}
+
+// FromContext returns the default logger with the request fields found in ctx
+// Fiber handlers pass c.Context(), whose Value() exposes the request locals
+// (request id from the requestid middleware, userID / username from AuthMiddleware)
+func FromContext(ctx context.Context) *slog.Logger {
+	l := slog.Default()
+	if ctx == nil {
+		return l
+	}
+
+	if id := requestID(ctx); id != "" {
+		l = l.With("request_id", id)
+	}
+	if uid := userID(ctx); uid > 0 {
+		l = l.With("user_id", uid)
+	}
+	return l
+}
+
+// ctxKey holds the request fields copied by Detach
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userIDKey
+)
+
+// Detach returns a new background context carrying only the request fields of ctx
+// Use it for work that outlives the request: fasthttp reuses c.Context() for later requests
+func Detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	if ctx == nil {
+		return detached
+	}
+
+	if id := requestID(ctx); id != "" {
+		detached = context.WithValue(detached, requestIDKey, id)
+	}
+	if uid := userID(ctx); uid > 0 {
+		detached = context.WithValue(detached, userIDKey, uid)
+	}
+	return detached
+}
+
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	id, _ := ctx.Value("requestid").(string)
+	return id
+}
+
+func userID(ctx context.Context) uint {
+	if id, ok := ctx.Value(userIDKey).(uint); ok {
+		return id
+	}
+	id, _ := ctx.Value("userID").(uint)
+	return id
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,56 @@
+package maintenance
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// State is the current maintenance mode status
+type State struct {
+	Enabled   bool       `json:"enabled"`
+	Message   string     `json:"message,omitempty"`
+	ChangedBy string     `json:"changed_by,omitempty"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+}
+
+var (
+	mu    sync.RWMutex
+	state State
+)
+
+// Get returns the current maintenance state
+func Get() State {
+	mu.RLock()
+	defer mu.RUnlock()
+	return state
+}
+
+// Enabled reports whether write operations are currently blocked
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return state.Enabled
+}
+
+// Set turns maintenance mode on or off and logs who changed it
+// The state lives in memory only, so every instance must be toggled (or started with MAINTENANCE_MODE)
+func Set(enabled bool, message, changedBy string) State {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	state = State{
+		Enabled:   enabled,
+		Message:   message,
+		ChangedBy: changedBy,
+		ChangedAt: &now,
+	}
+
+	if enabled {
+		slog.Warn("maintenance mode on", "changed_by", changedBy, "message", message)
+	} else {
+		slog.Info("maintenance mode off", "changed_by", changedBy)
+	}
+	return state
+}
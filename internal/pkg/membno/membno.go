@@ -0,0 +1,21 @@
+package membno
+
+import "strings"
+
+// Length is the width of MAST_MEMB_NO in flommast (left-padded with zeros)
+const Length = 5
+
+// Normalize trims and left-pads a member number to Length digits, e.g. "123" -> "00123"
+// ok is false for empty, non-numeric or over-length input, which can never match flommast
+func Normalize(membNo string) (string, bool) {
+	membNo = strings.TrimSpace(membNo)
+	if membNo == "" || len(membNo) > Length {
+		return "", false
+	}
+	for _, r := range membNo {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return strings.Repeat("0", Length-len(membNo)) + membNo, true
+}
@@ -0,0 +1,69 @@
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rounding modes (MONEY_ROUNDING)
+const (
+	HalfUp   = "half_up"   // 0.125 -> 0.13 (ปัดเศษแบบทั่วไป)
+	HalfEven = "half_even" // 0.125 -> 0.12 (banker's rounding)
+)
+
+// CurrencySymbol is prepended by FormatTHB
+const CurrencySymbol = "฿"
+
+var mode = HalfUp
+
+// SetMode selects the rounding mode used by Round (unknown modes fall back to half_up)
+// Call once at startup, before requests are served
+func SetMode(m string) {
+	if m == HalfEven {
+		mode = HalfEven
+		return
+	}
+	mode = HalfUp
+}
+
+// Round rounds an amount to 2 decimals (satang)
+// The value is first snapped to 6 decimals so float noise like 1.00499999 rounds as 1.005
+func Round(amount float64) float64 {
+	cents := math.Round(amount*1e6) / 1e4
+	if mode == HalfEven {
+		return math.RoundToEven(cents) / 100
+	}
+	return math.Round(cents) / 100
+}
+
+// Format formats 1234567.5 as 1,234,567.50
+func Format(amount float64) string {
+	s := strconv.FormatFloat(Round(amount), 'f', 2, 64)
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	if neg {
+		return "-" + b.String() + frac
+	}
+	return b.String() + frac
+}
+
+// FormatTHB formats 1234567.5 as ฿1,234,567.50
+func FormatTHB(amount float64) string {
+	s := Format(amount)
+	if strings.HasPrefix(s, "-") {
+		return "-" + CurrencySymbol + s[1:]
+	}
+	return CurrencySymbol + s
+}
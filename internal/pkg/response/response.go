@@ -4,10 +4,12 @@ import "github.com/gofiber/fiber/v2"
 
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool              `json:"success"`
+	Message string            `json:"message,omitempty"`
+	Data    interface{}       `json:"data,omitempty"`
+	Code    string            `json:"code,omitempty"` // machine-readable error code, e.g. MORTGAGE_NOT_FOUND
+	Error   string            `json:"error,omitempty"`
+	Errors  map[string]string `json:"errors,omitempty"`
 }
 
 // Success sends a success response
@@ -28,14 +30,57 @@ func Created(c *fiber.Ctx, message string, data interface{}) error {
 	})
 }
 
-// Error sends an error response
+// Error sends an error response with the generic code for the status
 func Error(c *fiber.Ctx, statusCode int, message string) error {
+	return ErrorWithCode(c, statusCode, StatusCode(statusCode), message)
+}
+
+// ErrorWithCode sends an error response with a specific machine-readable code
+func ErrorWithCode(c *fiber.Ctx, statusCode int, code, message string) error {
 	return c.Status(statusCode).JSON(Response{
 		Success: false,
+		Code:    code,
 		Error:   message,
 	})
 }
 
+// ErrorWithData sends an error response with a specific code and details the client can act on
+func ErrorWithData(c *fiber.Ctx, statusCode int, code, message string, data interface{}) error {
+	return c.Status(statusCode).JSON(Response{
+		Success: false,
+		Code:    code,
+		Error:   message,
+		Data:    data,
+	})
+}
+
+// StatusCode returns the generic error code for an HTTP status
+func StatusCode(statusCode int) string {
+	switch statusCode {
+	case fiber.StatusBadRequest:
+		return "BAD_REQUEST"
+	case fiber.StatusUnauthorized:
+		return "UNAUTHORIZED"
+	case fiber.StatusForbidden:
+		return "FORBIDDEN"
+	case fiber.StatusNotFound:
+		return "NOT_FOUND"
+	case fiber.StatusConflict:
+		return "CONFLICT"
+	case fiber.StatusUnprocessableEntity:
+		return "VALIDATION_FAILED"
+	case fiber.StatusTooManyRequests:
+		return "RATE_LIMITED"
+	case fiber.StatusServiceUnavailable:
+		return "SERVICE_UNAVAILABLE"
+	default:
+		if statusCode >= 500 {
+			return "INTERNAL_ERROR"
+		}
+		return ""
+	}
+}
+
 // BadRequest sends a 400 bad request response
 func BadRequest(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusBadRequest, message)
@@ -65,3 +110,13 @@ func Conflict(c *fiber.Ctx, message string) error {
 func InternalServerError(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusInternalServerError, message)
 }
+
+// ValidationError sends a 422 unprocessable entity response with field-level errors
+func ValidationError(c *fiber.Ctx, errs map[string]string) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(Response{
+		Success: false,
+		Code:    StatusCode(fiber.StatusUnprocessableEntity),
+		Error:   "Validation failed",
+		Errors:  errs,
+	})
+}
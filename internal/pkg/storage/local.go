@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInvalidKey is returned for keys that escape the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// LocalStorage stores files on the local filesystem under a base directory
+// Suits a single VM deployment; use S3Storage when running more than one instance
+type LocalStorage struct {
+	baseDir string
+}
+
+// NewLocalStorage creates the base directory if needed
+func NewLocalStorage(baseDir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{baseDir: baseDir}, nil
+}
+
+// path resolves a key (e.g. "appts/12/3/abc.pdf") to a path inside baseDir
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.baseDir, clean), nil
+}
+
+// Put writes r to key, replacing any existing file
+// เขียนลงไฟล์ชั่วคราวก่อนแล้ว rename เพื่อไม่ให้มีไฟล์ครึ่งๆ กลางๆ
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Get opens the file stored at key
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes the file stored at key (missing files are not an error)
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL returns "" - local files have no public address and are streamed by the API
+func (s *LocalStorage) URL(_ context.Context, key, _ string, _ time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	return "", nil
+}
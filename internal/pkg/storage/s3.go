@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config holds the settings for an S3 compatible bucket (AWS S3 / MinIO)
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.ap-southeast-1.amazonaws.com or http://minio:9000 (empty = AWS for Region)
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // bucket in the path instead of the host name (required by most MinIO setups)
+}
+
+// S3Storage stores files in an S3 compatible bucket
+// Requests are signed with AWS Signature V4, downloads use pre-signed URLs
+type S3Storage struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Storage validates the config; no request is made until the first upload
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("S3 bucket, access key and secret key are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+
+	return &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 60 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Put uploads r to key
+// The body is buffered to sign its hash; uploads are already capped by UPLOAD_MAX_BYTES
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+// Get downloads the object stored at key
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s3Error(http.MethodGet, key, resp)
+	}
+}
+
+// Delete removes the object stored at key
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+// URL returns a pre-signed GET link valid for expires (max 7 days)
+// downloadName sets Content-Disposition so the browser saves the original file name
+func (s *S3Storage) URL(_ context.Context, key, downloadName string, expires time.Duration) (string, error) {
+	host, uri, err := s.objectPath(key)
+	if err != nil {
+		return "", err
+	}
+
+	secs := int(expires / time.Second)
+	if secs < 1 || secs > 7*24*3600 {
+		return "", fmt.Errorf("invalid URL expiry %s", expires)
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.cfg.AccessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(secs),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if downloadName != "" {
+		query["response-content-disposition"] = mime.FormatMediaType("attachment", map[string]string{"filename": downloadName})
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	_, signature := s.sign(now, http.MethodGet, uri, canonicalQuery, map[string]string{"host": host}, "UNSIGNED-PAYLOAD")
+
+	return s.endpoint.Scheme + "://" + host + uri + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+// do sends a signed request for one object
+func (s *S3Storage) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	host, uri, err := s.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint.Scheme+"://"+host+uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders, signature := s.sign(now, method, uri, "", map[string]string{
+		"host":                 host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}, payloadHash)
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+
+	return s.client.Do(req)
+}
+
+// objectPath returns the host and escaped path of an object for the addressing style in use
+func (s *S3Storage) objectPath(key string) (host, uri string, err error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "..") {
+		return "", "", ErrInvalidKey
+	}
+
+	if s.cfg.PathStyle {
+		return s.endpoint.Host, s.endpoint.Path + "/" + awsEscape(s.cfg.Bucket, true) + "/" + awsEscape(key, false), nil
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host, s.endpoint.Path + "/" + awsEscape(key, false), nil
+}
+
+// sign computes the Signature V4 of a request; headers are the signed headers with lower-case names
+// and query is already canonical (see canonicalQueryString). Returns the SignedHeaders list and the signature
+func (s *S3Storage) sign(now time.Time, method, uri, query string, headers map[string]string, payloadHash string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, uri, query, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQueryString sorts and escapes query parameters as Signature V4 expects
+func canonicalQueryString(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, awsEscape(k, true)+"="+awsEscape(params[k], true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything except RFC 3986 unreserved characters (and "/" when kept)
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Error builds an error from a failed response, including the S3 error body (trimmed)
+func s3Error(method, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+}
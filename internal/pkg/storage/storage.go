@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Storage drivers (STORAGE_DRIVER)
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound is returned by Get when no file is stored at the key
+var ErrNotFound = errors.New("file not found in storage")
+
+// Storage stores uploaded files by key (e.g. "appts/12/3/abc.pdf")
+type Storage interface {
+	// Put writes r to key, replacing any existing file
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// Get opens the file stored at key (caller must close the reader)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the file stored at key (missing files are not an error)
+	Delete(ctx context.Context, key string) error
+
+	// URL returns a time-limited download link, or "" when files must be streamed through the API
+	URL(ctx context.Context, key, downloadName string, expires time.Duration) (string, error)
+}
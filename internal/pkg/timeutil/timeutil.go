@@ -0,0 +1,104 @@
+package timeutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DateLayout is the date format used in API params and DATE columns
+	DateLayout = "2006-01-02"
+
+	// DefaultTimezone is the business timezone (Thailand, UTC+7)
+	DefaultTimezone = "Asia/Bangkok"
+)
+
+var (
+	once     sync.Once
+	location *time.Location
+
+	// clock is the time source, swapped in tests
+	clock = time.Now
+)
+
+// ErrInvalidTimeOfDay is returned by NormalizeTimeOfDay for anything but a 24h H:MM / HH:MM time
+var ErrInvalidTimeOfDay = errors.New("invalid time format, use 24h HH:MM")
+
+// Location returns the configured business location (APP_TIMEZONE, default Asia/Bangkok)
+// Falls back to a fixed UTC+7 zone if tzdata is not available on the host
+func Location() *time.Location {
+	once.Do(func() {
+		name := os.Getenv("APP_TIMEZONE")
+		if name == "" {
+			name = DefaultTimezone
+		}
+
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			loc = time.FixedZone("ICT", 7*60*60)
+		}
+		location = loc
+	})
+	return location
+}
+
+// Now returns the current time in the business location
+func Now() time.Time {
+	return clock().In(Location())
+}
+
+// Today returns today's date (YYYY-MM-DD) in the business location
+func Today() string {
+	return FormatDate(clock())
+}
+
+// Tomorrow returns tomorrow's date (YYYY-MM-DD) in the business location
+func Tomorrow() string {
+	return FormatDate(Now().AddDate(0, 0, 1))
+}
+
+// FormatDate formats t as YYYY-MM-DD in the business location
+func FormatDate(t time.Time) string {
+	return t.In(Location()).Format(DateLayout)
+}
+
+// StartOfDay returns midnight of t's day in the business location
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Location())
+}
+
+// StartOfMonth returns midnight of the first day of t's month in the business location
+func StartOfMonth(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, Location())
+}
+
+// StartOfWeek returns midnight of the Sunday starting t's week in the business location
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// NormalizeTimeOfDay validates a 24h time and returns it zero-padded as HH:MM ("9:05" -> "09:05")
+// so stored values sort correctly as strings. 12h forms ("9:00 AM"), seconds and dots are rejected
+func NormalizeTimeOfDay(value string) (string, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || len(hh) < 1 || len(hh) > 2 || len(mm) != 2 {
+		return "", ErrInvalidTimeOfDay
+	}
+	hour, err := strconv.Atoi(hh)
+	if err != nil || hh[0] == '+' || hh[0] == '-' || hour > 23 {
+		return "", ErrInvalidTimeOfDay
+	}
+	minute, err := strconv.Atoi(mm)
+	if err != nil || mm[0] == '+' || mm[0] == '-' || minute > 59 {
+		return "", ErrInvalidTimeOfDay
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), nil
+}
@@ -0,0 +1,105 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+// Bangkok midnight of 2024-02-01 is 2024-01-31T17:00:00Z, which also crosses a month
+var (
+	beforeMidnight = time.Date(2024, 1, 31, 16, 59, 59, 0, time.UTC)
+	atMidnight     = time.Date(2024, 1, 31, 17, 0, 0, 0, time.UTC)
+)
+
+func withClock(t *testing.T, now time.Time) {
+	t.Helper()
+	prev := clock
+	clock = func() time.Time { return now }
+	t.Cleanup(func() { clock = prev })
+}
+
+func TestTodayTomorrow(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		today    string
+		tomorrow string
+	}{
+		{"before midnight", beforeMidnight, "2024-01-31", "2024-02-01"},
+		{"at midnight", atMidnight, "2024-02-01", "2024-02-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withClock(t, tt.now)
+			if got := Today(); got != tt.today {
+				t.Errorf("Today() = %q, want %q", got, tt.today)
+			}
+			if got := Tomorrow(); got != tt.tomorrow {
+				t.Errorf("Tomorrow() = %q, want %q", got, tt.tomorrow)
+			}
+		})
+	}
+}
+
+func TestStartOfDayMonth(t *testing.T) {
+	tests := []struct {
+		name  string
+		t     time.Time
+		day   time.Time
+		month time.Time
+	}{
+		{
+			"before midnight", beforeMidnight,
+			time.Date(2024, 1, 30, 17, 0, 0, 0, time.UTC),
+			time.Date(2023, 12, 31, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			"at midnight", atMidnight,
+			time.Date(2024, 1, 31, 17, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 31, 17, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StartOfDay(tt.t); !got.Equal(tt.day) {
+				t.Errorf("StartOfDay(%s) = %s, want %s", tt.t, got, tt.day)
+			}
+			if got := StartOfMonth(tt.t); !got.Equal(tt.month) {
+				t.Errorf("StartOfMonth(%s) = %s, want %s", tt.t, got, tt.month)
+			}
+		})
+	}
+}
+
+func TestNormalizeTimeOfDay(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{"9:00", "09:00", false},
+		{"09:00", "09:00", false},
+		{" 9:30 ", "09:30", false},
+		{"9.30", "", true},
+		{"09:00 น.", "", true},
+		{"24:00", "", true},
+		{"23:60", "", true},
+		{"9:5", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTimeOfDay(tt.in)
+		if tt.err {
+			if err != ErrInvalidTimeOfDay {
+				t.Errorf("NormalizeTimeOfDay(%q) error = %v, want ErrInvalidTimeOfDay", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeTimeOfDay(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
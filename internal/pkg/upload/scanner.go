@@ -0,0 +1,187 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Scanner drivers (FILE_SCANNER)
+const (
+	ScannerOff    = "off"
+	ScannerClamAV = "clamav"
+	ScannerHTTP   = "http"
+)
+
+var (
+	ErrFileInfected       = errors.New("file failed the virus scan")
+	ErrScannerUnavailable = errors.New("file scanner is unavailable")
+)
+
+// FileScanner checks an uploaded file before it is stored
+// Scan returns ErrFileInfected (wrapped with the signature name) on a detection
+// and ErrScannerUnavailable when the scanner cannot give an answer
+type FileScanner interface {
+	Scan(ctx context.Context, name string, r io.Reader) error
+}
+
+// ScanFile opens an uploaded file and runs it through the scanner
+func ScanFile(ctx context.Context, scanner FileScanner, fh *multipart.FileHeader) error {
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return scanner.Scan(ctx, fh.Filename, f)
+}
+
+// NoopScanner accepts every file (default, used when FILE_SCANNER=off)
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(context.Context, string, io.Reader) error { return nil }
+
+// ============================================================
+// ClamAV (clamd INSTREAM over TCP)
+// ============================================================
+
+// clamChunkSize is the size of each INSTREAM chunk (clamd StreamMaxLength still applies)
+const clamChunkSize = 64 << 10
+
+// ClamAVScanner streams files to a clamd daemon, e.g. clamav:3310
+type ClamAVScanner struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, _ string, r io.Reader) error {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+
+	buf := make([]byte, clamChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(append(size, buf[:n]...)); err != nil {
+				return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	// zero-length chunk ends the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+
+	// "stream: OK" / "stream: Eicar-Test-Signature FOUND" / "INSTREAM size limit exceeded. ERROR"
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return fmt.Errorf("%w: %s", ErrFileInfected, signature)
+	default:
+		return fmt.Errorf("%w: clamd replied %q", ErrScannerUnavailable, reply)
+	}
+}
+
+// ============================================================
+// HTTP scan service
+// ============================================================
+
+// HTTPScanner posts the raw file to a scan service
+// The service must answer 200 with {"clean": true} or {"clean": false, "signature": "..."}
+type HTTPScanner struct {
+	URL    string
+	Client *http.Client
+}
+
+type httpScanResult struct {
+	Clean     bool   `json:"clean"`
+	Signature string `json:"signature"`
+}
+
+func (s *HTTPScanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", name)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScannerUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: scan service returned %s", ErrScannerUnavailable, resp.Status)
+	}
+
+	var result httpScanResult
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("%w: invalid scan response: %v", ErrScannerUnavailable, err)
+	}
+	if !result.Clean {
+		return fmt.Errorf("%w: %s", ErrFileInfected, result.Signature)
+	}
+	return nil
+}
+
+// NewScanner builds the scanner selected by FILE_SCANNER (off / clamav / http)
+func NewScanner(driver, addr string, timeout time.Duration) (FileScanner, error) {
+	switch driver {
+	case "", ScannerOff:
+		return NoopScanner{}, nil
+	case ScannerClamAV:
+		if addr == "" {
+			return nil, errors.New("FILE_SCANNER_ADDR is required for clamav")
+		}
+		return &ClamAVScanner{Addr: addr, Timeout: timeout}, nil
+	case ScannerHTTP:
+		if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+			return nil, errors.New("FILE_SCANNER_ADDR must be an http(s) URL for the http scanner")
+		}
+		return &HTTPScanner{URL: addr, Client: &http.Client{Timeout: timeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown file scanner %q", driver)
+	}
+}
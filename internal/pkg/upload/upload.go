@@ -0,0 +1,56 @@
+package upload
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrEmptyFile          = errors.New("file is empty")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// Rules are the size/type limits for uploaded files
+type Rules struct {
+	MaxBytes     int64
+	AllowedTypes []string // e.g. application/pdf, image/jpeg, image/png
+}
+
+// Check validates an uploaded file and returns its detected content type
+// Content type is sniffed from the file content, not taken from the client header
+func Check(fh *multipart.FileHeader, rules Rules) (string, error) {
+	if fh.Size <= 0 {
+		return "", ErrEmptyFile
+	}
+	if rules.MaxBytes > 0 && fh.Size > rules.MaxBytes {
+		return "", ErrFileTooLarge
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	for _, allowed := range rules.AllowedTypes {
+		if strings.EqualFold(strings.TrimSpace(allowed), contentType) {
+			return contentType, nil
+		}
+	}
+	return "", ErrFileTypeNotAllowed
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// get returns the shared validator instance
+// Field names are reported using their json tag so they match the request body
+func get() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+			name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return fld.Name
+			}
+			return name
+		})
+	})
+	return validate
+}
+
+// Validate validates a struct using its `validate` tags
+// Returns nil if valid, otherwise a map of field name -> error message
+func Validate(s interface{}) map[string]string {
+	err := get().Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return map[string]string{"_": err.Error()}
+	}
+
+	errs := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		errs[fe.Field()] = message(fe)
+	}
+	return errs
+}
+
+// message converts a field error to a readable message
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", fe.Field(), fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", fe.Field(), fe.Param())
+	case "len":
+		return fmt.Sprintf("%s must be %s characters", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", fe.Field(), fe.Param())
+	case "datetime":
+		return fmt.Sprintf("%s must match format %s", fe.Field(), fe.Param())
+	default:
+		return fmt.Sprintf("%s is invalid", fe.Field())
+	}
+}
@@ -1,5 +1,6 @@
 -- ============================================================
 -- Migration: เพิ่ม columns สำหรับ Device Binding + Phone Verification
+-- Superseded by internal/adapters/persistence/migrations (go run ./cmd/migrate)
 -- Run this on your MySQL database
 -- ============================================================
 